@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/google/uuid"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
@@ -32,8 +33,12 @@ func (c *TestClient) CreateNewTask(title, description string) *openapi_types.UUI
 		Title:       title,
 		Description: description,
 	}
+	key := uuid.NewString()
+	params := &dto.PostTasksParams{
+		IdempotencyKey: &key,
+	}
 	ctx := context.Background()
-	resp, err := c.Client.PostTasks(ctx, createReq)
+	resp, err := c.Client.PostTasks(ctx, params, createReq)
 	if err != nil {
 		log.Println(fmt.Errorf("failed to create task: %w", err))
 		return nil
@@ -124,7 +129,7 @@ func (c *TestClient) GetTaskById(id openapi_types.UUID) {
 
 	ctx := context.Background()
 
-	taskResp, err := c.Client.GetTasksId(ctx, id)
+	taskResp, err := c.Client.GetTasksId(ctx, id, nil)
 	if err != nil {
 		log.Printf("Failed to receive task by id: %v", err)
 		return
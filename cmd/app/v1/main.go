@@ -7,6 +7,14 @@ import "betera-tz/internal/app"
 // @description This is a Task Management API
 // @host localhost:3333
 // @BasePath /api/v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API key as "Bearer <key>"
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key
 func main() {
 	app.Run()
 }
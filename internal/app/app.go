@@ -2,15 +2,20 @@ package app
 
 import (
 	"betera-tz/internal/config"
+	"betera-tz/internal/delivery/grpcserver"
 	"betera-tz/internal/delivery/handlers"
 	"betera-tz/internal/delivery/server"
 	"betera-tz/internal/domain/repositories"
 	"betera-tz/internal/domain/services"
 	"betera-tz/internal/workers"
+	"betera-tz/pkg/jwks"
 	"betera-tz/pkg/logger"
 	"betera-tz/pkg/monitoring"
 	"betera-tz/pkg/queue"
+	"betera-tz/pkg/ratelimit"
 	"betera-tz/pkg/storage"
+	"betera-tz/pkg/tracing"
+	"betera-tz/pkg/webhook"
 	"context"
 	"fmt"
 	"net"
@@ -18,13 +23,30 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func Run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	cfg := config.MustLoadConfig(os.Getenv("CONFIG_PATH"))
 	logger := logger.NewLogger(cfg.App)
 	logger.Info("config loaded")
 
+	tracer, err := tracing.Setup(ctx, cfg.Tracing, cfg.App)
+	if err != nil {
+		panic(fmt.Errorf("failed to set up tracing: %w", err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.CloseTimeout)
+		defer cancel()
+		if err := tracer.Shutdown(ctx); err != nil {
+			logger.Error("failed to flush traces", "error", err.Error())
+		}
+		logger.Info("tracing stopped")
+	}()
+
 	storage := storage.MustConnect(cfg.Storage)
 	logger.Info("connected to postgres")
 	defer func() {
@@ -36,27 +58,136 @@ func Run() {
 
 	producer := queue.NewProducer(cfg.Queue)
 
-	taskRepository := repositories.NewTaskRepository(storage)
+	delayConsumer := queue.NewConsumer(cfg.Queue.Delayed())
+
+	delayProducer := queue.NewProducer(cfg.Queue.Delayed())
 
-	taskWorker := workers.NewTaskWorker(consumer, producer, logger, taskRepository)
+	taskRepository := repositories.NewTaskRepository(storage)
 
-	taskService := services.NewTaskService(taskRepository, logger, producer)
+	quotaRepository := repositories.NewQuotaRepository(storage)
 
-	taskHandler := handlers.NewTaskHandler(taskService)
+	quotaService := services.NewQuotaService(quotaRepository, logger, cfg.Quotas)
 
 	prometheusSetup := monitoring.NewPrometheusSetup(cfg.Monitoring)
 
-	go func() {
-		taskWorker.MustStart()
-	}()
+	taskWorker := workers.NewTaskWorker(consumer, delayProducer, logger, taskRepository, quotaService, prometheusSetup, cfg.Quotas)
+
+	eventRepository := repositories.NewEventRepository(storage)
+
+	eventService := services.NewEventService(eventRepository, logger, cfg.Events)
+
+	delayWorker := workers.NewDelayWorker(delayConsumer, producer, logger)
+
+	taskService := services.NewTaskService(taskRepository, quotaService, logger, producer, eventService)
+
+	idempotencyRepository := repositories.NewIdempotencyRepository(storage)
+
+	idempotencyService := services.NewIdempotencyService(idempotencyRepository, logger, cfg.Idempotency)
+
+	// a timeout of 0 is unlimited, so it does not cap the wait
+	waitTimeout := cfg.Server.WriteTimeout
+	if cfg.Server.RequestTimeout > 0 && (waitTimeout == 0 || cfg.Server.RequestTimeout < waitTimeout) {
+		waitTimeout = cfg.Server.RequestTimeout
+	}
+	taskHandler := handlers.NewTaskHandler(taskService, idempotencyService, waitTimeout)
+
+	eventWorker := workers.NewEventWorker(storage, eventService, logger, cfg.Events)
+
+	eventHandler := handlers.NewEventHandler(eventService, cfg.Events.Heartbeat)
+
+	webhookRepository := repositories.NewWebhookRepository(storage)
+
+	webhookService := services.NewWebhookService(webhookRepository, webhook.NewSender(cfg.Webhooks), logger, cfg.Webhooks)
+
+	webhookWorker := workers.NewWebhookWorker(webhookService, logger, cfg.Webhooks)
+
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	importRepository := repositories.NewImportRepository(storage)
+
+	importService := services.NewImportService(importRepository, taskRepository, quotaService, producer, logger, cfg.Imports)
+
+	importWorker := workers.NewImportWorker(importService, logger, cfg.Imports)
+
+	importHandler := handlers.NewImportHandler(importService)
+
+	apiKeyRepository := repositories.NewApiKeyRepository(storage)
+
+	apiKeyService := services.NewApiKeyService(apiKeyRepository, logger)
+
+	if err := apiKeyService.Bootstrap(ctx, cfg.Auth.BootstrapKey); err != nil {
+		panic(fmt.Errorf("failed to store bootstrap api key: %w", err))
+	}
+
+	apiKeyHandler := handlers.NewApiKeyHandler(apiKeyService)
+
+	var keySet *jwks.KeySet
+	if cfg.Auth.JWT.JWKS != "" {
+		keySet = jwks.NewKeySet(cfg.Auth.JWT)
+		if err := keySet.Load(ctx); err != nil {
+			if !keySet.Remote() {
+				panic(fmt.Errorf("failed to load jwks: %w", err))
+			}
+			logger.Error("failed to fetch jwks, retrying on first token", "error", err.Error())
+		}
+	}
+
+	authService := services.NewAuthService(apiKeyService, keySet, logger, cfg.Auth.JWT)
+
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
+
+	handlers := handlers.NewHandlers(taskHandler, eventHandler, webhookHandler, importHandler, apiKeyHandler, quotaHandler)
+
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if cfg.Server.RateLimit.Store == "postgres" {
+		limiter = ratelimit.NewPostgresLimiter(storage)
+	}
+
+	go taskWorker.Start()
+
+	go taskWorker.ReportConsumerStats(ctx, cfg.Monitoring.ConsumerStatsInterval)
 
 	logger.Info("task worker started")
-	appServer := server.NewAppServer(cfg.Server, cfg.App, taskHandler, prometheusSetup)
+
+	go delayWorker.Start()
+
+	logger.Info("delay worker started")
+
+	go eventWorker.Start(ctx)
+
+	logger.Info("event worker started")
+
+	go webhookWorker.Start(ctx)
+
+	logger.Info("webhook worker started")
+
+	go importWorker.Start(ctx)
+
+	logger.Info("import worker started")
+
+	healthService := services.NewHealthService(
+		[]services.HealthCheck{
+			{Name: "taskWorker", Check: taskWorker.Health},
+			{Name: "delayWorker", Check: delayWorker.Health},
+			{Name: "eventWorker", Check: eventWorker.Health},
+			{Name: "webhookWorker", Check: webhookWorker.Health},
+			{Name: "importWorker", Check: importWorker.Health},
+		},
+		[]services.HealthCheck{{Name: "postgres", Check: storage.Ping}, {Name: "kafka", Check: consumer.Ping}},
+		logger,
+		cfg.Health,
+	)
+
+	appServer := server.NewAppServer(cfg.Server, cfg.App, cfg.Auth, handlers, prometheusSetup, authService, limiter, healthService)
+	appServer.MustConfigureTLS(cfg.Server, logger)
+	appServer.Server.RegisterOnShutdown(eventService.Close)
+	grpcServer := grpcserver.NewGRPCServer(cfg.Server, cfg.GRPC, grpcserver.NewTaskServer(taskService, eventService), authService, limiter, logger)
 	logger.Info("server created")
 	defer func() {
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.CloseTimeout)
 		defer cancel()
 		appServer.MustClose(ctx)
+		grpcServer.Close(ctx)
 		logger.Info("servers closed")
 	}()
 
@@ -70,24 +201,37 @@ func Run() {
 		panic(fmt.Errorf("failed to bind: %w", err))
 	}
 
+	grpcListener, err := net.Listen("tcp", grpcServer.Addr)
+	if err != nil {
+		panic(fmt.Errorf("failed to bind: %w", err))
+	}
+
 	logger.Info("server started")
 
 	go func() {
-		if err := appServer.Server.Serve(appListener); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(appServer.Server, appListener); err != nil && err != http.ErrServerClosed {
 			panic(fmt.Errorf("failed to start app server: %w", err))
 		}
 	}()
 
 	go func() {
-		if err := appServer.Metric.Serve(metricsListener); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(appServer.Metric, metricsListener); err != nil && err != http.ErrServerClosed {
 			panic(fmt.Errorf("failed to start metrics server: %w", err))
 		}
 	}()
 
+	go func() {
+		if err := grpcServer.Server.Serve(grpcListener); err != nil {
+			panic(fmt.Errorf("failed to start grpc server: %w", err))
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	<-quit
 	logger.Info("app is shutting down...")
+	healthService.Drain()
+	time.Sleep(cfg.Health.DrainDelay)
 }
@@ -11,13 +11,29 @@ import (
 )
 
 type Config struct {
-	App        AppConfig        `mapstructure:"app"`
-	Server     ServerConfig     `mapstructure:"server"`
-	Storage    StorageConfig    `mapstructure:"storage"`
-	Monitoring MonitoringConfig `mapstructure:"monitoring"`
-	Queue      QueueConfig      `mapstructure:"queue"`
+	App         AppConfig         `mapstructure:"app"`
+	Server      ServerConfig      `mapstructure:"server"`
+	GRPC        GRPCConfig        `mapstructure:"grpc"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Storage     StorageConfig     `mapstructure:"storage"`
+	Monitoring  MonitoringConfig  `mapstructure:"monitoring"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	Health      HealthConfig      `mapstructure:"health"`
+	Queue       QueueConfig       `mapstructure:"queue"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	Events      EventsConfig      `mapstructure:"events"`
+	Webhooks    WebhooksConfig    `mapstructure:"webhooks"`
+	Imports     ImportsConfig     `mapstructure:"imports"`
+	Quotas      QuotasConfig      `mapstructure:"quotas"`
 }
 
+// Environments of AppConfig.Env.
+const (
+	EnvLocal = "local"
+	EnvDev   = "dev"
+	EnvProd  = "prod"
+)
+
 type AppConfig struct {
 	Name    string `mapstructure:"name"`
 	Version string `mapstructure:"version"`
@@ -26,14 +42,125 @@ type AppConfig struct {
 }
 
 type ServerConfig struct {
-	Host           string        `mapstructure:"host"`
-	Port           string        `mapstructure:"port"`
-	MetricPort     string        `mapstructure:"metricPort"`
-	WriteTimeout   time.Duration `mapstructure:"writeTimeout"`
-	ReadTimeout    time.Duration `mapstructure:"readTimeout"`
-	IdleTimeout    time.Duration `mapstructure:"idleTimeout"`
-	RequestTimeout time.Duration `mapstructure:"requestTimeout"`
-	CloseTimeout   time.Duration `mapstructure:"closeTimeout"`
+	Host           string          `mapstructure:"host"`
+	Port           string          `mapstructure:"port"`
+	MetricPort     string          `mapstructure:"metricPort"`
+	WriteTimeout   time.Duration   `mapstructure:"writeTimeout"`
+	ReadTimeout    time.Duration   `mapstructure:"readTimeout"`
+	IdleTimeout    time.Duration   `mapstructure:"idleTimeout"`
+	RequestTimeout time.Duration   `mapstructure:"requestTimeout"`
+	CloseTimeout   time.Duration   `mapstructure:"closeTimeout"`
+	RouteTimeouts  []RouteTimeout  `mapstructure:"routeTimeouts"`
+	RateLimit      RateLimitConfig `mapstructure:"rateLimit"`
+	TLS            TLSConfig       `mapstructure:"tls"`
+	MetricTLS      TLSConfig       `mapstructure:"metricTls"`
+	CORS           CORSConfig      `mapstructure:"cors"`
+	Security       SecurityConfig  `mapstructure:"security"`
+}
+
+// TLSConfig serves a server over TLS when CertFile and KeyFile are set.
+// ClientCAFile enables mutual TLS, requiring client certificates signed by
+// one of its CAs. MinVersion is "1.2" or "1.3". Changed files are loaded
+// again at most once per ReloadInterval; zero disables reloading.
+type TLSConfig struct {
+	CertFile       string        `mapstructure:"certFile"`
+	KeyFile        string        `mapstructure:"keyFile"`
+	ClientCAFile   string        `mapstructure:"clientCaFile"`
+	MinVersion     string        `mapstructure:"minVersion"`
+	ReloadInterval time.Duration `mapstructure:"reloadInterval"`
+}
+
+// Enabled reports whether the server is served over TLS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// CORSConfig controls cross-origin requests. Empty lists take the defaults of
+// the environment: outside prod every origin is allowed, in prod none is.
+// MaxAge is how long browsers may cache a preflight response.
+type CORSConfig struct {
+	AllowedOrigins   []string      `mapstructure:"allowedOrigins"`
+	AllowedMethods   []string      `mapstructure:"allowedMethods"`
+	AllowedHeaders   []string      `mapstructure:"allowedHeaders"`
+	AllowCredentials bool          `mapstructure:"allowCredentials"`
+	MaxAge           time.Duration `mapstructure:"maxAge"`
+}
+
+// SecurityConfig sets the security headers of responses; an empty value or
+// zero HSTSMaxAge leaves a header out. SwaggerCSP replaces the
+// Content-Security-Policy of the API for the swagger UI, whose page needs
+// inline scripts and styles.
+type SecurityConfig struct {
+	HSTSMaxAge            time.Duration `mapstructure:"hstsMaxAge"`
+	HSTSIncludeSubdomains bool          `mapstructure:"hstsIncludeSubdomains"`
+	ContentTypeNosniff    bool          `mapstructure:"contentTypeNosniff"`
+	FrameOptions          string        `mapstructure:"frameOptions"`
+	ReferrerPolicy        string        `mapstructure:"referrerPolicy"`
+	CSP                   string        `mapstructure:"csp"`
+	SwaggerCSP            string        `mapstructure:"swaggerCsp"`
+}
+
+// RouteTimeout overrides the request timeout of a single route. Pattern is
+// the route pattern such as "/api/v1/tasks/{id}"; zero disables the timeout.
+type RouteTimeout struct {
+	Method  string        `mapstructure:"method"`
+	Pattern string        `mapstructure:"pattern"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// RateLimitConfig limits API requests with token buckets. Store is "memory"
+// to limit on every replica on its own or "postgres" to share buckets.
+type RateLimitConfig struct {
+	Store string      `mapstructure:"store"`
+	Rules []RateLimit `mapstructure:"rules"`
+}
+
+// RateLimit gives every key a bucket of Burst requests, Requests by default,
+// refilled at Requests per Period. Key is "caller" for the API key or JWT
+// user, "tenant" or "ip". Empty Method and Pattern match every route.
+type RateLimit struct {
+	Method   string        `mapstructure:"method"`
+	Pattern  string        `mapstructure:"pattern"`
+	Key      string        `mapstructure:"key"`
+	Requests int           `mapstructure:"requests"`
+	Period   time.Duration `mapstructure:"period"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+type AuthConfig struct {
+	BootstrapKey string      `mapstructure:"bootstrapKey"`
+	JWT          JWTConfig   `mapstructure:"jwt"`
+	RouteRoles   []RouteRole `mapstructure:"routeRoles"`
+}
+
+// JWTConfig enables bearer JWTs issued by an identity provider. JWKS is a
+// file path or an http(s) URL of the key set; empty disables JWTs.
+// RolesClaim and TenantClaim are dot-separated paths such as
+// "realm_access.roles"; an empty TenantClaim leaves users unbound.
+type JWTConfig struct {
+	JWKS        string        `mapstructure:"jwks"`
+	Issuer      string        `mapstructure:"issuer"`
+	Audience    string        `mapstructure:"audience"`
+	RolesClaim  string        `mapstructure:"rolesClaim"`
+	TenantClaim string        `mapstructure:"tenantClaim"`
+	Leeway      time.Duration `mapstructure:"leeway"`
+	CacheTTL    time.Duration `mapstructure:"cacheTTL"`
+	MinRefresh  time.Duration `mapstructure:"minRefresh"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+}
+
+// RouteRole overrides the role required by a single route, matched like
+// RouteTimeout.
+type RouteRole struct {
+	Method  string `mapstructure:"method"`
+	Pattern string `mapstructure:"pattern"`
+	Role    string `mapstructure:"role"`
+}
+
+type GRPCConfig struct {
+	Port             string        `mapstructure:"port"`
+	KeepaliveTime    time.Duration `mapstructure:"keepaliveTime"`
+	KeepaliveTimeout time.Duration `mapstructure:"keepaliveTimeout"`
 }
 
 type StorageConfig struct {
@@ -47,17 +174,108 @@ type StorageConfig struct {
 	ConnectTimeout time.Duration `mapstructure:"connectTimeout"`
 	PingTimeout    time.Duration `mapstructure:"pingTimeout"`
 	AmountOfConns  int32         `mapstructure:"amountOfConns"`
+	// RowLevelSecurity scopes task queries by the tasks_tenant_isolation
+	// policy besides their tenant filter. The policy only binds roles that
+	// do not own the table, so startup fails when the role owns it.
+	RowLevelSecurity bool `mapstructure:"rowLevelSecurity"`
 }
 
+// QueueConfig configures the task queue. Tasks deferred by the processing
+// quota wait in DelayTopic.
 type QueueConfig struct {
-	Broker  string        `mapstructure:"broker"`
-	Topic   string        `mapstructure:"topic"`
-	GroupId string        `mapstructure:"groupId"`
-	Timeout time.Duration `mapstructure:"timeout"`
+	Broker     string        `mapstructure:"broker"`
+	Topic      string        `mapstructure:"topic"`
+	DelayTopic string        `mapstructure:"delayTopic"`
+	GroupId    string        `mapstructure:"groupId"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+// Delayed returns the config of the delay topic, read by its own consumer
+// group.
+func (c QueueConfig) Delayed() QueueConfig {
+	c.Topic = c.DelayTopic
+	c.GroupId += "-delayed"
+	return c
+}
+
+// IdempotencyConfig keeps keys for TTL. Lease bounds how long a request in
+// progress holds its key; it should outlast the request timeout, as a retry
+// after it may run the request again.
+type IdempotencyConfig struct {
+	TTL   time.Duration `mapstructure:"ttl"`
+	Lease time.Duration `mapstructure:"lease"`
+}
+
+type EventsConfig struct {
+	BufferSize     int           `mapstructure:"bufferSize"`
+	ReplayLimit    int           `mapstructure:"replayLimit"`
+	Heartbeat      time.Duration `mapstructure:"heartbeat"`
+	Retention      time.Duration `mapstructure:"retention"`
+	PruneInterval  time.Duration `mapstructure:"pruneInterval"`
+	ReconnectDelay time.Duration `mapstructure:"reconnectDelay"`
+}
+
+type WebhooksConfig struct {
+	CallbackSecret string        `mapstructure:"callbackSecret"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	PollInterval   time.Duration `mapstructure:"pollInterval"`
+	BatchSize      int           `mapstructure:"batchSize"`
+	Lease          time.Duration `mapstructure:"lease"`
+	MaxAttempts    int           `mapstructure:"maxAttempts"`
+	BackoffBase    time.Duration `mapstructure:"backoffBase"`
+	BackoffMax     time.Duration `mapstructure:"backoffMax"`
+	DisableAfter   int           `mapstructure:"disableAfter"`
 }
 
+type ImportsConfig struct {
+	Dir        string        `mapstructure:"dir"`
+	MaxSize    int64         `mapstructure:"maxSize"`
+	BatchSize  int           `mapstructure:"batchSize"`
+	MaxErrors  int           `mapstructure:"maxErrors"`
+	Workers    int           `mapstructure:"workers"`
+	QueueSize  int           `mapstructure:"queueSize"`
+	StaleAfter time.Duration `mapstructure:"staleAfter"`
+}
+
+// QuotasConfig is the quota of tenants without one of their own; zero limits
+// are unlimited. A task over the processing quota is put back in the queue
+// after DeferDelay.
+type QuotasConfig struct {
+	MaxActive     int           `mapstructure:"maxActive"`
+	MaxProcessing int           `mapstructure:"maxProcessing"`
+	MaxDaily      int           `mapstructure:"maxDaily"`
+	DeferDelay    time.Duration `mapstructure:"deferDelay"`
+}
+
+// MonitoringConfig configures the Prometheus metrics. HTTPDurationBuckets
+// are the upper bounds in seconds of the request duration histogram, in
+// increasing order; empty means the defaults.
 type MonitoringConfig struct {
-	Namespace string `mapstructure:"namespace"`
+	Namespace             string        `mapstructure:"namespace"`
+	ConsumerStatsInterval time.Duration `mapstructure:"consumerStatsInterval"`
+	HTTPDurationBuckets   []float64     `mapstructure:"httpDurationBuckets"`
+}
+
+// HealthConfig configures the liveness and readiness probes. Each check is
+// cancelled after Timeout and its result is reused for CacheTTL, so frequent
+// probes do not load the dependencies. On shutdown the app reports not ready
+// for DrainDelay before it stops accepting requests.
+type HealthConfig struct {
+	Timeout    time.Duration `mapstructure:"timeout"`
+	CacheTTL   time.Duration `mapstructure:"cacheTtl"`
+	DrainDelay time.Duration `mapstructure:"drainDelay"`
+}
+
+// TracingConfig configures OpenTelemetry tracing. Spans are exported over
+// OTLP/HTTP to Endpoint, e.g. "http://otel-collector:4318", and without it
+// are written to File or to stdout. SampleRatio is the share of traces
+// started by the app that are recorded.
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	File        string  `mapstructure:"file"`
+	SampleRatio float64 `mapstructure:"sampleRatio"`
 }
 
 func MustLoadConfig(path string) *Config {
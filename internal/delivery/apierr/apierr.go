@@ -2,32 +2,58 @@ package apierr
 
 import (
 	"betera-tz/pkg/errs"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
-var (
-	ErrNotFound       = errors.New("not found")
-	ErrAlreadyExists  = errors.New("already exists")
-	ErrBadRequest     = errors.New("bad request")
-	ErrInternalServer = errors.New("internal server error")
-	ErrRequestTimeout = errors.New("request timeout")
+// Error codes are part of the API contract. Clients branch on them, so a code
+// must never change its meaning once published.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeValidationFailed   = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeAlreadyExists      = "already_exists"
+	CodeKeyMismatch        = "idempotency_key_mismatch"
+	CodeInProgress         = "request_in_progress"
+	CodePreconditionFailed = "precondition_failed"
+	CodeRequestTimeout     = "request_timeout"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeRateLimited        = "rate_limited"
+	CodeQuotaExceeded      = "quota_exceeded"
+	CodeInternal           = "internal_error"
 )
 
+// typePrefix turns a code into the problem type URI of RFC 7807.
+const typePrefix = "urn:betera-tz:problem:"
+
 type ApiErr struct {
-	Code    int
-	Message any
+	Status     int
+	Code       string
+	Title      string
+	Detail     string
+	Violations []errs.Violation
 }
 
 func (ae ApiErr) Error() string {
-	return fmt.Sprintf("error: %s, code: %d", ae.Message, ae.Code)
+	return fmt.Sprintf("error: %s, code: %s, status: %d", ae.Detail, ae.Code, ae.Status)
+}
+
+func (ae ApiErr) Type() string {
+	return typePrefix + ae.Code
 }
 
-func NewApiError(code int, err error) ApiErr {
+func NewApiError(status int, code, title, detail string) ApiErr {
 	return ApiErr{
-		Code:    code,
-		Message: err.Error(),
+		Status: status,
+		Code:   code,
+		Title:  title,
+		Detail: detail,
 	}
 }
 
@@ -38,32 +64,134 @@ func ToApiError(err error) ApiErr {
 	case errors.Is(err, errs.ErrAlreadyExistsBase):
 		return AlreadyExists()
 	case errors.Is(err, errs.ErrInvalidValuesBase):
+		var ve errs.ValidationError
+		if errors.As(err, &ve) {
+			return Validation(ve.Violations)
+		}
 		return InvalidValues()
+	case errors.Is(err, errs.ErrKeyMismatchBase):
+		return KeyMismatch()
+	case errors.Is(err, errs.ErrInProgressBase):
+		return InProgress()
+	case errors.Is(err, errs.ErrPreconditionBase):
+		return PreconditionFailed()
+	case errors.Is(err, errs.ErrTooLargeBase):
+		return PayloadTooLarge()
+	case errors.Is(err, errs.ErrQuotaBase):
+		var qe errs.QuotaError
+		if errors.As(err, &qe) {
+			return QuotaExceeded(qe.Quota, qe.Limit)
+		}
+		return QuotaExceeded("", 0)
+	case errors.Is(err, errs.ErrUnauthorizedBase):
+		return Unauthorized()
+	case errors.Is(err, errs.ErrForbiddenBase):
+		return Forbidden()
+	case errors.Is(err, context.DeadlineExceeded):
+		return RequestTimeout()
 	default:
 		return InternalServerError()
 	}
 }
 
 func InternalServerError() ApiErr {
-	return NewApiError(http.StatusInternalServerError, ErrInternalServer)
+	return NewApiError(http.StatusInternalServerError, CodeInternal, "Internal server error",
+		"An unexpected error occurred while processing the request.")
 }
 
 func InvalidRequest() ApiErr {
-	return NewApiError(http.StatusBadRequest, ErrBadRequest)
+	return NewApiError(http.StatusBadRequest, CodeBadRequest, "Bad request",
+		"The request could not be understood.")
+}
+
+func Unauthorized() ApiErr {
+	return NewApiError(http.StatusUnauthorized, CodeUnauthorized, "Unauthorized",
+		"A valid API key is required.")
+}
+
+func Forbidden() ApiErr {
+	return NewApiError(http.StatusForbidden, CodeForbidden, "Forbidden",
+		"The API key lacks the scope required for this request.")
 }
 
 func NotFound() ApiErr {
-	return NewApiError(http.StatusNotFound, ErrNotFound)
+	return NewApiError(http.StatusNotFound, CodeNotFound, "Not found",
+		"The requested resource does not exist.")
+}
+
+func MethodNotAllowed() ApiErr {
+	return NewApiError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed",
+		"The resource does not support this method.")
 }
 
 func AlreadyExists() ApiErr {
-	return NewApiError(http.StatusConflict, ErrAlreadyExists)
+	return NewApiError(http.StatusConflict, CodeAlreadyExists, "Already exists",
+		"A resource with the same unique fields already exists.")
 }
 
+// RequestTimeout reports a request that ran out of its processing time on the
+// server, so it is a 504 rather than the 408 of a slow client.
 func RequestTimeout() ApiErr {
-	return NewApiError(http.StatusRequestTimeout, ErrRequestTimeout)
+	return NewApiError(http.StatusGatewayTimeout, CodeRequestTimeout, "Request timeout",
+		"The request took too long to process.")
 }
 
 func InvalidValues() ApiErr {
-	return NewApiError(http.StatusBadRequest, errs.ErrInvalidValuesBase)
+	return NewApiError(http.StatusBadRequest, CodeValidationFailed, "Validation failed",
+		"The request contains invalid values.")
+}
+
+// Validation reports every invalid field of a request.
+func Validation(violations []errs.Violation) ApiErr {
+	apiErr := InvalidValues()
+	parts := make([]string, 0, len(violations))
+	for _, v := range violations {
+		parts = append(parts, strings.TrimSpace(v.Field+" "+v.Message))
+	}
+	if len(parts) > 0 {
+		apiErr.Detail = strings.Join(parts, "; ")
+	}
+	apiErr.Violations = violations
+	return apiErr
+}
+
+// InvalidField reports a single invalid request parameter.
+func InvalidField(field, message string) ApiErr {
+	return Validation([]errs.Violation{{Field: field, Message: message}})
+}
+
+func KeyMismatch() ApiErr {
+	return NewApiError(http.StatusUnprocessableEntity, CodeKeyMismatch, "Idempotency key mismatch",
+		"The Idempotency-Key was already used with a different request.")
+}
+
+func InProgress() ApiErr {
+	return NewApiError(http.StatusConflict, CodeInProgress, "Request in progress",
+		"A request with the same Idempotency-Key is still being processed.")
+}
+
+func PreconditionFailed() ApiErr {
+	return NewApiError(http.StatusPreconditionFailed, CodePreconditionFailed, "Precondition failed",
+		"The resource was modified since the version given in If-Match.")
+}
+
+func PayloadTooLarge() ApiErr {
+	return NewApiError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "Payload too large",
+		"The request body exceeds the allowed size.")
+}
+
+func TooManyRequests() ApiErr {
+	return NewApiError(http.StatusTooManyRequests, CodeRateLimited, "Too many requests",
+		"The rate limit of the client is exceeded, retry after the time given in Retry-After.")
+}
+
+// QuotaExceeded reports a tenant that reached a quota of its tasks, which is
+// lifted by finishing tasks or on the next day rather than by retrying soon.
+func QuotaExceeded(quota string, limit int) ApiErr {
+	apiErr := NewApiError(http.StatusTooManyRequests, CodeQuotaExceeded, "Quota exceeded",
+		"The tenant has reached a quota of its tasks.")
+	if quota != "" {
+		apiErr.Detail = fmt.Sprintf("The tenant has reached its %s quota of %d tasks.", quota, limit)
+	}
+	return apiErr
 }
@@ -0,0 +1,107 @@
+package grpcserver
+
+import (
+	"betera-tz/internal/delivery/grpcserver/taskpb"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/errs"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Authenticator resolves the caller a request token belongs to.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*models.Caller, error)
+}
+
+// publicServices are served without an API key, so probes and tooling keep
+// working.
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// writeMethods need tasks:write; every other task method needs tasks:read.
+var writeMethods = map[string]bool{
+	taskpb.TaskService_CreateTask_FullMethodName:       true,
+	taskpb.TaskService_UpdateTaskStatus_FullMethodName: true,
+	taskpb.TaskService_AssignTask_FullMethodName:       true,
+}
+
+const authOp = "grpcserver.authorize"
+
+// authorize checks the JWT or API key sent in the authorization
+// ("Bearer <token>") or x-api-key metadata against the role of the method
+// and resolves the tenant, optionally chosen with x-tenant-id, like the REST
+// API.
+func authorize(ctx context.Context, auth Authenticator, method string) (context.Context, error) {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+	caller, err := auth.Authenticate(ctx, metadataToken(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	role := models.ScopeTasksRead
+	if writeMethods[method] {
+		role = models.ScopeTasksWrite
+	}
+	if !caller.HasRole(role) {
+		return nil, toStatus(errs.ErrForbidden(authOp))
+	}
+	tenant, err := services.ResolveTenant(caller, metadataValue(ctx, "x-tenant-id"), "x-tenant-id")
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return models.WithTenant(services.WithCaller(ctx, caller), tenant), nil
+}
+
+func metadataToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if scheme, token, ok := strings.Cut(value, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return metadataValue(ctx, "x-api-key")
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func unaryAuthInterceptor(auth Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, auth, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuthInterceptor(auth Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), auth, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authStream passes the context carrying the API key to stream handlers.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (as *authStream) Context() context.Context {
+	return as.ctx
+}
@@ -0,0 +1,123 @@
+package grpcserver
+
+import (
+	"betera-tz/internal/delivery/grpcserver/taskpb"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/errs"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAuthenticator) Authenticate(ctx context.Context, token string) (*models.Caller, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Caller), args.Error(1)
+}
+
+func TestAuthorize(t *testing.T) {
+	reader := &models.Caller{Type: models.CallerApiKey, Subject: "reader", Roles: []string{models.ScopeTasksRead}, Tenant: testTenant}
+	admin := &models.Caller{Type: models.CallerUser, Subject: "admin", Roles: []string{models.ScopeAdmin}}
+	tests := []struct {
+		name           string
+		method         string
+		md             metadata.MD
+		mockSetup      func(*MockAuthenticator)
+		expectedCode   codes.Code
+		expectedCaller *models.Caller
+		expectedTenant string
+	}{
+		{
+			name:         "public service",
+			method:       "/grpc.health.v1.Health/Check",
+			mockSetup:    func(mockAuth *MockAuthenticator) {},
+			expectedCode: codes.OK,
+		},
+		{
+			name:   "missing token",
+			method: taskpb.TaskService_GetTask_FullMethodName,
+			mockSetup: func(mockAuth *MockAuthenticator) {
+				mockAuth.On("Authenticate", mock.Anything, "").Return(nil, errs.ErrUnauthorized("test"))
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:   "bearer token with read role",
+			method: taskpb.TaskService_GetTask_FullMethodName,
+			md:     metadata.Pairs("authorization", "Bearer token"),
+			mockSetup: func(mockAuth *MockAuthenticator) {
+				mockAuth.On("Authenticate", mock.Anything, "token").Return(reader, nil)
+			},
+			expectedCode:   codes.OK,
+			expectedCaller: reader,
+			expectedTenant: testTenant,
+		},
+		{
+			name:   "api key without write role",
+			method: taskpb.TaskService_CreateTask_FullMethodName,
+			md:     metadata.Pairs("x-api-key", "key"),
+			mockSetup: func(mockAuth *MockAuthenticator) {
+				mockAuth.On("Authenticate", mock.Anything, "key").Return(reader, nil)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:   "tenant of another caller",
+			method: taskpb.TaskService_GetTask_FullMethodName,
+			md:     metadata.Pairs("x-api-key", "key", "x-tenant-id", "other"),
+			mockSetup: func(mockAuth *MockAuthenticator) {
+				mockAuth.On("Authenticate", mock.Anything, "key").Return(reader, nil)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:   "admin choosing a tenant",
+			method: taskpb.TaskService_CreateTask_FullMethodName,
+			md:     metadata.Pairs("authorization", "bearer token", "x-tenant-id", "other"),
+			mockSetup: func(mockAuth *MockAuthenticator) {
+				mockAuth.On("Authenticate", mock.Anything, "token").Return(admin, nil)
+			},
+			expectedCode:   codes.OK,
+			expectedCaller: admin,
+			expectedTenant: "other",
+		},
+		{
+			name:   "invalid tenant",
+			method: taskpb.TaskService_GetTask_FullMethodName,
+			md:     metadata.Pairs("x-api-key", "key", "x-tenant-id", "Not A Tenant"),
+			mockSetup: func(mockAuth *MockAuthenticator) {
+				mockAuth.On("Authenticate", mock.Anything, "key").Return(admin, nil)
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuth := new(MockAuthenticator)
+			tt.mockSetup(mockAuth)
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			ctx, err := authorize(ctx, mockAuth, tt.method)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCaller != nil {
+				assert.Equal(t, tt.expectedCaller, services.CallerFromContext(ctx))
+				assert.Equal(t, tt.expectedTenant, models.TenantFromContext(ctx))
+			}
+			mockAuth.AssertExpectations(t)
+		})
+	}
+}
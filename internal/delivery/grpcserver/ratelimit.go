@@ -0,0 +1,107 @@
+package grpcserver
+
+import (
+	"betera-tz/internal/delivery/grpcserver/taskpb"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/ratelimit"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// route is the REST route a method does the work of.
+type route struct {
+	method  string
+	pattern string
+}
+
+// routes let the rate limits of the REST routes apply to the methods doing
+// the same work. A method counts against the buckets of its route, so a
+// caller cannot double its limit by calling both APIs.
+var routes = map[string]route{
+	taskpb.TaskService_CreateTask_FullMethodName:       {http.MethodPost, "/api/v1/tasks"},
+	taskpb.TaskService_GetTask_FullMethodName:          {http.MethodGet, "/api/v1/tasks/{id}"},
+	taskpb.TaskService_ListTasks_FullMethodName:        {http.MethodGet, "/api/v1/tasks"},
+	taskpb.TaskService_UpdateTaskStatus_FullMethodName: {http.MethodPatch, "/api/v1/tasks/{id}/status"},
+	taskpb.TaskService_AssignTask_FullMethodName:       {http.MethodPost, "/api/v1/tasks/{id}/assign"},
+	taskpb.TaskService_WatchTasks_FullMethodName:       {http.MethodGet, "/api/v1/events"},
+}
+
+// rateLimit takes a call of method from the buckets of rules and fails with
+// ResourceExhausted once one of them is empty, sending the seconds to wait
+// in the retry-after header. A failing limiter lets calls through. Methods
+// without a route are public and not limited, like they are not
+// authenticated.
+func rateLimit(ctx context.Context, rules *ratelimit.Rules, log *logger.Logger, method string) error {
+	r, ok := routes[method]
+	if !ok {
+		return nil
+	}
+	decision := rules.Allow(ctx, r.method, r.pattern, func(key string) string {
+		return rateLimitKey(ctx, key)
+	})
+	if decision.Err != nil {
+		log.Error("rate limiter failed", "method", method, logger.Err(decision.Err))
+	}
+	if decision.Allowed {
+		return nil
+	}
+	retryAfter := int(math.Ceil(decision.Result.RetryAfter.Seconds()))
+	// the header cannot be sent on a closed transport, which leaves the
+	// wait in the message
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+	return status.Error(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded, retry in %ds", retryAfter))
+}
+
+// rateLimitKey returns the value of the call of ctx a rate limit by key
+// counts, empty when the call has none.
+func rateLimitKey(ctx context.Context, key string) string {
+	switch key {
+	case ratelimit.KeyCaller:
+		if caller := services.CallerFromContext(ctx); caller != nil {
+			return caller.ID()
+		}
+		return ""
+	case ratelimit.KeyTenant:
+		return models.TenantFromContext(ctx)
+	default:
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return ""
+		}
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			return p.Addr.String()
+		}
+		return host
+	}
+}
+
+func unaryRateLimitInterceptor(rules *ratelimit.Rules, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := rateLimit(ctx, rules, log, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamRateLimitInterceptor(rules *ratelimit.Rules, log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rateLimit(ss.Context(), rules, log, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
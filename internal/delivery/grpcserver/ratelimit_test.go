@@ -0,0 +1,95 @@
+package grpcserver
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/delivery/grpcserver/taskpb"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/ratelimit"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type rateLimitCall struct {
+	method string
+	caller string
+	ip     string
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	createRule := config.RateLimit{Method: http.MethodPost, Pattern: "/api/v1/tasks", Key: ratelimit.KeyCaller, Requests: 1, Period: time.Hour}
+	tests := []struct {
+		name          string
+		rules         []config.RateLimit
+		keys          []string
+		calls         []rateLimitCall
+		expectedCodes []codes.Code
+	}{
+		{
+			name:  "create limit of the rest route",
+			rules: []config.RateLimit{createRule},
+			keys:  []string{ratelimit.KeyCaller, ratelimit.KeyTenant},
+			calls: []rateLimitCall{
+				{method: taskpb.TaskService_CreateTask_FullMethodName, caller: "first"},
+				{method: taskpb.TaskService_GetTask_FullMethodName, caller: "first"},
+				{method: taskpb.TaskService_CreateTask_FullMethodName, caller: "second"},
+				{method: taskpb.TaskService_CreateTask_FullMethodName, caller: "first"},
+			},
+			expectedCodes: []codes.Code{codes.OK, codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+		{
+			name:  "ip limit of every route",
+			rules: []config.RateLimit{{Key: ratelimit.KeyIP, Requests: 1, Period: time.Hour}},
+			keys:  []string{ratelimit.KeyIP},
+			calls: []rateLimitCall{
+				{method: taskpb.TaskService_ListTasks_FullMethodName, ip: "10.0.0.1"},
+				{method: taskpb.TaskService_WatchTasks_FullMethodName, ip: "10.0.0.2"},
+				{method: taskpb.TaskService_GetTask_FullMethodName, ip: "10.0.0.1"},
+			},
+			expectedCodes: []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+		{
+			name:  "public services",
+			rules: []config.RateLimit{{Key: ratelimit.KeyIP, Requests: 1, Period: time.Hour}},
+			keys:  []string{ratelimit.KeyIP},
+			calls: []rateLimitCall{
+				{method: "/grpc.health.v1.Health/Check", ip: "10.0.0.1"},
+				{method: "/grpc.health.v1.Health/Check", ip: "10.0.0.1"},
+			},
+			expectedCodes: []codes.Code{codes.OK, codes.OK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := ratelimit.NewRules(ratelimit.NewMemoryLimiter(), tt.rules, tt.keys...)
+			log := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+			interceptor := unaryRateLimitInterceptor(rules, log)
+			handler := func(ctx context.Context, req any) (any, error) { return nil, nil }
+
+			for i, call := range tt.calls {
+				ctx := context.Background()
+				if call.caller != "" {
+					ctx = services.WithCaller(ctx, &models.Caller{Type: models.CallerApiKey, Subject: call.caller})
+				}
+				if call.ip != "" {
+					ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(call.ip), Port: 5000 + i}})
+				}
+
+				_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: call.method}, handler)
+
+				assert.Equal(t, tt.expectedCodes[i], status.Code(err), "call %d", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package grpcserver
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/delivery/grpcserver/taskpb"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/ratelimit"
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCServer struct {
+	Server *grpc.Server
+	Health *health.Server
+	Addr   string
+}
+
+// NewGRPCServer serves ts with the rate limits of the REST API, taken from
+// the buckets of limiter: the ip limits ahead of authentication, the caller
+// and tenant limits after it.
+func NewGRPCServer(scfg config.ServerConfig, gcfg config.GRPCConfig, ts taskpb.TaskServiceServer, auth Authenticator, limiter ratelimit.Limiter, l *logger.Logger) *GRPCServer {
+	log := l.AddOp("GRPCServer")
+	ipRules := ratelimit.NewRules(limiter, scfg.RateLimit.Rules, ratelimit.KeyIP)
+	callerRules := ratelimit.NewRules(limiter, scfg.RateLimit.Rules, ratelimit.KeyCaller, ratelimit.KeyTenant)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			unaryLogInterceptor(log),
+			unaryRecoverInterceptor(log),
+			unaryRateLimitInterceptor(ipRules, log),
+			unaryAuthInterceptor(auth),
+			unaryRateLimitInterceptor(callerRules, log),
+		),
+		grpc.ChainStreamInterceptor(
+			streamLogInterceptor(log),
+			streamRecoverInterceptor(log),
+			streamRateLimitInterceptor(ipRules, log),
+			streamAuthInterceptor(auth),
+			streamRateLimitInterceptor(callerRules, log),
+		),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    gcfg.KeepaliveTime,
+			Timeout: gcfg.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             gcfg.KeepaliveTime / 2,
+			PermitWithoutStream: true,
+		}),
+	)
+	taskpb.RegisterTaskServiceServer(server, ts)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(taskpb.TaskService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	return &GRPCServer{
+		Server: server,
+		Health: healthServer,
+		Addr:   fmt.Sprintf("%s:%s", scfg.Host, gcfg.Port),
+	}
+}
+
+// Close reports the server as not serving, so health-checking balancers stop
+// sending requests, and waits for running calls until ctx is done. Watch
+// streams never finish on their own, so they are cut off at that point.
+func (gs *GRPCServer) Close(ctx context.Context) {
+	gs.Health.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		gs.Server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		gs.Server.Stop()
+	}
+}
+
+func unaryLogInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		logCall(log, info.FullMethod, start, err)
+		return res, err
+	}
+}
+
+func streamLogInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(log, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(log *logger.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	args := []any{"method", method, "code", code.String(), "duration", time.Since(start).String()}
+	if code == codes.Internal || code == codes.Unknown {
+		log.Error("grpc call failed", append(args, logger.Err(err))...)
+		return
+	}
+	log.Info("grpc call", args...)
+}
+
+// The recover interceptors report a panicking handler as Internal instead of
+// crashing the process.
+
+func unaryRecoverInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		defer func() {
+			if rvr := recover(); rvr != nil {
+				log.Error("grpc handler panicked", "method", info.FullMethod, "panic", fmt.Sprint(rvr), "stack", string(debug.Stack()))
+				err = status.Error(codes.Internal, "an unexpected error occurred while processing the request")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func streamRecoverInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rvr := recover(); rvr != nil {
+				log.Error("grpc handler panicked", "method", info.FullMethod, "panic", fmt.Sprint(rvr), "stack", string(debug.Stack()))
+				err = status.Error(codes.Internal, "an unexpected error occurred while processing the request")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"betera-tz/pkg/errs"
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps a service error to a gRPC status, the same way apierr maps it
+// to an HTTP problem. Validation errors carry every violation as BadRequest
+// details.
+func toStatus(err error) error {
+	var ve errs.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		return validationStatus(ve.Violations)
+	case errors.Is(err, errs.ErrNotFoundBase):
+		return status.Error(codes.NotFound, "the requested resource does not exist")
+	case errors.Is(err, errs.ErrAlreadyExistsBase):
+		return status.Error(codes.AlreadyExists, "a resource with the same unique fields already exists")
+	case errors.Is(err, errs.ErrInvalidValuesBase):
+		return status.Error(codes.InvalidArgument, "the request contains invalid values")
+	case errors.Is(err, errs.ErrUnauthorizedBase):
+		return status.Error(codes.Unauthenticated, "a valid API key is required")
+	case errors.Is(err, errs.ErrForbiddenBase):
+		return status.Error(codes.PermissionDenied, "the API key lacks the scope required for this call")
+	case errors.Is(err, errs.ErrPreconditionBase):
+		return status.Error(codes.Aborted, "the resource was modified since the given version")
+	case errors.Is(err, errs.ErrQuotaBase):
+		return status.Error(codes.ResourceExhausted, "the tenant has reached a quota of its tasks")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "the request took too long to process")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "the request was canceled")
+	default:
+		return status.Error(codes.Internal, "an unexpected error occurred while processing the request")
+	}
+}
+
+func validationStatus(violations []errs.Violation) error {
+	parts := make([]string, 0, len(violations))
+	details := &errdetails.BadRequest{}
+	for _, v := range violations {
+		field := fieldPath(v.Field)
+		parts = append(parts, strings.TrimSpace(field+" "+v.Message))
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: v.Message,
+		})
+	}
+	st := status.New(codes.InvalidArgument, strings.Join(parts, "; "))
+	if withDetails, err := st.WithDetails(details); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// invalidField reports a single invalid request field.
+func invalidField(field, message string) error {
+	return validationStatus([]errs.Violation{{Field: field, Message: message}})
+}
+
+// fieldPath converts a field named as in the JSON API, such as callbackUrl,
+// to its name in the proto messages.
+func fieldPath(field string) string {
+	b := strings.Builder{}
+	for _, r := range field {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package grpcserver
+
+import (
+	"betera-tz/pkg/errs"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "not found",
+			err:          errs.ErrNotFound("op"),
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "already exists",
+			err:          errs.NewAppError("op", errs.ErrAlreadyExistsBase),
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name:         "invalid values",
+			err:          errs.NewAppError("op", errs.ErrInvalidValuesBase),
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "unauthorized",
+			err:          errs.ErrUnauthorized("op"),
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "forbidden",
+			err:          errs.ErrForbidden("op"),
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "precondition failed",
+			err:          errs.NewAppError("op", errs.ErrPreconditionBase),
+			expectedCode: codes.Aborted,
+		},
+		{
+			name:         "quota exceeded",
+			err:          errs.NewAppError("op", errs.QuotaError{Quota: "maxActive", Limit: 10}),
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name:         "deadline exceeded",
+			err:          errs.NewAppError("op", context.DeadlineExceeded),
+			expectedCode: codes.DeadlineExceeded,
+		},
+		{
+			name:         "canceled",
+			err:          fmt.Errorf("query: %w", context.Canceled),
+			expectedCode: codes.Canceled,
+		},
+		{
+			name:         "unexpected error",
+			err:          errors.New("connection reset"),
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(toStatus(tt.err))
+
+			assert.Equal(t, tt.expectedCode, st.Code())
+			assert.NotContains(t, st.Message(), "connection reset")
+		})
+	}
+}
+
+func TestToStatus_Validation(t *testing.T) {
+	err := errs.ErrValidation("op", []errs.Violation{
+		{Field: "title", Message: "is required"},
+		{Field: "callbackUrl", Message: "must be a valid url"},
+	})
+
+	st := status.Convert(toStatus(err))
+
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "title is required; callback_url must be a valid url", st.Message())
+	if assert.Len(t, st.Details(), 1) {
+		details, ok := st.Details()[0].(*errdetails.BadRequest)
+		if assert.True(t, ok) {
+			violations := details.GetFieldViolations()
+			assert.Len(t, violations, 2)
+			assert.Equal(t, "callback_url", violations[1].GetField())
+			assert.Equal(t, "must be a valid url", violations[1].GetDescription())
+		}
+	}
+}
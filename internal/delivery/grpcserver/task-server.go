@@ -0,0 +1,216 @@
+package grpcserver
+
+import (
+	"betera-tz/internal/delivery/grpcserver/taskpb"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TaskServer implements the gRPC TaskService on top of the services used by
+// the REST handlers.
+type TaskServer struct {
+	taskpb.UnimplementedTaskServiceServer
+	TaskService  services.TaskService
+	EventService services.EventService
+}
+
+func NewTaskServer(ts services.TaskService, es services.EventService) *TaskServer {
+	return &TaskServer{
+		TaskService:  ts,
+		EventService: es,
+	}
+}
+
+func (s *TaskServer) CreateTask(ctx context.Context, req *taskpb.CreateTaskRequest) (*taskpb.CreateTaskResponse, error) {
+	id, err := s.TaskService.Create(ctx, req.GetTitle(), req.GetDescription(), req.GetCallbackUrl())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &taskpb.CreateTaskResponse{Id: id.String()}, nil
+}
+
+func (s *TaskServer) GetTask(ctx context.Context, req *taskpb.GetTaskRequest) (*taskpb.Task, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.TaskService.GetById(ctx, id.String())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toTask(task), nil
+}
+
+func (s *TaskServer) ListTasks(ctx context.Context, req *taskpb.ListTasksRequest) (*taskpb.ListTasksResponse, error) {
+	amount, page := int(req.GetAmount()), int(req.GetPage())
+	if amount <= 0 || page <= 0 {
+		amount, page = -1, 1
+	}
+	filter := models.TaskFilter{
+		Status:    fromStatus(req.GetStatusFilter()),
+		CreatedBy: req.GetCreatedBy(),
+		Assignee:  req.GetAssignee(),
+	}
+	tasks, err := s.TaskService.Get(ctx, amount, page, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &taskpb.ListTasksResponse{Tasks: make([]*taskpb.Task, 0, len(tasks))}
+	for i := range tasks {
+		res.Tasks = append(res.Tasks, toTask(&tasks[i]))
+	}
+	return res, nil
+}
+
+func (s *TaskServer) UpdateTaskStatus(ctx context.Context, req *taskpb.UpdateTaskStatusRequest) (*taskpb.Task, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	var version *int
+	if req.Version != nil {
+		v := int(req.GetVersion())
+		version = &v
+	}
+	if err := s.TaskService.UpdateStatus(ctx, id.String(), fromStatus(req.GetStatus()), version); err != nil {
+		return nil, toStatus(err)
+	}
+	task, err := s.TaskService.GetById(ctx, id.String())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toTask(task), nil
+}
+
+func (s *TaskServer) AssignTask(ctx context.Context, req *taskpb.AssignTaskRequest) (*taskpb.Task, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	var version *int
+	if req.Version != nil {
+		v := int(req.GetVersion())
+		version = &v
+	}
+	task, err := s.TaskService.Assign(ctx, id.String(), req.GetAssignee(), version)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toTask(task), nil
+}
+
+// WatchTasks replays the events after last_event_id and then streams live
+// events until the client goes away. A client that falls behind, or missed
+// more events than are replayed at once, is cut off with Unavailable and
+// resumes from the last event it received.
+func (s *TaskServer) WatchTasks(req *taskpb.WatchTasksRequest, stream grpc.ServerStreamingServer[taskpb.TaskEvent]) error {
+	ctx := stream.Context()
+	filter := models.EventFilter{
+		Tenant: models.TenantFromContext(ctx),
+		Status: fromStatus(req.GetStatus()),
+	}
+	if req.GetTaskId() != "" {
+		id, err := parseID("task_id", req.GetTaskId())
+		if err != nil {
+			return err
+		}
+		filter.TaskID = &id
+	}
+
+	// Subscribe before replaying so nothing committed in between is lost;
+	// duplicates are skipped by comparing event ids.
+	sub := s.EventService.Subscribe(filter)
+	defer sub.Close()
+
+	lastId := req.GetLastEventId()
+	if lastId > 0 {
+		missed, more, err := s.EventService.GetAfter(ctx, lastId, filter)
+		if err != nil {
+			return toStatus(err)
+		}
+		for _, event := range missed {
+			if err := stream.Send(toEvent(event)); err != nil {
+				return err
+			}
+			lastId = event.ID
+		}
+		if more {
+			return status.Error(codes.Unavailable, "replay limit reached, resume with last_event_id")
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case event, ok := <-sub.Events:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream closed, resume with last_event_id")
+			}
+			if event.ID <= lastId {
+				continue
+			}
+			if err := stream.Send(toEvent(event)); err != nil {
+				return err
+			}
+			lastId = event.ID
+		}
+	}
+}
+
+func parseID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, invalidField(field, "must be a UUID")
+	}
+	return id, nil
+}
+
+func toTask(task *models.Task) *taskpb.Task {
+	return &taskpb.Task{
+		Id:          task.ID.String(),
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      toStatusEnum(task.Status),
+		Version:     int32(task.Version),
+		CallbackUrl: task.CallbackURL,
+		CreatedBy:   task.CreatedBy,
+		Assignee:    task.Assignee,
+		ProcessedBy: task.ProcessedBy,
+	}
+}
+
+func toEvent(event models.TaskEvent) *taskpb.TaskEvent {
+	return &taskpb.TaskEvent{
+		Id:        event.ID,
+		TaskId:    event.TaskID.String(),
+		Type:      event.Type,
+		Status:    toStatusEnum(event.Status),
+		Version:   int32(event.Version),
+		CreatedAt: timestamppb.New(event.CreatedAt),
+	}
+}
+
+const statusPrefix = "TASK_STATUS_"
+
+func toStatusEnum(s string) taskpb.TaskStatus {
+	return taskpb.TaskStatus(taskpb.TaskStatus_value[statusPrefix+strings.ToUpper(s)])
+}
+
+// fromStatus returns the status name used by the services. Unspecified maps
+// to an empty status and unknown values keep their number, so the services
+// reject them as invalid.
+func fromStatus(s taskpb.TaskStatus) string {
+	if s == taskpb.TaskStatus_TASK_STATUS_UNSPECIFIED {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(s.String(), statusPrefix))
+}
@@ -0,0 +1,239 @@
+package grpcserver
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/delivery/grpcserver/taskpb"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/logger"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testTenant = "acme"
+
+type MockEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockEventRepository) GetAfter(ctx context.Context, lastId int64, filter models.EventFilter, limit int) ([]models.TaskEvent, error) {
+	args := m.Called(ctx, lastId, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TaskEvent), args.Error(1)
+}
+
+func (m *MockEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+// recordingEventService records the filters of the subscriptions.
+type recordingEventService struct {
+	services.EventService
+	mu      sync.Mutex
+	filters []models.EventFilter
+}
+
+// Subscribe records the filter once subscribed, so events published after
+// it is recorded reach the subscription.
+func (rs *recordingEventService) Subscribe(filter models.EventFilter) *services.Subscription {
+	sub := rs.EventService.Subscribe(filter)
+	rs.mu.Lock()
+	rs.filters = append(rs.filters, filter)
+	rs.mu.Unlock()
+	return sub
+}
+
+// eventStream collects the events sent to a WatchTasks client.
+type eventStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	mu     sync.Mutex
+	events []int64
+}
+
+func (es *eventStream) Context() context.Context {
+	return es.ctx
+}
+
+func (es *eventStream) Send(event *taskpb.TaskEvent) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.events = append(es.events, event.GetId())
+	return nil
+}
+
+func (es *eventStream) sent() []int64 {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return append([]int64{}, es.events...)
+}
+
+func newTestEventService(er *MockEventRepository, bufferSize int) *recordingEventService {
+	l := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+	return &recordingEventService{EventService: services.NewEventService(er, l, config.EventsConfig{BufferSize: bufferSize, ReplayLimit: 100})}
+}
+
+func taskEvent(id int64, tenant string, taskId uuid.UUID) models.TaskEvent {
+	return models.TaskEvent{ID: id, TenantID: tenant, TaskID: taskId, Type: "updated", Status: "done"}
+}
+
+func TestTaskServer_WatchTasks(t *testing.T) {
+	taskId := uuid.New()
+	mockRepo := new(MockEventRepository)
+	es := newTestEventService(mockRepo, 8)
+	expectedFilter := models.EventFilter{Tenant: testTenant, Status: "done"}
+	// events committed while replaying reach the subscription too
+	mockRepo.On("GetAfter", mock.Anything, int64(3), expectedFilter, 101).Return([]models.TaskEvent{
+		taskEvent(4, testTenant, taskId),
+		taskEvent(5, testTenant, taskId),
+	}, nil).Run(func(args mock.Arguments) {
+		es.Publish(taskEvent(5, testTenant, taskId))
+		es.Publish(taskEvent(6, "other", taskId))
+		es.Publish(taskEvent(7, testTenant, taskId))
+	})
+	server := NewTaskServer(nil, es)
+	ctx, cancel := context.WithCancel(models.WithTenant(context.Background(), testTenant))
+	stream := &eventStream{ctx: ctx}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.WatchTasks(&taskpb.WatchTasksRequest{
+			Status:      taskpb.TaskStatus_TASK_STATUS_DONE,
+			LastEventId: 3,
+		}, stream)
+	}()
+
+	assert.Eventually(t, func() bool { return len(stream.sent()) == 3 }, time.Second, 10*time.Millisecond)
+	cancel()
+	err := <-done
+
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, []int64{4, 5, 7}, stream.sent())
+	assert.Equal(t, []models.EventFilter{expectedFilter}, es.filters)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTaskServer_WatchTasksLagging(t *testing.T) {
+	taskId := uuid.New()
+	mockRepo := new(MockEventRepository)
+	es := newTestEventService(mockRepo, 1)
+	mockRepo.On("GetAfter", mock.Anything, int64(3), models.EventFilter{Tenant: testTenant}, 101).Return([]models.TaskEvent{
+		taskEvent(4, testTenant, taskId),
+	}, nil).Run(func(args mock.Arguments) {
+		// the second event overflows the buffer of the subscription
+		es.Publish(taskEvent(5, testTenant, taskId))
+		es.Publish(taskEvent(6, testTenant, taskId))
+	})
+	server := NewTaskServer(nil, es)
+	stream := &eventStream{ctx: models.WithTenant(context.Background(), testTenant)}
+
+	err := server.WatchTasks(&taskpb.WatchTasksRequest{LastEventId: 3}, stream)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, []int64{4, 5}, stream.sent())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTaskServer_WatchTasksReplayLimit(t *testing.T) {
+	taskId := uuid.New()
+	mockRepo := new(MockEventRepository)
+	es := newTestEventService(mockRepo, 8)
+	missed := make([]models.TaskEvent, 0, 101)
+	for id := int64(4); id < 105; id++ {
+		missed = append(missed, taskEvent(id, testTenant, taskId))
+	}
+	mockRepo.On("GetAfter", mock.Anything, int64(3), models.EventFilter{Tenant: testTenant}, 101).Return(missed, nil)
+	server := NewTaskServer(nil, es)
+	stream := &eventStream{ctx: models.WithTenant(context.Background(), testTenant)}
+
+	err := server.WatchTasks(&taskpb.WatchTasksRequest{LastEventId: 3}, stream)
+
+	// the client resumes from the last replayed event rather than skipping
+	// the events past the limit
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	sent := stream.sent()
+	assert.Len(t, sent, 100)
+	assert.Equal(t, int64(103), sent[len(sent)-1])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTaskServer_WatchTasksErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          *taskpb.WatchTasksRequest
+		mockSetup    func(*MockEventRepository)
+		expectedCode codes.Code
+	}{
+		{
+			name:         "invalid task id",
+			req:          &taskpb.WatchTasksRequest{TaskId: "not-a-uuid"},
+			mockSetup:    func(mockRepo *MockEventRepository) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "replay failed",
+			req:  &taskpb.WatchTasksRequest{LastEventId: 3},
+			mockSetup: func(mockRepo *MockEventRepository) {
+				mockRepo.On("GetAfter", mock.Anything, int64(3), models.EventFilter{Tenant: testTenant}, 101).Return(nil, errors.New("database error"))
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockEventRepository)
+			tt.mockSetup(mockRepo)
+			server := NewTaskServer(nil, newTestEventService(mockRepo, 8))
+			stream := &eventStream{ctx: models.WithTenant(context.Background(), testTenant)}
+
+			err := server.WatchTasks(tt.req, stream)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Empty(t, stream.sent())
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskServer_WatchTasksLiveOnly(t *testing.T) {
+	taskId := uuid.New()
+	mockRepo := new(MockEventRepository)
+	es := newTestEventService(mockRepo, 8)
+	server := NewTaskServer(nil, es)
+	ctx, cancel := context.WithCancel(models.WithTenant(context.Background(), testTenant))
+	stream := &eventStream{ctx: ctx}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.WatchTasks(&taskpb.WatchTasksRequest{TaskId: taskId.String()}, stream)
+	}()
+	assert.Eventually(t, func() bool {
+		es.mu.Lock()
+		defer es.mu.Unlock()
+		return len(es.filters) == 1
+	}, time.Second, 10*time.Millisecond)
+	es.Publish(taskEvent(1, testTenant, uuid.New()))
+	es.Publish(taskEvent(2, testTenant, taskId))
+
+	assert.Eventually(t, func() bool { return len(stream.sent()) == 1 }, time.Second, 10*time.Millisecond)
+	cancel()
+	err := <-done
+
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, []int64{2}, stream.sent())
+	mockRepo.AssertNotCalled(t, "GetAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
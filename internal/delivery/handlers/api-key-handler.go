@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"betera-tz/internal/delivery/apierr"
+	"betera-tz/internal/delivery/handlers/helper"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/internal/dto"
+	"encoding/json"
+	"net/http"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type ApiKeyHandler struct {
+	ApiKeyService services.ApiKeyService
+}
+
+func NewApiKeyHandler(as services.ApiKeyService) *ApiKeyHandler {
+	return &ApiKeyHandler{
+		ApiKeyService: as,
+	}
+}
+
+// PostAdminApiKeys godoc
+// @Summary Create an API key
+// @Description Issue an API key with the given scopes, bound to a tenant or to none. Only its hash is stored, so the returned key is shown only once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateApiKeyRequest true "API key to create"
+// @Success 201 {object} dto.CreateApiKeyResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/api-keys [post]
+func (ah *ApiKeyHandler) PostAdminApiKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := dto.CreateApiKeyRequest{}
+	if err := helper.DecodeJSON(r, &req); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		scopes = append(scopes, string(s))
+	}
+
+	tenant := ""
+	if req.Tenant != nil {
+		tenant = *req.Tenant
+	}
+
+	key, token, err := ah.ApiKeyService.Create(ctx, req.Name, tenant, scopes)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toCreateApiKeyResponse(key, token))
+}
+
+// GetAdminApiKeys godoc
+// @Summary List API keys
+// @Description Get all API keys including revoked ones, with the time each was last used.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} dto.ApiKeyResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/api-keys [get]
+func (ah *ApiKeyHandler) GetAdminApiKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	keys, err := ah.ApiKeyService.Get(ctx)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+// DeleteAdminApiKeysId godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key. Requests with it are rejected from then on; the key stays listed.
+// @Tags admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} dto.ApiResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/api-keys/{id} [delete]
+func (ah *ApiKeyHandler) DeleteAdminApiKeysId(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	ctx := r.Context()
+	if err := ah.ApiKeyService.Revoke(ctx, id); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ApiResponse{
+		Code:    http.StatusOK,
+		Message: "api key revoked",
+	})
+}
+
+// PostAdminApiKeysIdRotate godoc
+// @Summary Rotate an API key
+// @Description Replace the key of an active API key, keeping its name and scopes. The previous key stops working immediately and the new one is shown only once.
+// @Tags admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} dto.CreateApiKeyResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/api-keys/{id}/rotate [post]
+func (ah *ApiKeyHandler) PostAdminApiKeysIdRotate(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	ctx := r.Context()
+	key, token, err := ah.ApiKeyService.Rotate(ctx, id)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toCreateApiKeyResponse(key, token))
+}
+
+func toCreateApiKeyResponse(key *models.ApiKey, token string) dto.CreateApiKeyResponse {
+	res := dto.CreateApiKeyResponse{
+		Id:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Key:        token,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		RotatedAt:  key.RotatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+	if key.Tenant != "" {
+		res.Tenant = &key.Tenant
+	}
+	return res
+}
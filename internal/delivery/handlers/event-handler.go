@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"betera-tz/internal/delivery/apierr"
+	"betera-tz/internal/delivery/handlers/helper"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/internal/dto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type EventHandler struct {
+	EventService services.EventService
+	Heartbeat    time.Duration
+}
+
+func NewEventHandler(es services.EventService, heartbeat time.Duration) *EventHandler {
+	return &EventHandler{
+		EventService: es,
+		Heartbeat:    heartbeat,
+	}
+}
+
+// GetEvents godoc
+// @Summary Stream task events
+// @Description Stream task changes as Server-Sent Events. Send Last-Event-ID to receive the events missed since that id. When more events were missed than are replayed at once, the stream ends with a replay.truncated event; reconnect with the last id to receive the rest.
+// @Tags events
+// @Produce text/event-stream
+// @Param taskId query string false "Only events of this task"
+// @Param status query string false "Only events with this status" Enums(created, processing, done)
+// @Param Last-Event-ID header string false "Id of the last received event"
+// @Success 200 {string} string "Stream of task events"
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/events [get]
+func (eh *EventHandler) GetEvents(w http.ResponseWriter, r *http.Request, params dto.GetEventsParams) {
+	ctx := r.Context()
+	filter := models.EventFilter{
+		Tenant: models.TenantFromContext(ctx),
+		TaskID: params.TaskId,
+	}
+	if params.Status != nil {
+		filter.Status = string(*params.Status)
+	}
+	var lastId int64
+	if params.LastEventID != nil && *params.LastEventID != "" {
+		id, err := strconv.ParseInt(*params.LastEventID, 10, 64)
+		if err != nil {
+			helper.WriteJSONError(w, r, apierr.InvalidField("Last-Event-ID", "must be an integer"))
+			return
+		}
+		lastId = id
+	}
+
+	// Subscribe before replaying so nothing committed in between is lost;
+	// duplicates are skipped by comparing event ids.
+	sub := eh.EventService.Subscribe(filter)
+	defer sub.Close()
+
+	var missed []models.TaskEvent
+	var truncated bool
+	if lastId > 0 {
+		events, more, err := eh.EventService.GetAfter(ctx, lastId, filter)
+		if err != nil {
+			helper.WriteJSONError(w, r, apierr.ToApiError(err))
+			return
+		}
+		missed, truncated = events, more
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range missed {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+		lastId = event.ID
+	}
+	// Streaming live events after a truncated replay would skip the events
+	// in between, so the stream ends and the client reconnects from lastId.
+	if truncated {
+		fmt.Fprintf(w, "event: replay.truncated\ndata: {\"lastEventId\":%d}\n\n", lastId)
+		rc.Flush()
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	// a nil channel never fires, so no heartbeat is sent when it is disabled
+	var heartbeat <-chan time.Time
+	if eh.Heartbeat > 0 {
+		ticker := time.NewTicker(eh.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if event.ID <= lastId {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			lastId = event.ID
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event models.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: task.%s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,23 @@
+package handlers
+
+// Handlers combines the resource handlers into the single implementation of
+// the generated server interface.
+type Handlers struct {
+	*TaskHandler
+	*EventHandler
+	*WebhookHandler
+	*ImportHandler
+	*ApiKeyHandler
+	*QuotaHandler
+}
+
+func NewHandlers(th *TaskHandler, eh *EventHandler, wh *WebhookHandler, ih *ImportHandler, ah *ApiKeyHandler, qh *QuotaHandler) *Handlers {
+	return &Handlers{
+		TaskHandler:    th,
+		EventHandler:   eh,
+		WebhookHandler: wh,
+		ImportHandler:  ih,
+		ApiKeyHandler:  ah,
+		QuotaHandler:   qh,
+	}
+}
@@ -2,15 +2,125 @@ package helper
 
 import (
 	"betera-tz/internal/delivery/apierr"
+	"betera-tz/internal/dto"
+	"betera-tz/pkg/errs"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
-func WriteJSONError(w http.ResponseWriter, apiErr apierr.ApiErr) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(apiErr.Code)
-	json.NewEncoder(w).Encode(map[string]any{
-		"code":    apiErr.Code,
-		"message": apiErr.Message,
-	})
+// WriteJSONError writes the error as an RFC 7807 problem, identifying the
+// failed request by its path and request id.
+func WriteJSONError(w http.ResponseWriter, r *http.Request, apiErr apierr.ApiErr) {
+	problem := dto.Problem{
+		Type:     apiErr.Type(),
+		Title:    apiErr.Title,
+		Status:   apiErr.Status,
+		Code:     dto.ProblemCode(apiErr.Code),
+		Instance: &r.URL.Path,
+	}
+	if apiErr.Detail != "" {
+		problem.Detail = &apiErr.Detail
+	}
+	if id := middleware.GetReqID(r.Context()); id != "" {
+		problem.RequestId = &id
+	}
+	if len(apiErr.Violations) > 0 {
+		violations := make([]dto.Violation, 0, len(apiErr.Violations))
+		for _, v := range apiErr.Violations {
+			violations = append(violations, dto.Violation{Field: v.Field, Message: v.Message})
+		}
+		problem.Violations = &violations
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(apiErr.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// DecodeJSON decodes the request body into v. Malformed JSON and values of the
+// wrong type are returned as a validation error naming the offending field.
+func DecodeJSON(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return nil
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return errs.ValidationError{Violations: []errs.Violation{{
+			Field:   typeErr.Field,
+			Message: "must be " + jsonKind(typeErr.Type.Kind()),
+		}}}
+	}
+	if errors.Is(err, io.EOF) {
+		return errs.ValidationError{Violations: []errs.Violation{{Message: "request body is required"}}}
+	}
+	return errs.ValidationError{Violations: []errs.Violation{{Message: "request body is not valid JSON"}}}
+}
+
+func jsonKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// Fingerprint identifies a request by its method, path and decoded body, so
+// formatting differences in the JSON do not change the result.
+func Fingerprint(r *http.Request, body any) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	json.NewEncoder(h).Encode(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func ETag(version int) string {
+	return fmt.Sprintf("%q", strconv.Itoa(version))
+}
+
+// ParseETag extracts the version from a single entity tag, weak or strong.
+func ParseETag(tag string) (int, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// MatchETag reports whether a comma-separated If-None-Match style header
+// contains the given version or the "*" wildcard.
+func MatchETag(header string, version int) bool {
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == "*" {
+			return true
+		}
+		if v, ok := ParseETag(tag); ok && v == version {
+			return true
+		}
+	}
+	return false
 }
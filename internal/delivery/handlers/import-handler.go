@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"betera-tz/internal/delivery/apierr"
+	"betera-tz/internal/delivery/handlers/helper"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/internal/dto"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// importContentTypes maps the accepted upload media types to import formats.
+var importContentTypes = map[string]string{
+	"text/csv":             models.ImportFormatCSV,
+	"application/x-ndjson": models.ImportFormatNDJSON,
+	"application/jsonl":    models.ImportFormatNDJSON,
+}
+
+type ImportHandler struct {
+	ImportService services.ImportService
+}
+
+func NewImportHandler(is services.ImportService) *ImportHandler {
+	return &ImportHandler{
+		ImportService: is,
+	}
+}
+
+// PostTasksImport godoc
+// @Summary Import tasks from a file
+// @Description Upload a CSV file with a header row or an NDJSON file. Rows are validated like created tasks and inserted in batches in the background.
+// @Description The format is taken from the format parameter or from Content-Type. Follow the Location header to track the import.
+// @Tags imports
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "File format" Enums(csv, ndjson)
+// @Param enqueue query bool false "Send imported tasks with status created to the processing queue"
+// @Param file body string true "File with tasks"
+// @Success 202 {object} dto.ImportResponse
+// @Header 202 {string} Location "URL of the import job"
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 413 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/tasks/import [post]
+func (ih *ImportHandler) PostTasksImport(w http.ResponseWriter, r *http.Request, params dto.PostTasksImportParams) {
+	ctx := r.Context()
+	format := ""
+	if params.Format != nil {
+		format = string(*params.Format)
+	} else if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		format = importContentTypes[mediaType]
+	}
+	if format == "" {
+		helper.WriteJSONError(w, r, apierr.InvalidField("format", "must be one of csv, ndjson or given by Content-Type"))
+		return
+	}
+	enqueue := params.Enqueue != nil && *params.Enqueue
+
+	// Large files take longer to upload than the server read timeout allows;
+	// the size limit of the import bounds the upload instead.
+	http.NewResponseController(w).SetReadDeadline(time.Time{})
+	imp, err := ih.ImportService.Create(ctx, format, enqueue, r.Body)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/imports/"+imp.ID.String())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(imp)
+}
+
+// GetImportsId godoc
+// @Summary Get import by ID
+// @Description Get the status and row counters of an import.
+// @Tags imports
+// @Produce json
+// @Param id path string true "Import ID"
+// @Success 200 {object} dto.ImportResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/imports/{id} [get]
+func (ih *ImportHandler) GetImportsId(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	ctx := r.Context()
+	imp, err := ih.ImportService.GetById(ctx, id)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(imp)
+}
+
+// GetImportsIdErrors godoc
+// @Summary Download import error report
+// @Description Download the rejected rows of an import as CSV with row, field and message columns. Row is the line number in the uploaded file.
+// @Tags imports
+// @Produce text/csv
+// @Param id path string true "Import ID"
+// @Success 200 {string} string "Error report"
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/imports/{id}/errors [get]
+func (ih *ImportHandler) GetImportsIdErrors(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	ctx := r.Context()
+	rc := http.NewResponseController(w)
+	var (
+		cw      *csv.Writer
+		started bool
+	)
+	// The report has no size limit, so it is streamed past the server's
+	// write timeout like the task export.
+	start := func() error {
+		started = true
+		rc.SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%s-errors.csv"`, id))
+		w.WriteHeader(http.StatusOK)
+		cw = csv.NewWriter(w)
+		return cw.Write([]string{"row", "field", "message"})
+	}
+
+	err := ih.ImportService.GetErrors(ctx, id, func(ie *models.ImportError) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		// field and message may echo the uploaded file
+		return cw.Write([]string{strconv.Itoa(ie.Row), escapeFormula(ie.Field), escapeFormula(ie.Message)})
+	})
+	if err != nil {
+		if !started {
+			helper.WriteJSONError(w, r, apierr.ToApiError(err))
+			return
+		}
+		panic(http.ErrAbortHandler)
+	}
+	if !started {
+		if err := start(); err != nil {
+			panic(http.ErrAbortHandler)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubImportService streams errors as the rejected rows of every import.
+type stubImportService struct {
+	services.ImportService
+	errors []models.ImportError
+}
+
+func (s *stubImportService) GetErrors(ctx context.Context, id uuid.UUID, fn func(*models.ImportError) error) error {
+	for i := range s.errors {
+		if err := fn(&s.errors[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestImportHandler_GetImportsIdErrors(t *testing.T) {
+	handler := NewImportHandler(&stubImportService{errors: []models.ImportError{
+		{Row: 2, Field: "title", Message: "is required"},
+		{Row: 3, Field: "=cmd|' /C calc'!A0", Message: "-unknown column"},
+	}})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/imports/id/errors", nil)
+	rec := httptest.NewRecorder()
+
+	handler.GetImportsIdErrors(rec, req, uuid.New())
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, [][]string{
+		{"row", "field", "message"},
+		{"2", "title", "is required"},
+		{"3", "'=cmd|' /C calc'!A0", "'-unknown column"},
+	}, records)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"betera-tz/internal/delivery/apierr"
+	"betera-tz/internal/delivery/handlers/helper"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/internal/dto"
+	"encoding/json"
+	"net/http"
+)
+
+type QuotaHandler struct {
+	QuotaService services.QuotaService
+}
+
+func NewQuotaHandler(qs services.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		QuotaService: qs,
+	}
+}
+
+// GetAdminQuotas godoc
+// @Summary List tenant quotas
+// @Description Get the quotas set for tenants. Tenants not listed have the default quota of the configuration.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} dto.QuotaResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/quotas [get]
+func (qh *QuotaHandler) GetAdminQuotas(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	quotas, err := qh.QuotaService.List(ctx)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(quotas)
+}
+
+// GetAdminQuotasTenant godoc
+// @Summary Get the quota of a tenant
+// @Description Get the quota of a tenant, the default one when it has none of its own, with the tasks it currently counts.
+// @Tags admin
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Success 200 {object} dto.QuotaUsageResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/quotas/{tenant} [get]
+func (qh *QuotaHandler) GetAdminQuotasTenant(w http.ResponseWriter, r *http.Request, tenant string) {
+	ctx := r.Context()
+	quota, usage, err := qh.QuotaService.Get(ctx, tenant)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	res := dto.QuotaUsageResponse{
+		Tenant:        quota.Tenant,
+		MaxActive:     quota.MaxActive,
+		MaxProcessing: quota.MaxProcessing,
+		MaxDaily:      quota.MaxDaily,
+		UpdatedAt:     quota.UpdatedAt,
+	}
+	res.Usage.Active = usage.Active
+	res.Usage.Processing = usage.Processing
+	res.Usage.CreatedToday = usage.CreatedToday
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+// PutAdminQuotasTenant godoc
+// @Summary Set the quota of a tenant
+// @Description Give a tenant a quota of its own in place of the default one. Zero limits are unlimited; lowering a limit keeps the tasks the tenant already has.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Param request body dto.SetQuotaRequest true "Quota to set"
+// @Success 200 {object} dto.QuotaResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/quotas/{tenant} [put]
+func (qh *QuotaHandler) PutAdminQuotasTenant(w http.ResponseWriter, r *http.Request, tenant string) {
+	ctx := r.Context()
+	req := dto.SetQuotaRequest{}
+	if err := helper.DecodeJSON(r, &req); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	quota, err := qh.QuotaService.Set(ctx, models.Quota{
+		Tenant:        tenant,
+		MaxActive:     req.MaxActive,
+		MaxProcessing: req.MaxProcessing,
+		MaxDaily:      req.MaxDaily,
+	})
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(quota)
+}
+
+// DeleteAdminQuotasTenant godoc
+// @Summary Delete the quota of a tenant
+// @Description Return a tenant to the default quota of the configuration.
+// @Tags admin
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Success 200 {object} dto.ApiResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/quotas/{tenant} [delete]
+func (qh *QuotaHandler) DeleteAdminQuotasTenant(w http.ResponseWriter, r *http.Request, tenant string) {
+	ctx := r.Context()
+	if err := qh.QuotaService.Delete(ctx, tenant); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ApiResponse{
+		Code:    http.StatusOK,
+		Message: "quota deleted",
+	})
+}
@@ -3,73 +3,157 @@ package handlers
 import (
 	"betera-tz/internal/delivery/apierr"
 	"betera-tz/internal/delivery/handlers/helper"
+	"betera-tz/internal/domain/models"
 	"betera-tz/internal/domain/services"
 	"betera-tz/internal/dto"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 type TaskHandler struct {
-	TaskService services.TaskService
+	TaskService        services.TaskService
+	IdempotencyService services.IdempotencyService
+	MaxWait            time.Duration
 }
 
-func NewTaskHandler(ts services.TaskService) *TaskHandler {
+// waitMargin leaves room to write the response before the server's write or
+// request timeout cuts a long-polling request off.
+const waitMargin = 500 * time.Millisecond
+
+// defaultMaxWait caps long polling when the server has no timeout to cap it.
+const defaultMaxWait = time.Minute
+
+// NewTaskHandler caps long polling just below timeout, the server timeout
+// of a request, or at defaultMaxWait when it is 0 and requests never time out.
+func NewTaskHandler(ts services.TaskService, is services.IdempotencyService, timeout time.Duration) *TaskHandler {
+	maxWait := defaultMaxWait
+	if timeout > 0 {
+		maxWait = max(timeout-waitMargin, 0)
+	}
 	return &TaskHandler{
-		TaskService: ts,
+		TaskService:        ts,
+		IdempotencyService: is,
+		MaxWait:            maxWait,
 	}
 }
 
 // PostTasks godoc
 // @Summary Create a new task
-// @Description Create a new task with title and description
+// @Description Create a new task with title and description. Requests repeated with the same Idempotency-Key replay the original response.
+// @Description When callbackUrl is set, every status change of the task is posted to it as a signed webhook.
 // @Tags tasks
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
 // @Param request body dto.CreateTaskRequest true "Task to create"
 // @Success 201 {object} dto.CreateTaskResponse
-// @Failure 400 {object} dto.ApiResponse
-// @Failure 403 {object} dto.ApiResponse
-// @Failure 500 {object} dto.ApiResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 409 {object} dto.Problem
+// @Failure 422 {object} dto.Problem
+// @Failure 429 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/v1/tasks [post]
-func (th *TaskHandler) PostTasks(w http.ResponseWriter, r *http.Request) {
+func (th *TaskHandler) PostTasks(w http.ResponseWriter, r *http.Request, params dto.PostTasksParams) {
 	ctx := r.Context()
 	req := dto.CreateTaskRequest{}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		helper.WriteJSONError(w, apierr.InvalidRequest())
+	if err := helper.DecodeJSON(r, &req); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
 		return
 	}
 
-	id, err := th.TaskService.Create(ctx, req.Title, req.Description)
+	key := ""
+	if params.IdempotencyKey != nil {
+		key = *params.IdempotencyKey
+	}
+	if key != "" {
+		record, err := th.IdempotencyService.Begin(ctx, key, helper.Fingerprint(r, req))
+		if err != nil {
+			helper.WriteJSONError(w, r, apierr.ToApiError(err))
+			return
+		}
+		if record != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(record.StatusCode)
+			w.Write(record.Response)
+			return
+		}
+	}
+
+	callbackURL := ""
+	if req.CallbackUrl != nil {
+		callbackURL = *req.CallbackUrl
+	}
+	id, err := th.TaskService.Create(ctx, req.Title, req.Description, callbackURL)
 	if err != nil {
-		helper.WriteJSONError(w, apierr.ToApiError(err))
+		if key != "" {
+			// the service logs a failure, the key then expires after its TTL
+			_ = th.IdempotencyService.Release(context.WithoutCancel(ctx), key)
+		}
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(dto.CreateTaskResponse{
+	resp, _ := json.Marshal(dto.CreateTaskResponse{
 		Id: *id,
 	})
+	if key != "" {
+		// the task is created either way, so a failure to store the response
+		// is logged by the service and retries see the request in progress
+		// until the key expires rather than creating the task again
+		_ = th.IdempotencyService.Complete(context.WithoutCancel(ctx), key, http.StatusCreated, resp)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
 }
 
 // PatchTasksIdStatus godoc
 // @Summary Update task status
-// @Description Update status of a task by ID
+// @Description Update status of a task by ID. With If-Match the update is applied only if the task's version still matches.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
 // @Param status query dto.PatchTasksIdStatusParams true "New status"
+// @Param If-Match header string false "ETag of the task version being modified"
 // @Success 200 {object} dto.ApiResponse
-// @Failure 400 {object} dto.ApiResponse
-// @Failure 404 {object} dto.ApiResponse
-// @Failure 500 {object} dto.ApiResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 412 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/v1/tasks/{id}/status [patch]
 func (th *TaskHandler) PatchTasksIdStatus(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params dto.PatchTasksIdStatusParams) {
 	ctx := r.Context()
-	if err := th.TaskService.UpdateStatus(ctx, id.String(), params.Status); err != nil {
-		helper.WriteJSONError(w, apierr.ToApiError(err))
+	var version *int
+	if params.IfMatch != nil && strings.TrimSpace(*params.IfMatch) != "*" {
+		v, ok := helper.ParseETag(*params.IfMatch)
+		if !ok {
+			helper.WriteJSONError(w, r, apierr.PreconditionFailed())
+			return
+		}
+		version = &v
+	}
+	if err := th.TaskService.UpdateStatus(ctx, id.String(), params.Status, version); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
 		return
 	}
 
@@ -81,6 +165,58 @@ func (th *TaskHandler) PatchTasksIdStatus(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// PostTasksIdAssign godoc
+// @Summary Assign a task
+// @Description Set the assignee of a task to a caller id, or to the caller with "me". A null, empty or "none" assignee unassigns the task.
+// @Description With If-Match the assignment is applied only if the task's version still matches.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param If-Match header string false "ETag of the task version being modified"
+// @Param request body dto.AssignTaskRequest true "New assignee"
+// @Success 200 {object} dto.TaskResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 412 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/tasks/{id}/assign [post]
+func (th *TaskHandler) PostTasksIdAssign(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params dto.PostTasksIdAssignParams) {
+	ctx := r.Context()
+	req := dto.AssignTaskRequest{}
+	if err := helper.DecodeJSON(r, &req); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+	var version *int
+	if params.IfMatch != nil && strings.TrimSpace(*params.IfMatch) != "*" {
+		v, ok := helper.ParseETag(*params.IfMatch)
+		if !ok {
+			helper.WriteJSONError(w, r, apierr.PreconditionFailed())
+			return
+		}
+		version = &v
+	}
+	assignee := ""
+	if req.Assignee != nil {
+		assignee = *req.Assignee
+	}
+	task, err := th.TaskService.Assign(ctx, id.String(), assignee, version)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("ETag", helper.ETag(task.Version))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
 // GetTasks godoc
 // @Summary List tasks
 // @Description Get a paginated list of tasks. All query parameters are optional.
@@ -90,17 +226,22 @@ func (th *TaskHandler) PatchTasksIdStatus(w http.ResponseWriter, r *http.Request
 // @Param amount query int false "Number of tasks per page"
 // @Param page query int false "Page number"
 // @Param statusFilter query string false "Filter by task status" Enums(created, processing, done)
+// @Param createdBy query string false "Filter by creator: a caller id, me or none"
+// @Param assignee query string false "Filter by assignee: a caller id, me or none"
 // @Success 200 {array} dto.TaskResponse "List of tasks"
-// @Failure 400 {object} dto.ApiResponse "Bad request"
-// @Failure 500 {object} dto.ApiResponse "Internal server error"
+// @Failure 400 {object} dto.Problem "Bad request"
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem "Internal server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/v1/tasks [get]
 func (th *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request, params dto.GetTasksParams) {
 	ctx := r.Context()
 
 	var (
-		amount       int
-		page         int
-		statusFilter string
+		amount int
+		page   int
 	)
 	if params.Amount == nil || params.Page == nil || *params.Amount <= 0 || *params.Page <= 0 {
 		amount = -1
@@ -110,17 +251,20 @@ func (th *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request, params d
 		page = *params.Page
 	}
 
-	def := ""
-
-	if params.StatusFilter == nil {
-		statusFilter = def
-	} else {
-		statusFilter = string(*params.StatusFilter)
+	filter := models.TaskFilter{}
+	if params.StatusFilter != nil {
+		filter.Status = string(*params.StatusFilter)
+	}
+	if params.CreatedBy != nil {
+		filter.CreatedBy = *params.CreatedBy
+	}
+	if params.Assignee != nil {
+		filter.Assignee = *params.Assignee
 	}
 
-	tasks, err := th.TaskService.Get(ctx, amount, page, statusFilter)
+	tasks, err := th.TaskService.Get(ctx, amount, page, filter)
 	if err != nil {
-		helper.WriteJSONError(w, apierr.ToApiError(err))
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
 		return
 	}
 
@@ -129,27 +273,420 @@ func (th *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request, params d
 	json.NewEncoder(w).Encode(tasks)
 }
 
+// GetTasksStats godoc
+// @Summary Get task statistics
+// @Description Get task counts by status, tasks created and finished per time bucket and processing durations in seconds over the window.
+// @Description Counts cover all tasks; buckets and durations cover the window, which starts at the beginning of its first bucket.
+// @Tags tasks
+// @Produce json
+// @Param window query string false "How far back to aggregate, up to 90 days" default(24h)
+// @Param bucket query string false "Size of a time bucket, at least 1m and at most 1000 buckets per window" default(1h)
+// @Success 200 {object} dto.TaskStatsResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/tasks/stats [get]
+func (th *TaskHandler) GetTasksStats(w http.ResponseWriter, r *http.Request, params dto.GetTasksStatsParams) {
+	ctx := r.Context()
+	var window, bucket time.Duration
+	if params.Window != nil {
+		d, err := time.ParseDuration(*params.Window)
+		if err != nil {
+			helper.WriteJSONError(w, r, apierr.InvalidField("window", "must be a duration such as 24h"))
+			return
+		}
+		window = d
+	}
+	if params.Bucket != nil {
+		d, err := time.ParseDuration(*params.Bucket)
+		if err != nil {
+			helper.WriteJSONError(w, r, apierr.InvalidField("bucket", "must be a duration such as 1h"))
+			return
+		}
+		bucket = d
+	}
+
+	stats, err := th.TaskService.Stats(ctx, window, bucket)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	buckets := make([]dto.StatsBucket, 0, len(stats.Buckets))
+	for _, b := range stats.Buckets {
+		buckets = append(buckets, dto.StatsBucket{Start: b.Start, Created: b.Created, Finished: b.Finished})
+	}
+	p := stats.Processing
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.TaskStatsResponse{
+		From:    stats.From,
+		To:      stats.To,
+		Bucket:  stats.Bucket.String(),
+		Total:   stats.Total,
+		Counts:  stats.Counts,
+		Buckets: buckets,
+		Processing: dto.DurationStats{
+			Count: p.Count,
+			Avg:   p.Avg.Seconds(),
+			P50:   p.P50.Seconds(),
+			P90:   p.P90.Seconds(),
+			P99:   p.P99.Seconds(),
+			Max:   p.Max.Seconds(),
+		},
+	})
+}
+
 // GetTasksId godoc
 // @Summary Get task by ID
-// @Description Get detailed information about a task by its ID
+// @Description Get detailed information about a task by its ID. The response carries an ETag; If-None-Match returns 304 while the task is unchanged.
+// @Description With waitFor the request blocks until the task reaches one of the statuses or the timeout elapses.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
+// @Param waitFor query []string false "Statuses to wait for" collectionFormat(csv) Enums(created, processing, done)
+// @Param timeout query string false "How long to wait, e.g. 30s. Capped by the server write and request timeouts, or 1m when the server has none"
+// @Param If-None-Match header string false "ETag of a cached task version"
 // @Success 200 {object} dto.TaskResponse
-// @Failure 400 {object} dto.ApiResponse
-// @Failure 404 {object} dto.ApiResponse
-// @Failure 500 {object} dto.ApiResponse
+// @Success 304 "Not modified"
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/v1/tasks/{id} [get]
-func (th *TaskHandler) GetTasksId(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+func (th *TaskHandler) GetTasksId(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params dto.GetTasksIdParams) {
 	ctx := r.Context()
-	task, err := th.TaskService.GetById(ctx, id.String())
+	var (
+		task *models.Task
+		err  error
+	)
+	if params.WaitFor != nil && len(*params.WaitFor) > 0 {
+		timeout := th.MaxWait
+		if params.Timeout != nil {
+			requested, err := time.ParseDuration(*params.Timeout)
+			if err != nil || requested < 0 {
+				helper.WriteJSONError(w, r, apierr.InvalidField("timeout", "must be a duration such as 30s"))
+				return
+			}
+			timeout = min(requested, th.MaxWait)
+		}
+		statuses := make([]string, 0, len(*params.WaitFor))
+		for _, s := range *params.WaitFor {
+			statuses = append(statuses, string(s))
+		}
+		var reached bool
+		task, reached, err = th.TaskService.WaitForStatus(ctx, id.String(), statuses, timeout)
+		if err == nil && !reached {
+			w.Header().Set("X-Wait-Timed-Out", "true")
+		}
+	} else {
+		task, err = th.TaskService.GetById(ctx, id.String())
+	}
 	if err != nil {
-		helper.WriteJSONError(w, apierr.ToApiError(err))
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(task.Version))
+	if params.IfNoneMatch != nil && helper.MatchETag(*params.IfNoneMatch, task.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(task)
 }
+
+// PostTasksBulk godoc
+// @Summary Bulk update status or delete tasks
+// @Description Apply a status transition or delete tasks selected by an id list, a non-empty filter or all: true. Use dryRun to preview the affected count.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param dryRun query bool false "Preview the operation without applying it"
+// @Param request body dto.BulkTaskRequest true "Bulk operation"
+// @Success 200 {object} dto.BulkTaskResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/tasks/bulk [post]
+func (th *TaskHandler) PostTasksBulk(w http.ResponseWriter, r *http.Request, params dto.PostTasksBulkParams) {
+	ctx := r.Context()
+	req := dto.BulkTaskRequest{}
+	if err := helper.DecodeJSON(r, &req); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	selection := models.BulkSelection{All: req.All != nil && *req.All}
+	if req.Ids != nil {
+		selection.IDs = *req.Ids
+	}
+	if req.Filter != nil {
+		selection.Filter = &models.TaskFilter{}
+		if req.Filter.StatusFilter != nil {
+			selection.Filter.Status = string(*req.Filter.StatusFilter)
+		}
+		if req.Filter.CreatedBy != nil {
+			selection.Filter.CreatedBy = *req.Filter.CreatedBy
+		}
+		if req.Filter.Assignee != nil {
+			selection.Filter.Assignee = *req.Filter.Assignee
+		}
+	}
+	dryRun := params.DryRun != nil && *params.DryRun
+
+	var (
+		res *models.BulkResult
+		err error
+	)
+	switch req.Action {
+	case dto.BulkTaskRequestActionUpdateStatus:
+		status := ""
+		if req.Status != nil {
+			status = string(*req.Status)
+		}
+		res, err = th.TaskService.BulkUpdateStatus(ctx, selection, status, dryRun)
+	case dto.BulkTaskRequestActionDelete:
+		res, err = th.TaskService.BulkDelete(ctx, selection, dryRun)
+	default:
+		helper.WriteJSONError(w, r, apierr.InvalidField("action", "must be one of updateStatus, delete"))
+		return
+	}
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.BulkTaskResponse{
+		Matched:  res.Matched,
+		Affected: res.Affected,
+		Skipped:  res.Skipped,
+		DryRun:   res.DryRun,
+	})
+}
+
+// exportFlushEvery is how many rows are buffered before an export is flushed
+// to the client.
+const exportFlushEvery = 500
+
+// GetTasksExport godoc
+// @Summary Export tasks
+// @Description Stream every task matching the filter as CSV or NDJSON. The export is gzip-compressed when the client sends Accept-Encoding: gzip.
+// @Tags tasks
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, ndjson) default(csv)
+// @Param statusFilter query string false "Filter by task status" Enums(created, processing, done)
+// @Param createdBy query string false "Filter by creator: a caller id, me or none"
+// @Param assignee query string false "Filter by assignee: a caller id, me or none"
+// @Success 200 {string} string "Exported tasks"
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/tasks/export [get]
+func (th *TaskHandler) GetTasksExport(w http.ResponseWriter, r *http.Request, params dto.GetTasksExportParams) {
+	ctx := r.Context()
+
+	format := dto.GetTasksExportParamsFormatCsv
+	if params.Format != nil {
+		format = *params.Format
+	}
+	var contentType string
+	switch format {
+	case dto.GetTasksExportParamsFormatCsv:
+		contentType = "text/csv; charset=utf-8"
+	case dto.GetTasksExportParamsFormatNdjson:
+		contentType = "application/x-ndjson"
+	default:
+		helper.WriteJSONError(w, r, apierr.InvalidField("format", "must be one of csv, ndjson"))
+		return
+	}
+	filter := models.TaskFilter{}
+	if params.StatusFilter != nil {
+		filter.Status = string(*params.StatusFilter)
+	}
+	if params.CreatedBy != nil {
+		filter.CreatedBy = *params.CreatedBy
+	}
+	if params.Assignee != nil {
+		filter.Assignee = *params.Assignee
+	}
+
+	rc := http.NewResponseController(w)
+	var (
+		enc     taskEncoder
+		gz      *gzip.Writer
+		started bool
+		rows    int
+	)
+	// start commits the response headers. It runs on the first row so that a
+	// failure before any output can still be reported as a problem response.
+	start := func() error {
+		started = true
+		rc.SetWriteDeadline(time.Time{})
+		out := io.Writer(w)
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks-%s.%s"`,
+			time.Now().UTC().Format("20060102T150405Z"), format))
+		w.Header().Set("Vary", "Accept-Encoding")
+		if acceptsGzip(r) {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz = gzip.NewWriter(w)
+			out = gz
+		}
+		w.WriteHeader(http.StatusOK)
+		enc = newTaskEncoder(format, out)
+		return enc.header()
+	}
+	flush := func() error {
+		if err := enc.flush(); err != nil {
+			return err
+		}
+		if gz != nil {
+			if err := gz.Flush(); err != nil {
+				return err
+			}
+		}
+		return rc.Flush()
+	}
+
+	err := th.TaskService.Export(ctx, filter, func(task *models.Task) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := enc.encode(task); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			helper.WriteJSONError(w, r, apierr.ToApiError(err))
+			return
+		}
+		// The status line is already sent, so the only way to tell the
+		// client the export is incomplete is to break the connection.
+		panic(http.ErrAbortHandler)
+	}
+	if !started {
+		if err := start(); err != nil {
+			panic(http.ErrAbortHandler)
+		}
+	}
+	if err := enc.flush(); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// acceptsGzip reports whether the client listed gzip in Accept-Encoding
+// without disabling it through q=0.
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// taskEncoder writes exported tasks in one of the export formats.
+type taskEncoder interface {
+	header() error
+	encode(task *models.Task) error
+	flush() error
+}
+
+func newTaskEncoder(format dto.GetTasksExportParamsFormat, w io.Writer) taskEncoder {
+	if format == dto.GetTasksExportParamsFormatNdjson {
+		bw := bufio.NewWriter(w)
+		return &ndjsonEncoder{w: bw, enc: json.NewEncoder(bw)}
+	}
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) header() error {
+	return e.w.Write([]string{"id", "title", "description", "status", "version", "callbackUrl", "createdBy", "assignee", "processedBy"})
+}
+
+func (e *csvEncoder) encode(task *models.Task) error {
+	return e.w.Write([]string{
+		task.ID.String(),
+		escapeFormula(task.Title),
+		escapeFormula(task.Description),
+		task.Status,
+		strconv.Itoa(task.Version),
+		escapeFormula(task.CallbackURL),
+		escapeFormula(task.CreatedBy),
+		escapeFormula(task.Assignee),
+		escapeFormula(task.ProcessedBy),
+	})
+}
+
+// escapeFormula prefixes a value that a spreadsheet would run as a formula
+// with a quote, so a CSV column of user input cannot inject one.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) header() error {
+	return nil
+}
+
+func (e *ndjsonEncoder) encode(task *models.Task) error {
+	return e.enc.Encode(task)
+}
+
+func (e *ndjsonEncoder) flush() error {
+	return e.w.Flush()
+}
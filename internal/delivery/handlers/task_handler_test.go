@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"betera-tz/internal/domain/models"
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name                string
+		title               string
+		description         string
+		expectedTitle       string
+		expectedDescription string
+	}{
+		{
+			name:                "plain text",
+			title:               "Task title",
+			description:         "Task description",
+			expectedTitle:       "Task title",
+			expectedDescription: "Task description",
+		},
+		{
+			name:                "formulas",
+			title:               "=HYPERLINK(\"https://example.com\")",
+			description:         "+1+1",
+			expectedTitle:       "'=HYPERLINK(\"https://example.com\")",
+			expectedDescription: "'+1+1",
+		},
+		{
+			name:                "minus and at sign",
+			title:               "-2+3",
+			description:         "@SUM(A1:A2)",
+			expectedTitle:       "'-2+3",
+			expectedDescription: "'@SUM(A1:A2)",
+		},
+		{
+			name:                "tab and carriage return",
+			title:               "\t=1",
+			description:         "\r=1",
+			expectedTitle:       "'\t=1",
+			expectedDescription: "'\r=1",
+		},
+		{
+			name:                "formula character inside the value",
+			title:               "a=b",
+			description:         "",
+			expectedTitle:       "a=b",
+			expectedDescription: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			encoder := &csvEncoder{w: csv.NewWriter(&buf)}
+
+			err := encoder.encode(&models.Task{ID: uuid.New(), Title: tt.title, Description: tt.description, Status: "created"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := encoder.flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			record, err := csv.NewReader(&buf).Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedTitle, record[1])
+			assert.Equal(t, tt.expectedDescription, record[2])
+		})
+	}
+}
+
+func TestCSVEncoder_EncodeUserColumns(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := &csvEncoder{w: csv.NewWriter(&buf)}
+	task := &models.Task{
+		ID:          uuid.New(),
+		Title:       "Task title",
+		Description: "Task description",
+		Status:      "done",
+		Version:     3,
+		CallbackURL: "https://example.com/hooks",
+		CreatedBy:   "user:=1+1",
+		Assignee:    "=HYPERLINK(\"https://example.com\")",
+		ProcessedBy: "@worker",
+	}
+
+	if err := encoder.encode(task); err != nil {
+		t.Fatal(err)
+	}
+	if err := encoder.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := csv.NewReader(&buf).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{
+		task.ID.String(),
+		"Task title",
+		"Task description",
+		"done",
+		"3",
+		"https://example.com/hooks",
+		"user:=1+1",
+		"'=HYPERLINK(\"https://example.com\")",
+		"'@worker",
+	}, record)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"betera-tz/internal/delivery/apierr"
+	"betera-tz/internal/delivery/handlers/helper"
+	"betera-tz/internal/domain/services"
+	"betera-tz/internal/dto"
+	"encoding/json"
+	"net/http"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type WebhookHandler struct {
+	WebhookService services.WebhookService
+}
+
+func NewWebhookHandler(ws services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		WebhookService: ws,
+	}
+}
+
+// PostWebhooks godoc
+// @Summary Create a webhook subscription
+// @Description Subscribe a URL to task status changes. Payloads are signed with HMAC-SHA256 using the returned secret, which is shown only once.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWebhookRequest true "Webhook to create"
+// @Success 201 {object} dto.CreateWebhookResponse
+// @Failure 400 {object} dto.Problem
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks [post]
+func (wh *WebhookHandler) PostWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := dto.CreateWebhookRequest{}
+	if err := helper.DecodeJSON(r, &req); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+	statuses := []string{}
+	if req.Statuses != nil {
+		for _, s := range *req.Statuses {
+			statuses = append(statuses, string(s))
+		}
+	}
+
+	hook, err := wh.WebhookService.Create(ctx, req.Url, statuses)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.CreateWebhookResponse{
+		Id:           hook.ID,
+		Url:          hook.URL,
+		Secret:       hook.Secret,
+		Statuses:     hook.Statuses,
+		Active:       hook.Active,
+		FailureCount: hook.FailureCount,
+		DisabledAt:   hook.DisabledAt,
+		CreatedAt:    hook.CreatedAt,
+	})
+}
+
+// GetWebhooks godoc
+// @Summary List webhook subscriptions
+// @Description Get all webhook subscriptions including disabled ones.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} dto.WebhookResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks [get]
+func (wh *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	hooks, err := wh.WebhookService.Get(ctx)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(hooks)
+}
+
+// GetWebhooksCallbackSecret godoc
+// @Summary Get the task callback secret
+// @Description Get the secret signing the callbackUrl deliveries of the tasks of the tenant. Every tenant has its own, so it needs the tasks:write role that may set callback urls.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} dto.CallbackSecretResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/callback-secret [get]
+func (wh *WebhookHandler) GetWebhooksCallbackSecret(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	secret, err := wh.WebhookService.CallbackSecret(ctx)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.CallbackSecretResponse{Secret: secret})
+}
+
+// GetWebhooksId godoc
+// @Summary Get webhook subscription by ID
+// @Description Get a webhook subscription with its failure counter.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/{id} [get]
+func (wh *WebhookHandler) GetWebhooksId(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	ctx := r.Context()
+	hook, err := wh.WebhookService.GetById(ctx, id)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// DeleteWebhooksId godoc
+// @Summary Delete webhook subscription
+// @Description Delete a webhook subscription together with its pending deliveries.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} dto.ApiResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/{id} [delete]
+func (wh *WebhookHandler) DeleteWebhooksId(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	ctx := r.Context()
+	if err := wh.WebhookService.Delete(ctx, id); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ApiResponse{
+		Code:    http.StatusOK,
+		Message: "webhook deleted",
+	})
+}
+
+// PostWebhooksIdEnable godoc
+// @Summary Re-enable a disabled webhook subscription
+// @Description Re-enable a webhook disabled after repeated failures. Its pending deliveries are resumed.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} dto.ApiResponse
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/{id}/enable [post]
+func (wh *WebhookHandler) PostWebhooksIdEnable(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	ctx := r.Context()
+	if err := wh.WebhookService.Enable(ctx, id); err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ApiResponse{
+		Code:    http.StatusOK,
+		Message: "webhook enabled",
+	})
+}
+
+// GetWebhooksIdDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Get the most recent deliveries of a webhook, newest first, each with its log of attempts.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Number of deliveries, 50 by default"
+// @Success 200 {array} dto.WebhookDelivery
+// @Failure 401 {object} dto.Problem
+// @Failure 403 {object} dto.Problem
+// @Failure 404 {object} dto.Problem
+// @Failure 500 {object} dto.Problem
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (wh *WebhookHandler) GetWebhooksIdDeliveries(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params dto.GetWebhooksIdDeliveriesParams) {
+	ctx := r.Context()
+	limit := defaultDeliveriesLimit
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = min(*params.Limit, maxDeliveriesLimit)
+	}
+	deliveries, err := wh.WebhookService.GetDeliveries(ctx, id, limit)
+	if err != nil {
+		helper.WriteJSONError(w, r, apierr.ToApiError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
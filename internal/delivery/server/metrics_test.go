@@ -0,0 +1,45 @@
+package server
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/pkg/monitoring"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPrometheus registers the metrics once, as registering twice panics.
+var testPrometheus = sync.OnceValue(func() *monitoring.PrometheusSetup {
+	return monitoring.NewPrometheusSetup(config.MonitoringConfig{Namespace: "test"})
+})
+
+func TestMetricsMiddleware(t *testing.T) {
+	ps := testPrometheus()
+	r := chi.NewRouter()
+	r.Use(MetricsMiddleware(ps))
+	r.Get("/api/v1/tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(ps.HTTPInFlight))
+		w.Write([]byte("task"))
+	})
+
+	for _, path := range []string{
+		"/api/v1/tasks/0b7ad2b4-3f0a-4d9b-9a39-5d0b0a6f1e21",
+		"/api/v1/tasks/5d0b0a6f-3f0a-4d9b-9a39-0b7ad2b41e21",
+		"/wp-login.php",
+		"/.env",
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2, testutil.CollectAndCount(ps.HTTPRequestsTotal))
+	assert.Equal(t, 2.0, testutil.ToFloat64(ps.HTTPRequestsTotal.WithLabelValues("/api/v1/tasks/{id}", http.MethodGet)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(ps.HTTPRequestsTotal.WithLabelValues(unmatchedRoute, http.MethodGet)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(ps.HTTPErrorTotal.WithLabelValues(unmatchedRoute, http.MethodGet, "404")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(ps.HTTPInFlight))
+	assert.Equal(t, 2, testutil.CollectAndCount(ps.HTTPResponseSize, "test_http_response_size_bytes"))
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/ratelimit"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingAuthenticator struct {
+	calls int
+}
+
+func (ca *countingAuthenticator) Authenticate(ctx context.Context, token string) (*models.Caller, error) {
+	ca.calls++
+	if token == "" {
+		return nil, errs.ErrUnauthorized("test")
+	}
+	return &models.Caller{Type: models.CallerApiKey, Subject: token}, nil
+}
+
+// newRateLimitRouter wires the rate limits like NewAppServer: the ip limits
+// ahead of authentication and the others on the routes.
+func newRateLimitRouter(rules []config.RateLimit, auth Authenticator) http.Handler {
+	limiter := ratelimit.NewMemoryLimiter()
+	ps := testPrometheus()
+	r := chi.NewRouter()
+	r.Use(RateLimitMiddleware(limiter, rules, ps, rateLimitByIP))
+	r.Use(AuthMiddleware(auth))
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+	routes := r.With(RateLimitMiddleware(limiter, rules, ps, rateLimitByCaller, rateLimitByTenant))
+	routes.Get("/api/v1/tasks", func(w http.ResponseWriter, r *http.Request) {})
+	routes.Post("/api/v1/tasks", func(w http.ResponseWriter, r *http.Request) {})
+	return r
+}
+
+type rateLimitRequest struct {
+	method string
+	path   string
+	token  string
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ipRule := config.RateLimit{Key: rateLimitByIP, Requests: 1, Period: time.Hour, Burst: 2}
+	tests := []struct {
+		name              string
+		rules             []config.RateLimit
+		requests          []rateLimitRequest
+		expectedStatus    []int
+		expectedAuthCalls int
+		expectedRemaining string
+	}{
+		{
+			name:  "ip flood throttled before authentication",
+			rules: []config.RateLimit{ipRule},
+			requests: []rateLimitRequest{
+				{http.MethodGet, "/api/v1/tasks", "key"},
+				{http.MethodGet, "/api/v1/tasks", "key"},
+				{http.MethodGet, "/api/v1/tasks", "key"},
+			},
+			expectedStatus:    []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			expectedAuthCalls: 2,
+			expectedRemaining: "0",
+		},
+		{
+			name:  "unauthenticated flood throttled",
+			rules: []config.RateLimit{ipRule},
+			requests: []rateLimitRequest{
+				{http.MethodGet, "/api/v1/tasks", ""},
+				{http.MethodGet, "/api/v1/tasks", ""},
+				{http.MethodGet, "/api/v1/tasks", ""},
+			},
+			expectedStatus:    []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests},
+			expectedAuthCalls: 2,
+			expectedRemaining: "0",
+		},
+		{
+			name:  "ip rule of a route matched before routing",
+			rules: []config.RateLimit{{Method: http.MethodPost, Pattern: "/api/v1/tasks", Key: rateLimitByIP, Requests: 1, Period: time.Hour}},
+			requests: []rateLimitRequest{
+				{http.MethodPost, "/api/v1/tasks", "key"},
+				{http.MethodGet, "/api/v1/tasks", "key"},
+				{http.MethodPost, "/api/v1/tasks", "key"},
+			},
+			expectedStatus:    []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			expectedAuthCalls: 2,
+			expectedRemaining: "0",
+		},
+		{
+			name: "caller rule counts each caller",
+			rules: []config.RateLimit{
+				{Key: rateLimitByIP, Requests: 100, Period: time.Hour},
+				{Key: rateLimitByCaller, Requests: 1, Period: time.Hour},
+			},
+			requests: []rateLimitRequest{
+				{http.MethodGet, "/api/v1/tasks", "first"},
+				{http.MethodGet, "/api/v1/tasks", "second"},
+				{http.MethodGet, "/api/v1/tasks", "first"},
+			},
+			expectedStatus:    []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			expectedAuthCalls: 3,
+			expectedRemaining: "0",
+		},
+		{
+			name: "headers of the bucket closest to empty",
+			rules: []config.RateLimit{
+				{Key: rateLimitByIP, Requests: 10, Period: time.Hour},
+				{Key: rateLimitByCaller, Requests: 100, Period: time.Hour},
+			},
+			requests: []rateLimitRequest{
+				{http.MethodGet, "/api/v1/tasks", "key"},
+			},
+			expectedStatus:    []int{http.StatusOK},
+			expectedAuthCalls: 1,
+			expectedRemaining: "9",
+		},
+		{
+			name:  "public paths not limited",
+			rules: []config.RateLimit{{Key: rateLimitByIP, Requests: 1, Period: time.Hour}},
+			requests: []rateLimitRequest{
+				{http.MethodGet, "/health", ""},
+				{http.MethodGet, "/health", ""},
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &countingAuthenticator{}
+			router := newRateLimitRouter(tt.rules, auth)
+
+			var last *httptest.ResponseRecorder
+			for i, req := range tt.requests {
+				r := httptest.NewRequest(req.method, req.path, nil)
+				if req.token != "" {
+					r.Header.Set("Authorization", "Bearer "+req.token)
+				}
+				last = httptest.NewRecorder()
+				router.ServeHTTP(last, r)
+				assert.Equal(t, tt.expectedStatus[i], last.Code, "request %d", i)
+			}
+
+			assert.Equal(t, tt.expectedAuthCalls, auth.calls)
+			assert.Equal(t, tt.expectedRemaining, last.Header().Get("RateLimit-Remaining"))
+			if last.Code == http.StatusTooManyRequests {
+				assert.Equal(t, "3600", last.Header().Get("Retry-After"))
+				assert.NotEmpty(t, last.Header().Get("RateLimit-Limit"))
+				assert.NotEmpty(t, last.Header().Get("RateLimit-Reset"))
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		routes         []config.RouteRole
+		caller         *models.Caller
+		expectedStatus int
+	}{
+		{
+			name:           "read with tasks:read",
+			method:         http.MethodGet,
+			path:           "/api/v1/tasks",
+			caller:         &models.Caller{Roles: []string{models.ScopeTasksRead}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "write without tasks:write",
+			method:         http.MethodPost,
+			path:           "/api/v1/tasks",
+			caller:         &models.Caller{Roles: []string{models.ScopeTasksRead}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin route with admin",
+			method:         http.MethodPost,
+			path:           "/api/v1/admin/api-keys",
+			caller:         &models.Caller{Roles: []string{models.ScopeAdmin}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "admin route with a tenant-bound admin",
+			method:         http.MethodPost,
+			path:           "/api/v1/admin/api-keys",
+			caller:         &models.Caller{Roles: []string{models.ScopeAdmin}, Tenant: "team-a"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin route overridden for a tenant-bound caller",
+			method:         http.MethodPost,
+			path:           "/api/v1/admin/api-keys",
+			routes:         []config.RouteRole{{Method: http.MethodPost, Pattern: "/api/v1/admin/api-keys", Role: models.ScopeTasksWrite}},
+			caller:         &models.Caller{Roles: []string{models.ScopeTasksWrite}, Tenant: "team-a"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "tenant-bound admin on a task route",
+			method:         http.MethodPost,
+			path:           "/api/v1/tasks",
+			caller:         &models.Caller{Roles: []string{models.ScopeAdmin}, Tenant: "team-a"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "callback secret with tasks:read",
+			method:         http.MethodGet,
+			path:           "/api/v1/webhooks/callback-secret",
+			caller:         &models.Caller{Roles: []string{models.ScopeTasksRead}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "callback secret with tasks:write",
+			method:         http.MethodGet,
+			path:           "/api/v1/webhooks/callback-secret",
+			caller:         &models.Caller{Roles: []string{models.ScopeTasksWrite}},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			routes := r.With(RoleMiddleware(tt.routes))
+			routes.Get("/api/v1/tasks", func(w http.ResponseWriter, r *http.Request) {})
+			routes.Post("/api/v1/tasks", func(w http.ResponseWriter, r *http.Request) {})
+			routes.Post("/api/v1/admin/api-keys", func(w http.ResponseWriter, r *http.Request) {})
+			routes.Get("/api/v1/webhooks/callback-secret", func(w http.ResponseWriter, r *http.Request) {})
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req = req.WithContext(services.WithCaller(req.Context(), tt.caller))
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
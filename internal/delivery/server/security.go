@@ -0,0 +1,95 @@
+package server
+
+import (
+	"betera-tz/internal/config"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+)
+
+// Default CORS lists, covering every header the API reads or sets.
+var (
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"}
+	defaultCORSHeaders = []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key", "If-Match", "Last-Event-ID",
+		apiKeyHeader, tenantHeader}
+	corsExposedHeaders = []string{"Link", "Location", "ETag", "Content-Disposition", "Idempotent-Replayed", "X-Wait-Timed-Out",
+		middleware.RequestIDHeader, "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"}
+)
+
+// corsOptions fills the lists left empty in cfg with the defaults of env.
+// Origins may hold a wildcard such as "https://*.example.com".
+// Credentials are refused with a wildcard origin, as the CORS handler would
+// then echo back any origin along with the cookies of the user.
+func corsOptions(cfg config.CORSConfig, env string) cors.Options {
+	origins := cfg.AllowedOrigins
+	if len(origins) == 0 && env != config.EnvProd {
+		origins = []string{"*"}
+	}
+	if cfg.AllowCredentials && slices.Contains(origins, "*") {
+		panic(errors.New("cors allowCredentials needs explicit allowedOrigins"))
+	}
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	opts := cors.Options{
+		AllowedOrigins:   origins,
+		AllowedMethods:   methods,
+		AllowedHeaders:   headers,
+		ExposedHeaders:   corsExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           int(cfg.MaxAge.Seconds()),
+	}
+	if len(origins) == 0 {
+		// The CORS handler takes an empty list for every origin.
+		opts.AllowOriginFunc = func(*http.Request, string) bool { return false }
+	}
+	return opts
+}
+
+// SecurityHeadersMiddleware sets the security headers of cfg on every
+// response. HSTS is only sent over TLS, directly or behind a proxy that says
+// so in X-Forwarded-Proto, as browsers must ignore it on plain HTTP.
+func SecurityHeadersMiddleware(cfg config.SecurityConfig) func(http.Handler) http.Handler {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			if hsts != "" && (r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")) {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+			if cfg.ContentTypeNosniff {
+				h.Set("X-Content-Type-Options", "nosniff")
+			}
+			if cfg.FrameOptions != "" {
+				h.Set("X-Frame-Options", cfg.FrameOptions)
+			}
+			if cfg.ReferrerPolicy != "" {
+				h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+			}
+			csp := cfg.CSP
+			if strings.HasPrefix(r.URL.Path, "/swagger/") {
+				csp = cfg.SwaggerCSP
+			}
+			if csp != "" {
+				h.Set("Content-Security-Policy", csp)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"betera-tz/internal/config"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/cors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           config.CORSConfig
+		env           string
+		origin        string
+		expectedAllow string
+	}{
+		{
+			name:          "every origin by default outside prod",
+			env:           config.EnvDev,
+			origin:        "https://app.example.com",
+			expectedAllow: "*",
+		},
+		{
+			name:          "no origin by default in prod",
+			env:           config.EnvProd,
+			origin:        "https://app.example.com",
+			expectedAllow: "",
+		},
+		{
+			name:          "listed origin",
+			cfg:           config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}},
+			env:           config.EnvProd,
+			origin:        "https://app.example.com",
+			expectedAllow: "https://app.example.com",
+		},
+		{
+			name:          "wildcard origin",
+			cfg:           config.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}},
+			env:           config.EnvProd,
+			origin:        "https://app.example.com",
+			expectedAllow: "https://app.example.com",
+		},
+		{
+			name:          "unlisted origin",
+			cfg:           config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}},
+			env:           config.EnvLocal,
+			origin:        "https://evil.example.org",
+			expectedAllow: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := cors.Handler(corsOptions(tt.cfg, tt.env))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedAllow, rec.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
+
+func TestCORSOptions_Defaults(t *testing.T) {
+	opts := corsOptions(config.CORSConfig{MaxAge: 5 * time.Minute}, config.EnvDev)
+
+	assert.Equal(t, defaultCORSMethods, opts.AllowedMethods)
+	assert.Contains(t, opts.AllowedHeaders, "Idempotency-Key")
+	assert.Contains(t, opts.AllowedHeaders, tenantHeader)
+	assert.Contains(t, opts.ExposedHeaders, "ETag")
+	assert.Equal(t, 300, opts.MaxAge)
+}
+
+func TestCORSOptions_CredentialsWithWildcard(t *testing.T) {
+	assert.Panics(t, func() {
+		corsOptions(config.CORSConfig{AllowCredentials: true}, config.EnvDev)
+	})
+	assert.NotPanics(t, func() {
+		corsOptions(config.CORSConfig{AllowCredentials: true, AllowedOrigins: []string{"https://app.example.com"}}, config.EnvDev)
+	})
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	cfg := config.SecurityConfig{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+		CSP:                   "default-src 'none'",
+		SwaggerCSP:            "default-src 'self'",
+	}
+	tests := []struct {
+		name     string
+		cfg      config.SecurityConfig
+		path     string
+		tls      bool
+		proto    string
+		expected map[string]string
+	}{
+		{
+			name: "api over plain http",
+			cfg:  cfg,
+			path: "/api/v1/tasks",
+			expected: map[string]string{
+				"Strict-Transport-Security": "",
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Referrer-Policy":           "no-referrer",
+				"Content-Security-Policy":   "default-src 'none'",
+			},
+		},
+		{
+			name: "api over tls",
+			cfg:  cfg,
+			path: "/api/v1/tasks",
+			tls:  true,
+			expected: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+			},
+		},
+		{
+			name:  "tls terminated by a proxy",
+			cfg:   cfg,
+			path:  "/health",
+			proto: "https",
+			expected: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+			},
+		},
+		{
+			name: "swagger ui",
+			cfg:  cfg,
+			path: "/swagger/index.html",
+			expected: map[string]string{
+				"Content-Security-Policy": "default-src 'self'",
+			},
+		},
+		{
+			name: "headers left out",
+			cfg:  config.SecurityConfig{},
+			path: "/api/v1/tasks",
+			tls:  true,
+			expected: map[string]string{
+				"Strict-Transport-Security": "",
+				"X-Content-Type-Options":    "",
+				"X-Frame-Options":           "",
+				"Referrer-Policy":           "",
+				"Content-Security-Policy":   "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SecurityHeadersMiddleware(tt.cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			for header, value := range tt.expected {
+				assert.Equal(t, value, rec.Header().Get(header), header)
+			}
+		})
+	}
+}
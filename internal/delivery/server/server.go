@@ -2,24 +2,39 @@ package server
 
 import (
 	"betera-tz/internal/config"
+	"betera-tz/internal/delivery/apierr"
+	"betera-tz/internal/delivery/handlers/helper"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
 	"betera-tz/internal/dto"
+	"betera-tz/pkg/logger"
 	"betera-tz/pkg/monitoring"
+	"betera-tz/pkg/ratelimit"
+	"betera-tz/pkg/tlsreload"
+	"cmp"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "betera-tz/docs"
 
-	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httplog"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type AppServer struct {
@@ -27,7 +42,12 @@ type AppServer struct {
 	Metric *http.Server
 }
 
-func NewAppServer(scfg config.ServerConfig, acfg config.AppConfig, si ServerInterface, ps *monitoring.PrometheusSetup) *AppServer {
+// Authenticator resolves the caller a request token belongs to.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*models.Caller, error)
+}
+
+func NewAppServer(scfg config.ServerConfig, acfg config.AppConfig, authCfg config.AuthConfig, si ServerInterface, ps *monitoring.PrometheusSetup, auth Authenticator, limiter ratelimit.Limiter, hs services.HealthService) *AppServer {
 	r := chi.NewRouter()
 	metricsMux := chi.NewMux()
 	metricsMux.Handle("/metrics", promhttp.Handler())
@@ -44,14 +64,9 @@ func NewAppServer(scfg config.ServerConfig, acfg config.AppConfig, si ServerInte
 	}
 	writer := io.MultiWriter(logFile, os.Stdout)
 	logger = logger.Output(writer)
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: false,
-		MaxAge:           300,
-	}))
+	r.Use(TracingMiddleware)
+	r.Use(cors.Handler(corsOptions(scfg.CORS, acfg.Env)))
+	r.Use(SecurityHeadersMiddleware(scfg.Security))
 	r.Use(middleware.RedirectSlashes)
 	r.Use(func(h http.Handler) http.Handler {
 		return httplog.RequestLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -60,8 +75,12 @@ func NewAppServer(scfg config.ServerConfig, acfg config.AppConfig, si ServerInte
 			h.ServeHTTP(w, r)
 		}))
 	})
-	r.Use(middleware.Recoverer)
+	r.Use(RecoverMiddleware)
+	r.Use(RequestIDHeaderMiddleware)
 	r.Use(MetricsMiddleware(ps))
+	r.Use(RateLimitMiddleware(limiter, scfg.RateLimit.Rules, ps, rateLimitByIP))
+	r.Use(AuthMiddleware(auth))
+	r.Use(TenantMiddleware)
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -71,10 +90,27 @@ func NewAppServer(scfg config.ServerConfig, acfg config.AppConfig, si ServerInte
 			Message: "ok",
 		})
 	})
+	r.Get("/livez", HealthHandler(hs.Live))
+	r.Get("/readyz", HealthHandler(hs.Ready))
 
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		helper.WriteJSONError(w, r, apierr.NotFound())
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		helper.WriteJSONError(w, r, apierr.MethodNotAllowed())
+	})
+
+	// The last middleware runs first, so requests over the caller and tenant
+	// rate limits are rejected before anything else is done for them.
 	h := HandlerWithOptions(si, ChiServerOptions{
 		BaseURL:    "/api/v1",
 		BaseRouter: r,
+		Middlewares: []MiddlewareFunc{
+			TimeoutMiddleware(scfg.RequestTimeout, scfg.RouteTimeouts),
+			RoleMiddleware(authCfg.RouteRoles),
+			RateLimitMiddleware(limiter, scfg.RateLimit.Rules, ps, rateLimitByCaller, rateLimitByTenant),
+		},
+		ErrorHandlerFunc: ParamErrorHandler,
 	})
 
 	return &AppServer{
@@ -89,6 +125,32 @@ func NewAppServer(scfg config.ServerConfig, acfg config.AppConfig, si ServerInte
 	}
 }
 
+// MustConfigureTLS serves the app and metrics servers over TLS when their
+// certificates are configured.
+func (as *AppServer) MustConfigureTLS(scfg config.ServerConfig, l *logger.Logger) {
+	as.Server.TLSConfig = mustTLSConfig(scfg.TLS, l.AddOp("server.tls"))
+	as.Metric.TLSConfig = mustTLSConfig(scfg.MetricTLS, l.AddOp("server.metricTls"))
+}
+
+func mustTLSConfig(cfg config.TLSConfig, l *logger.Logger) *tls.Config {
+	if !cfg.Enabled() {
+		return nil
+	}
+	reloader, err := tlsreload.NewReloader(cfg, l)
+	if err != nil {
+		panic(fmt.Errorf("failed to load tls certificate: %w", err))
+	}
+	return reloader.TLSConfig()
+}
+
+// Serve accepts connections on l, over TLS when srv has a TLS config.
+func Serve(srv *http.Server, l net.Listener) error {
+	if srv.TLSConfig != nil {
+		return srv.ServeTLS(l, "", "")
+	}
+	return srv.Serve(l)
+}
+
 func (as *AppServer) MustClose(ctx context.Context) {
 	if err := as.Metric.Shutdown(ctx); err != nil {
 		panic(fmt.Errorf("failed to close metric server: %w", err))
@@ -98,24 +160,369 @@ func (as *AppServer) MustClose(ctx context.Context) {
 	}
 }
 
+// TracingMiddleware records a span for every request, continuing the trace
+// of the client if its request has one. The span is named after the matched
+// route, e.g. "GET /api/v1/tasks/{id}", once the request is routed.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.request", otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+		if pattern := chi.RouteContext(r.Context()).RoutePattern(); pattern != "" {
+			return r.Method + " " + pattern
+		}
+		return operation
+	}))
+}
+
+// HealthHandler writes the report of a probe, with status 503 unless the
+// report is ok.
+func HealthHandler(probe func(ctx context.Context) models.HealthReport) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := probe(r.Context())
+		status := http.StatusOK
+		if !report.Ok() {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// unmatchedRoute labels the metrics of requests that match no route, so
+// scans of random paths do not create new series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the metrics of requests by route pattern, e.g.
+// "/api/v1/tasks/{id}", rather than by path, which would make a series per
+// task.
 func MetricsMiddleware(ps *monitoring.PrometheusSetup) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ps.HTTPInFlight.Inc()
+			defer ps.HTTPInFlight.Dec()
 
 			next.ServeHTTP(ww, r)
 
 			duration := time.Since(start).Seconds()
-			path := r.URL.Path
+			path := routeLabel(r)
 			method := r.Method
 			status := fmt.Sprintf("%d", ww.Status())
 
 			ps.HTTPRequestsTotal.WithLabelValues(path, method).Inc()
 			ps.HTTPRequestDuration.WithLabelValues(path, method, status).Observe(duration)
+			ps.HTTPResponseSize.WithLabelValues(path, method, status).Observe(float64(ww.BytesWritten()))
 			if ww.Status() >= 400 {
 				ps.HTTPErrorTotal.WithLabelValues(path, method, status).Inc()
 			}
 		})
 	}
 }
+
+// routeLabel is the route pattern of a routed request.
+func routeLabel(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.RoutePattern() == "" {
+		return unmatchedRoute
+	}
+	return rctx.RoutePattern()
+}
+
+const apiKeyHeader = "X-API-Key"
+
+// publicPaths are served without an API key.
+var publicPaths = []string{"/health", "/livez", "/readyz", "/swagger/"}
+
+func isPublicPath(path string) bool {
+	for _, public := range publicPaths {
+		if path == public || (strings.HasSuffix(public, "/") && strings.HasPrefix(path, public)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthMiddleware rejects requests without a valid bearer token, which is a
+// JWT or an API key, or an API key in the X-API-Key header. It stores the
+// caller in the request context for RoleMiddleware and the services.
+func AuthMiddleware(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPublicPath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			caller, err := auth.Authenticate(r.Context(), requestToken(r))
+			if err != nil {
+				apiErr := apierr.ToApiError(err)
+				if apiErr.Status == http.StatusUnauthorized {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="betera-tz"`)
+				}
+				helper.WriteJSONError(w, r, apiErr)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(services.WithCaller(r.Context(), caller)))
+		})
+	}
+}
+
+const tenantHeader = "X-Tenant-ID"
+
+// TenantMiddleware scopes the request to the tenant of its caller, or to the
+// one chosen with the X-Tenant-ID header as services.ResolveTenant allows.
+// Requests to public paths have no caller and stay unscoped.
+func TenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller := services.CallerFromContext(r.Context())
+		if caller == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tenant, err := services.ResolveTenant(caller, r.Header.Get(tenantHeader), tenantHeader)
+		if err != nil {
+			helper.WriteJSONError(w, r, apierr.ToApiError(err))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(models.WithTenant(r.Context(), tenant)))
+	})
+}
+
+func requestToken(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get(apiKeyHeader)
+}
+
+// adminPrefix is the prefix of the routes managing every tenant.
+const adminPrefix = "/api/v1/admin/"
+
+// RoleMiddleware rejects requests whose caller lacks the role of the route.
+// Admin routes need admin, reads need tasks:read and everything else
+// tasks:write, as does reading the callback secret, unless the route is
+// listed in routes. Admin routes manage every tenant, so callers bound to a
+// tenant are rejected from them whatever their role.
+func RoleMiddleware(routes []config.RouteRole) MiddlewareFunc {
+	overrides := make(map[string]string, len(routes))
+	for _, route := range routes {
+		overrides[strings.ToUpper(route.Method)+" "+route.Pattern] = route.Role
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pattern := ""
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				pattern = rctx.RoutePattern()
+			}
+			role, ok := overrides[r.Method+" "+pattern]
+			if !ok {
+				role = requiredRole(r.Method, pattern)
+			}
+			caller := services.CallerFromContext(r.Context())
+			if caller == nil || !caller.HasRole(role) || (strings.HasPrefix(pattern, adminPrefix) && caller.Tenant != "") {
+				helper.WriteJSONError(w, r, apierr.Forbidden())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// callbackSecretPattern is the route of the secret signing task callbacks,
+// which only callers that may set callback urls read.
+const callbackSecretPattern = "/api/v1/webhooks/callback-secret"
+
+func requiredRole(method, pattern string) string {
+	switch {
+	case strings.HasPrefix(pattern, adminPrefix):
+		return models.ScopeAdmin
+	case pattern == callbackSecretPattern:
+		return models.ScopeTasksWrite
+	case method == http.MethodGet || method == http.MethodHead:
+		return models.ScopeTasksRead
+	default:
+		return models.ScopeTasksWrite
+	}
+}
+
+// TimeoutMiddleware bounds the context of each API request, so database
+// queries and waits are cancelled once the timeout elapses and the handler
+// reports a request_timeout problem. It runs after routing, which lets routes
+// override the timeout by their pattern; streaming routes disable it with zero.
+func TimeoutMiddleware(timeout time.Duration, routes []config.RouteTimeout) MiddlewareFunc {
+	overrides := make(map[string]time.Duration, len(routes))
+	for _, route := range routes {
+		overrides[strings.ToUpper(route.Method)+" "+route.Pattern] = route.Timeout
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t := timeout
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if override, ok := overrides[r.Method+" "+rctx.RoutePattern()]; ok {
+					t = override
+				}
+			}
+			if t <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), t)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Keys of rate limits.
+const (
+	rateLimitByCaller = ratelimit.KeyCaller
+	rateLimitByTenant = ratelimit.KeyTenant
+	rateLimitByIP     = ratelimit.KeyIP
+)
+
+// RateLimitMiddleware takes every request from the buckets of the rate limits
+// with one of keys its route matches and rejects it with a rate_limited
+// problem once one of them is empty. The RateLimit headers describe the
+// bucket closest to empty. A failing limiter lets requests through rather
+// than take the API down.
+//
+// The ip limits are meant to run ahead of authentication, so a flood is
+// rejected before any key lookup, and need the route matched in advance;
+// the caller and tenant limits run after it, as they need the caller.
+// Public paths are not limited, like they are not authenticated.
+func RateLimitMiddleware(limiter ratelimit.Limiter, rules []config.RateLimit, ps *monitoring.PrometheusSetup, keys ...string) func(http.Handler) http.Handler {
+	selected := ratelimit.NewRules(limiter, rules, keys...)
+	return func(next http.Handler) http.Handler {
+		if selected.Empty() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPublicPath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			pattern := routePattern(r)
+			decision := selected.Allow(r.Context(), r.Method, pattern, func(key string) string {
+				return rateLimitKey(r, key)
+			})
+			if decision.Err != nil {
+				httplog.LogEntrySetField(r.Context(), "rate_limit_error", decision.Err.Error())
+			}
+			if !decision.Allowed {
+				setRateLimitHeaders(w, *decision.Result)
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.Result.RetryAfter)))
+				ps.HTTPThrottledTotal.WithLabelValues(cmp.Or(pattern, unmatchedRoute), r.Method, decision.Key).Inc()
+				helper.WriteJSONError(w, r, apierr.TooManyRequests())
+				return
+			}
+			// the headers of an earlier middleware may describe a bucket
+			// closer to empty
+			remaining, err := strconv.Atoi(w.Header().Get("RateLimit-Remaining"))
+			if tightest := decision.Result; tightest != nil && (err != nil || tightest.Remaining < remaining) {
+				setRateLimitHeaders(w, *tightest)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routePattern returns the pattern of the route r matches, looking it up
+// when r is not routed yet.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" || rctx.Routes == nil {
+		return pattern
+	}
+	match := chi.NewRouteContext()
+	if !rctx.Routes.Match(match, r.Method, r.URL.Path) {
+		return ""
+	}
+	return match.RoutePattern()
+}
+
+// rateLimitKey returns the value of r a rate limit by key counts, empty when
+// the request has none.
+func rateLimitKey(r *http.Request, key string) string {
+	switch key {
+	case rateLimitByCaller:
+		if caller := services.CallerFromContext(r.Context()); caller != nil {
+			return caller.ID()
+		}
+		return ""
+	case rateLimitByTenant:
+		return models.TenantFromContext(r.Context())
+	default:
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+		return host
+	}
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, res ratelimit.Result) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// RecoverMiddleware reports a panicking handler as an internal_error problem
+// instead of an empty 500 response.
+func RecoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rvr := recover(); rvr != nil {
+				if rvr == http.ErrAbortHandler {
+					panic(rvr)
+				}
+				middleware.PrintPrettyStack(rvr)
+				helper.WriteJSONError(w, r, apierr.InternalServerError())
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequestIDHeaderMiddleware returns the request id to the client, so it can be
+// quoted when reporting a failed request.
+func RequestIDHeaderMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(middleware.RequestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ParamErrorHandler reports parameters the generated wrappers failed to bind
+// as validation problems naming the parameter.
+func ParamErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		invalidFormat *InvalidParamFormatError
+		required      *RequiredParamError
+		requiredHead  *RequiredHeaderError
+		tooMany       *TooManyValuesForParamError
+		unmarshaling  *UnmarshalingParamError
+	)
+	switch {
+	case errors.As(err, &invalidFormat):
+		helper.WriteJSONError(w, r, apierr.InvalidField(invalidFormat.ParamName, "has an invalid format"))
+	case errors.As(err, &required):
+		helper.WriteJSONError(w, r, apierr.InvalidField(required.ParamName, "is required"))
+	case errors.As(err, &requiredHead):
+		helper.WriteJSONError(w, r, apierr.InvalidField(requiredHead.ParamName, "is required"))
+	case errors.As(err, &tooMany):
+		helper.WriteJSONError(w, r, apierr.InvalidField(tooMany.ParamName, "must be given once"))
+	case errors.As(err, &unmarshaling):
+		helper.WriteJSONError(w, r, apierr.InvalidField(unmarshaling.ParamName, "has an invalid format"))
+	default:
+		helper.WriteJSONError(w, r, apierr.InvalidRequest())
+	}
+}
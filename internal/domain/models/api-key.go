@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes of API keys double as roles required by the API, so tokens of the
+// identity provider carry the same names.
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeAdmin      = "admin"
+)
+
+const ApiKeyNameMaxLength = 100
+
+func Scopes() []string {
+	return []string{ScopeTasksRead, ScopeTasksWrite, ScopeAdmin}
+}
+
+// ApiKey authenticates API clients. Only the SHA-256 hash of the key is
+// stored; Prefix is its first characters, kept to tell keys apart. A key
+// without a tenant is not bound to one.
+type ApiKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Tenant     string     `json:"tenant,omitempty"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RotatedAt  *time.Time `json:"rotatedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
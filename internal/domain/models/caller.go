@@ -0,0 +1,32 @@
+package models
+
+import "slices"
+
+const (
+	CallerApiKey = "api_key"
+	CallerUser   = "user"
+	CallerWorker = "worker"
+)
+
+// Caller is the authenticated client of a request: an API key, whose roles
+// are its scopes, or a user of the identity provider. Tenant is empty for
+// callers not bound to a tenant. Task workers are recorded as actors with
+// the worker type but never authenticate.
+type Caller struct {
+	Type    string
+	Subject string
+	Roles   []string
+	Tenant  string
+}
+
+// ID identifies the caller across both types, e.g. "user:1b2c" or
+// "api_key:7c9e6679-7425-40de-944b-e07fc1f90ae7".
+func (c *Caller) ID() string {
+	return c.Type + ":" + c.Subject
+}
+
+// HasRole reports whether the caller has role. The admin role grants every
+// other role.
+func (c *Caller) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role) || slices.Contains(c.Roles, ScopeAdmin)
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventCreated = "created"
+	// EventUpdated is an update changing the status of a task.
+	EventUpdated = "updated"
+	// EventAssigned is an update changing only the assignee of a task.
+	EventAssigned = "assigned"
+	// EventEdited is an update changing neither the status nor the assignee
+	// of a task, e.g. its title.
+	EventEdited  = "edited"
+	EventDeleted = "deleted"
+)
+
+type TaskEvent struct {
+	ID        int64     `json:"id"`
+	TaskID    uuid.UUID `json:"taskId"`
+	TenantID  string    `json:"tenantId"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EventFilter selects the events of a subscriber. Tenant is required: events
+// of other tenants never match, and an empty one matches nothing.
+type EventFilter struct {
+	Tenant string
+	TaskID *uuid.UUID
+	Status string
+}
+
+func (ef EventFilter) Matches(event TaskEvent) bool {
+	if ef.Tenant != event.TenantID {
+		return false
+	}
+	if ef.TaskID != nil && *ef.TaskID != event.TaskID {
+		return false
+	}
+	if ef.Status != "" && ef.Status != event.Status {
+		return false
+	}
+	return true
+}
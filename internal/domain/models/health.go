@@ -0,0 +1,27 @@
+package models
+
+// Statuses of a health report and of its checks.
+const (
+	HealthOk       = "ok"
+	HealthFailed   = "failed"
+	HealthDraining = "draining"
+)
+
+// HealthReport is the result of a liveness or readiness probe with the
+// result of each checked component by name.
+type HealthReport struct {
+	Status string                  `json:"status"`
+	Checks map[string]HealthResult `json:"checks"`
+}
+
+// HealthResult is the result of checking a component. Duration is the time
+// the check took in milliseconds.
+type HealthResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"durationMs"`
+}
+
+func (r HealthReport) Ok() bool {
+	return r.Status == HealthOk
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// IdempotencyKeyMaxLength is the length of the key column.
+const IdempotencyKeyMaxLength = 255
+
+type IdempotencyRecord struct {
+	Key         string    `json:"key"`
+	Fingerprint string    `json:"fingerprint"`
+	StatusCode  int       `json:"statusCode"`
+	Response    []byte    `json:"response"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// Completed reports whether the original request has finished and its
+// response can be replayed.
+func (ir IdempotencyRecord) Completed() bool {
+	return ir.StatusCode != 0
+}
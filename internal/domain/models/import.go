@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ImportPending    = "pending"
+	ImportProcessing = "processing"
+	ImportDone       = "done"
+	ImportFailed     = "failed"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+func ImportFormats() []string {
+	return []string{ImportFormatCSV, ImportFormatNDJSON}
+}
+
+// Import is a job loading tasks from an uploaded file. ProcessedRows counts
+// every data row read so far, ImportedRows and FailedRows split it by outcome.
+type Import struct {
+	ID            uuid.UUID  `json:"id"`
+	Format        string     `json:"format"`
+	Status        string     `json:"status"`
+	Enqueue       bool       `json:"enqueue"`
+	ProcessedRows int        `json:"processedRows"`
+	ImportedRows  int        `json:"importedRows"`
+	FailedRows    int        `json:"failedRows"`
+	Error         string     `json:"error,omitempty"`
+	CreatedBy     string     `json:"createdBy,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+// ImportError explains why a row of an import was rejected. Row is the line
+// number in the uploaded file.
+type ImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
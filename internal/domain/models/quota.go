@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Names of quotas as reported to clients.
+const (
+	QuotaMaxActive     = "maxActive"
+	QuotaMaxProcessing = "maxProcessing"
+	QuotaMaxDaily      = "maxDaily"
+)
+
+// Quota limits the tasks of a tenant: MaxActive those created or processing
+// at once, MaxProcessing those processing at once and MaxDaily those created
+// per UTC day. Zero limits are unlimited. UpdatedAt is nil for the default
+// quota of tenants without one of their own.
+type Quota struct {
+	Tenant        string     `json:"tenant"`
+	MaxActive     int        `json:"maxActive"`
+	MaxProcessing int        `json:"maxProcessing"`
+	MaxDaily      int        `json:"maxDaily"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+}
+
+// QuotaUsage is what a tenant uses of its quota.
+type QuotaUsage struct {
+	Active       int `json:"active"`
+	Processing   int `json:"processing"`
+	CreatedToday int `json:"createdToday"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// TaskStats aggregates tasks over the window [From, To). Counts and Total
+// describe all tasks regardless of the window.
+type TaskStats struct {
+	From       time.Time
+	To         time.Time
+	Bucket     time.Duration
+	Counts     map[string]int
+	Total      int
+	Buckets    []StatsBucket
+	Processing DurationStats
+}
+
+// StatsBucket counts the tasks created and finished within a bucket starting
+// at Start.
+type StatsBucket struct {
+	Start    time.Time
+	Created  int
+	Finished int
+}
+
+// DurationStats describes how long tasks finished within the window took from
+// the start of processing to done.
+type DurationStats struct {
+	Count int
+	Avg   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
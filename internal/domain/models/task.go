@@ -2,9 +2,100 @@ package models
 
 import "github.com/google/uuid"
 
+const (
+	StatusCreated    = "created"
+	StatusProcessing = "processing"
+	StatusDone       = "done"
+)
+
+// Field limits, kept in line with the tasks table and openapi.yaml.
+const (
+	TitleMaxLength       = 150
+	DescriptionMaxLength = 650
+	URLMaxLength         = 2048
+	ActorMaxLength       = 255
+)
+
+// Actor filter values resolved from the request: ActorMe is the caller and
+// ActorNone matches tasks without the actor, e.g. unassigned ones.
+const (
+	ActorMe   = "me"
+	ActorNone = "none"
+)
+
+var transitions = map[string][]string{
+	StatusCreated:    {StatusProcessing, StatusDone},
+	StatusProcessing: {StatusDone, StatusCreated},
+	StatusDone:       {StatusCreated},
+}
+
 type Task struct {
 	ID          uuid.UUID `json:"id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Status      string    `json:"status"`
+	Version     int       `json:"version"`
+	CallbackURL string    `json:"callbackUrl,omitempty"`
+	CreatedBy   string    `json:"createdBy,omitempty"`
+	Assignee    string    `json:"assignee,omitempty"`
+	ProcessedBy string    `json:"processedBy,omitempty"`
+}
+
+// TaskFilter selects tasks. CreatedBy and Assignee hold caller ids or
+// ActorNone once resolved by the task service.
+type TaskFilter struct {
+	Status    string
+	CreatedBy string
+	Assignee  string
+}
+
+// Empty reports whether the filter matches every task.
+func (tf TaskFilter) Empty() bool {
+	return tf == TaskFilter{}
+}
+
+// BulkSelection selects the tasks of a bulk operation: the tasks with IDs,
+// if any, matching Filter, if any. All has to be set to select every task
+// of the tenant, so a request missing both never touches all of them.
+type BulkSelection struct {
+	IDs    []uuid.UUID
+	Filter *TaskFilter
+	All    bool
+}
+
+type BulkResult struct {
+	Matched  int  `json:"matched"`
+	Affected int  `json:"affected"`
+	Skipped  int  `json:"skipped"`
+	DryRun   bool `json:"dryRun"`
+}
+
+// Statuses lists every task status.
+func Statuses() []string {
+	return []string{StatusCreated, StatusProcessing, StatusDone}
+}
+
+func IsValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// SourceStatuses returns every status a task may be moved to the given one from.
+func SourceStatuses(to string) []string {
+	from := []string{}
+	for s := range transitions {
+		if CanTransition(s, to) {
+			from = append(from, s)
+		}
+	}
+	return from
 }
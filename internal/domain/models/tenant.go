@@ -0,0 +1,32 @@
+package models
+
+import (
+	"context"
+	"regexp"
+)
+
+// DefaultTenant owns the data created before tenants were introduced and is
+// used by callers that are not bound to a tenant.
+const DefaultTenant = "default"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// IsValidTenant reports whether tenant is a lowercase id of at most 63
+// characters such as "team-a".
+func IsValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+type tenantCtxKey struct{}
+
+// WithTenant returns a context whose repository calls are scoped to tenant.
+// It lives here rather than next to the caller, as repositories need it.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant of ctx or "" when it has none.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantCtxKey{}).(string)
+	return tenant
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a global subscription notified about status changes of every
+// task. An empty Statuses list subscribes to all statuses.
+type Webhook struct {
+	ID           uuid.UUID  `json:"id"`
+	URL          string     `json:"url"`
+	Secret       string     `json:"-"`
+	Statuses     []string   `json:"statuses"`
+	Active       bool       `json:"active"`
+	FailureCount int        `json:"failureCount"`
+	DisabledAt   *time.Time `json:"disabledAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// WebhookDelivery is a single notification waiting to be sent or already
+// sent. WebhookID is nil for deliveries to a task's own callback URL.
+type WebhookDelivery struct {
+	ID             int64            `json:"id"`
+	WebhookID      *uuid.UUID       `json:"webhookId,omitempty"`
+	TaskID         uuid.UUID        `json:"taskId"`
+	Tenant         string           `json:"-"`
+	URL            string           `json:"url"`
+	Secret         string           `json:"-"`
+	Payload        []byte           `json:"-"`
+	Status         string           `json:"status"`
+	Attempts       int              `json:"attempts"`
+	LastStatusCode int              `json:"lastStatusCode,omitempty"`
+	LastError      string           `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time        `json:"nextAttemptAt"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	AttemptLog     []WebhookAttempt `json:"attemptLog"`
+}
+
+type WebhookAttempt struct {
+	DeliveryID int64     `json:"-"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
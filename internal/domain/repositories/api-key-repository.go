@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/storage"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApiKeyRepository interface {
+	Create(ctx context.Context, key *models.ApiKey) error
+	Get(ctx context.Context) ([]models.ApiKey, error)
+	GetByHash(ctx context.Context, hash string) (*models.ApiKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	Rotate(ctx context.Context, id uuid.UUID, hash, prefix string) (*models.ApiKey, error)
+	Touch(ctx context.Context, id uuid.UUID, interval time.Duration) error
+}
+
+type apiKeyRepository struct {
+	Storage *storage.Storage
+}
+
+func NewApiKeyRepository(s *storage.Storage) ApiKeyRepository {
+	return &apiKeyRepository{
+		Storage: s,
+	}
+}
+
+const (
+	apiKeyPlace   = "apiKeyRepository."
+	apiKeyColumns = "id, name, prefix, COALESCE(tenant_id, ''), key_hash, scopes, created_at, rotated_at, last_used_at, revoked_at"
+)
+
+func scanApiKey(row rowScanner, key *models.ApiKey) error {
+	return row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Tenant, &key.Hash, &key.Scopes, &key.CreatedAt, &key.RotatedAt,
+		&key.LastUsedAt, &key.RevokedAt)
+}
+
+func (ar *apiKeyRepository) Create(ctx context.Context, key *models.ApiKey) error {
+	op := apiKeyPlace + "Create"
+	query := "INSERT INTO api_keys (id, name, prefix, tenant_id, key_hash, scopes) VALUES ($1,$2,$3,NULLIF($4, ''),$5,$6) RETURNING " + apiKeyColumns
+	if err := scanApiKey(ar.Storage.Pool.QueryRow(ctx, query, key.ID, key.Name, key.Prefix, key.Tenant, key.Hash, key.Scopes), key); err != nil {
+		if storage.ErrorAlreadyExists(err) {
+			return errs.ErrAlreadyExists(op, err)
+		}
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
+
+func (ar *apiKeyRepository) Get(ctx context.Context) ([]models.ApiKey, error) {
+	op := apiKeyPlace + "Get"
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY created_at"
+	rows, err := ar.Storage.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	defer rows.Close()
+	keys := []models.ApiKey{}
+	for rows.Next() {
+		key := models.ApiKey{}
+		if err := scanApiKey(rows, &key); err != nil {
+			return nil, errs.NewAppError(op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	return keys, nil
+}
+
+// GetByHash returns the key with the hash, including a revoked one, so the
+// caller can tell a revoked key from an unknown one.
+func (ar *apiKeyRepository) GetByHash(ctx context.Context, hash string) (*models.ApiKey, error) {
+	op := apiKeyPlace + "GetByHash"
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE key_hash = $1"
+	key := models.ApiKey{}
+	if err := scanApiKey(ar.Storage.Pool.QueryRow(ctx, query, hash), &key); err != nil {
+		if errors.Is(err, storage.ErrNotFound()) {
+			return nil, errs.ErrNotFound(op)
+		}
+		return nil, errs.NewAppError(op, err)
+	}
+	return &key, nil
+}
+
+// Revoke disables the key for good. Revoking a revoked key keeps the original
+// revocation time.
+func (ar *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	op := apiKeyPlace + "Revoke"
+	query := "UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1"
+	res, err := ar.Storage.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return errs.ErrNotFound(op)
+	}
+	return nil
+}
+
+// Rotate replaces the hash of an active key, so the previous key stops
+// working while the id, name and scopes stay.
+func (ar *apiKeyRepository) Rotate(ctx context.Context, id uuid.UUID, hash, prefix string) (*models.ApiKey, error) {
+	op := apiKeyPlace + "Rotate"
+	query := "UPDATE api_keys SET key_hash = $2, prefix = $3, rotated_at = now() WHERE id = $1 AND revoked_at IS NULL RETURNING " + apiKeyColumns
+	key := models.ApiKey{}
+	if err := scanApiKey(ar.Storage.Pool.QueryRow(ctx, query, id, hash, prefix), &key); err != nil {
+		if errors.Is(err, storage.ErrNotFound()) {
+			return nil, errs.ErrNotFound(op)
+		}
+		return nil, errs.NewAppError(op, err)
+	}
+	return &key, nil
+}
+
+// Touch records the use of a key. It writes at most once per interval, so
+// busy clients do not turn every request into an update.
+func (ar *apiKeyRepository) Touch(ctx context.Context, id uuid.UUID, interval time.Duration) error {
+	op := apiKeyPlace + "Touch"
+	query := `UPDATE api_keys SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - $2::interval)`
+	if _, err := ar.Storage.Pool.Exec(ctx, query, id, interval); err != nil {
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
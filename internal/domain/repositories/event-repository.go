@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/storage"
+	"context"
+	"fmt"
+	"time"
+)
+
+type EventRepository interface {
+	GetAfter(ctx context.Context, lastId int64, filter models.EventFilter, limit int) ([]models.TaskEvent, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+type eventRepository struct {
+	Storage *storage.Storage
+}
+
+func NewEventRepository(s *storage.Storage) EventRepository {
+	return &eventRepository{
+		Storage: s,
+	}
+}
+
+const eventPlace = "eventRepository."
+
+func (er *eventRepository) GetAfter(ctx context.Context, lastId int64, filter models.EventFilter, limit int) ([]models.TaskEvent, error) {
+	op := eventPlace + "GetAfter"
+	args := []any{lastId, filter.Tenant}
+	query := "SELECT id, task_id, tenant_id, type, COALESCE(status, ''), COALESCE(version, 0), created_at FROM task_events WHERE id > $1 AND tenant_id = $2"
+	if filter.TaskID != nil {
+		args = append(args, *filter.TaskID)
+		query += fmt.Sprintf(" AND task_id = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY id LIMIT $%d", len(args))
+	rows, err := er.Storage.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	defer rows.Close()
+	events := []models.TaskEvent{}
+	for rows.Next() {
+		event := models.TaskEvent{}
+		if err := rows.Scan(&event.ID, &event.TaskID, &event.TenantID, &event.Type, &event.Status, &event.Version, &event.CreatedAt); err != nil {
+			return nil, errs.NewAppError(op, err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	return events, nil
+}
+
+func (er *eventRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	op := eventPlace + "DeleteBefore"
+	query := "DELETE FROM task_events WHERE created_at < $1"
+	res, err := er.Storage.Pool.Exec(ctx, query, before)
+	if err != nil {
+		return 0, errs.NewAppError(op, err)
+	}
+	return int(res.RowsAffected()), nil
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/storage"
+	"context"
+	"errors"
+	"time"
+)
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, caller, key, fingerprint string, lockedUntil, expiresAt time.Time) (bool, error)
+	GetByKey(ctx context.Context, caller, key string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, caller, key string, statusCode int, response []byte) error
+	Delete(ctx context.Context, caller, key string) error
+}
+
+type idempotencyRepository struct {
+	Storage *storage.Storage
+}
+
+func NewIdempotencyRepository(s *storage.Storage) IdempotencyRepository {
+	return &idempotencyRepository{
+		Storage: s,
+	}
+}
+
+const idempotencyPlace = "idempotencyRepository."
+
+// Reserve claims the key for a new request of caller. Keys are scoped to the
+// tenant and the caller, so no one replays the responses of another caller.
+// An expired record is taken over, as is the record of the same request left
+// in progress past lockedUntil, e.g. by a crashed replica. A live one is left
+// untouched and false is returned.
+func (ir *idempotencyRepository) Reserve(ctx context.Context, caller, key, fingerprint string, lockedUntil, expiresAt time.Time) (bool, error) {
+	op := idempotencyPlace + "Reserve"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return false, err
+	}
+	query := `INSERT INTO idempotency_keys (tenant_id, caller_id, key, fingerprint, locked_until, expires_at) VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT (tenant_id, caller_id, key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, response = NULL,
+		created_at = now(), locked_until = EXCLUDED.locked_until, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < now() OR (idempotency_keys.status_code IS NULL
+			AND idempotency_keys.locked_until < now() AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)`
+	res, err := ir.Storage.Pool.Exec(ctx, query, tenant, caller, key, fingerprint, lockedUntil, expiresAt)
+	if err != nil {
+		return false, errs.NewAppError(op, err)
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (ir *idempotencyRepository) GetByKey(ctx context.Context, caller, key string) (*models.IdempotencyRecord, error) {
+	op := idempotencyPlace + "GetByKey"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT key, fingerprint, COALESCE(status_code, 0), response, expires_at FROM idempotency_keys
+		WHERE tenant_id = $1 AND caller_id = $2 AND key = $3`
+	record := models.IdempotencyRecord{}
+	if err := ir.Storage.Pool.QueryRow(ctx, query, tenant, caller, key).Scan(&record.Key, &record.Fingerprint, &record.StatusCode, &record.Response, &record.ExpiresAt); err != nil {
+		if errors.Is(err, storage.ErrNotFound()) {
+			return nil, errs.ErrNotFound(op)
+		}
+		return nil, errs.NewAppError(op, err)
+	}
+	return &record, nil
+}
+
+// Complete stores the response of the request holding the key. A request
+// whose record was taken over after its lease may finish first; the record is
+// then completed once and the later one is not found.
+func (ir *idempotencyRepository) Complete(ctx context.Context, caller, key string, statusCode int, response []byte) error {
+	op := idempotencyPlace + "Complete"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE idempotency_keys SET status_code = $1, response = $2
+		WHERE tenant_id = $3 AND caller_id = $4 AND key = $5 AND status_code IS NULL`
+	res, err := ir.Storage.Pool.Exec(ctx, query, statusCode, response, tenant, caller, key)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return errs.ErrNotFound(op)
+	}
+	return nil
+}
+
+func (ir *idempotencyRepository) Delete(ctx context.Context, caller, key string) error {
+	op := idempotencyPlace + "Delete"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return err
+	}
+	query := "DELETE FROM idempotency_keys WHERE tenant_id = $1 AND caller_id = $2 AND key = $3 AND status_code IS NULL"
+	if _, err := ir.Storage.Pool.Exec(ctx, query, tenant, caller, key); err != nil {
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
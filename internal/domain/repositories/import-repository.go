@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/storage"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ImportRepository interface {
+	Create(ctx context.Context, imp *models.Import) error
+	GetById(ctx context.Context, id uuid.UUID) (*models.Import, error)
+	Claim(ctx context.Context, id uuid.UUID) (bool, error)
+	SaveProgress(ctx context.Context, imp *models.Import, importErrors []models.ImportError) error
+	Finish(ctx context.Context, id uuid.UUID, status, message string) error
+	FailStale(ctx context.Context, staleAfter time.Duration, message string) (int, error)
+	GetErrors(ctx context.Context, id uuid.UUID, fn func(*models.ImportError) error) error
+}
+
+type importRepository struct {
+	Storage *storage.Storage
+}
+
+func NewImportRepository(s *storage.Storage) ImportRepository {
+	return &importRepository{
+		Storage: s,
+	}
+}
+
+const (
+	importPlace   = "importRepository."
+	importColumns = "id, format, status, enqueue, processed_rows, imported_rows, failed_rows, COALESCE(error, ''), COALESCE(created_by, ''), created_at, updated_at, finished_at"
+)
+
+func scanImport(row rowScanner, imp *models.Import) error {
+	return row.Scan(&imp.ID, &imp.Format, &imp.Status, &imp.Enqueue, &imp.ProcessedRows, &imp.ImportedRows,
+		&imp.FailedRows, &imp.Error, &imp.CreatedBy, &imp.CreatedAt, &imp.UpdatedAt, &imp.FinishedAt)
+}
+
+func (ir *importRepository) Create(ctx context.Context, imp *models.Import) error {
+	op := importPlace + "Create"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO imports (id, tenant_id, format, status, enqueue, created_by) VALUES ($1,$2,$3,$4,$5,NULLIF($6, '')) RETURNING " + importColumns
+	if err := scanImport(ir.Storage.Pool.QueryRow(ctx, query, imp.ID, tenant, imp.Format, imp.Status, imp.Enqueue, imp.CreatedBy), imp); err != nil {
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
+
+func (ir *importRepository) GetById(ctx context.Context, id uuid.UUID) (*models.Import, error) {
+	op := importPlace + "GetById"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + importColumns + " FROM imports WHERE id = $1 AND tenant_id = $2"
+	imp := models.Import{}
+	if err := scanImport(ir.Storage.Pool.QueryRow(ctx, query, id, tenant), &imp); err != nil {
+		if errors.Is(err, storage.ErrNotFound()) {
+			return nil, errs.ErrNotFound(op)
+		}
+		return nil, errs.NewAppError(op, err)
+	}
+	return &imp, nil
+}
+
+// Claim moves a pending import to processing. It reports false when the import
+// was already claimed or given up on as stale.
+func (ir *importRepository) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	op := importPlace + "Claim"
+	query := "UPDATE imports SET status = 'processing', updated_at = now() WHERE id = $1 AND status = 'pending'"
+	res, err := ir.Storage.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, errs.NewAppError(op, err)
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+// SaveProgress stores the row counters of an import together with the errors
+// of the rows processed since the previous call.
+func (ir *importRepository) SaveProgress(ctx context.Context, imp *models.Import, importErrors []models.ImportError) error {
+	op := importPlace + "SaveProgress"
+	tx, err := ir.Storage.Pool.Begin(ctx)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE imports SET processed_rows = $2, imported_rows = $3, failed_rows = $4, updated_at = now()
+		WHERE id = $1 RETURNING updated_at`
+	if err := tx.QueryRow(ctx, query, imp.ID, imp.ProcessedRows, imp.ImportedRows, imp.FailedRows).Scan(&imp.UpdatedAt); err != nil {
+		if errors.Is(err, storage.ErrNotFound()) {
+			return errs.ErrNotFound(op)
+		}
+		return errs.NewAppError(op, err)
+	}
+	if len(importErrors) > 0 {
+		rows := make([][]any, 0, len(importErrors))
+		for _, ie := range importErrors {
+			rows = append(rows, []any{imp.ID, ie.Row, ie.Field, ie.Message})
+		}
+		columns := []string{"import_id", "row", "field", "message"}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_errors"}, columns, pgx.CopyFromRows(rows)); err != nil {
+			return errs.NewAppError(op, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
+
+func (ir *importRepository) Finish(ctx context.Context, id uuid.UUID, status, message string) error {
+	op := importPlace + "Finish"
+	query := "UPDATE imports SET status = $2, error = NULLIF($3, ''), updated_at = now(), finished_at = now() WHERE id = $1"
+	res, err := ir.Storage.Pool.Exec(ctx, query, id, status, message)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return errs.ErrNotFound(op)
+	}
+	return nil
+}
+
+// FailStale fails unfinished imports that made no progress for staleAfter,
+// which happens when the instance processing them stops.
+func (ir *importRepository) FailStale(ctx context.Context, staleAfter time.Duration, message string) (int, error) {
+	op := importPlace + "FailStale"
+	query := `UPDATE imports SET status = 'failed', error = $2, updated_at = now(), finished_at = now()
+		WHERE status IN ('pending', 'processing') AND updated_at < now() - $1::interval`
+	res, err := ir.Storage.Pool.Exec(ctx, query, staleAfter, message)
+	if err != nil {
+		return 0, errs.NewAppError(op, err)
+	}
+	return int(res.RowsAffected()), nil
+}
+
+// GetErrors calls fn for every rejected row of an import in file order. It
+// is not scoped to a tenant; the import must be looked up with GetById first.
+func (ir *importRepository) GetErrors(ctx context.Context, id uuid.UUID, fn func(*models.ImportError) error) error {
+	op := importPlace + "GetErrors"
+	query := "SELECT row, field, message FROM import_errors WHERE import_id = $1 ORDER BY row, id"
+	rows, err := ir.Storage.Pool.Query(ctx, query, id)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	defer rows.Close()
+	ie := models.ImportError{}
+	for rows.Next() {
+		if err := rows.Scan(&ie.Row, &ie.Field, &ie.Message); err != nil {
+			return errs.NewAppError(op, err)
+		}
+		if err := fn(&ie); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/storage"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QuotaRepository stores the quotas set for tenants. Its methods name the
+// tenant themselves, as quotas are managed by admins for any tenant.
+type QuotaRepository interface {
+	Get(ctx context.Context, tenant string) (*models.Quota, error)
+	List(ctx context.Context) ([]models.Quota, error)
+	Set(ctx context.Context, quota *models.Quota) error
+	Delete(ctx context.Context, tenant string) error
+	Usage(ctx context.Context, tenant string) (*models.QuotaUsage, error)
+}
+
+type quotaRepository struct {
+	Storage *storage.Storage
+}
+
+func NewQuotaRepository(s *storage.Storage) QuotaRepository {
+	return &quotaRepository{
+		Storage: s,
+	}
+}
+
+const (
+	quotaPlace   = "quotaRepository."
+	quotaColumns = "tenant_id, max_active, max_processing, max_daily, updated_at"
+	// usageQuery counts the tasks of tenant $1 its quota limits.
+	usageQuery = `SELECT
+		(SELECT count(*) FROM tasks WHERE tenant_id = $1 AND status IN ('created', 'processing')),
+		(SELECT count(*) FROM tasks WHERE tenant_id = $1 AND status = 'processing'),
+		COALESCE((SELECT created FROM tenant_usage WHERE tenant_id = $1 AND day = (now() AT TIME ZONE 'UTC')::date), 0)`
+	// quotaLockQuery serializes the statements checking and changing the
+	// usage of tenant $1 until the end of the transaction.
+	quotaLockQuery = "SELECT pg_advisory_xact_lock(hashtextextended('tenant_quota:' || $1, 0))"
+)
+
+func scanQuota(row rowScanner, quota *models.Quota) error {
+	return row.Scan(&quota.Tenant, &quota.MaxActive, &quota.MaxProcessing, &quota.MaxDaily, &quota.UpdatedAt)
+}
+
+func scanUsage(row rowScanner, usage *models.QuotaUsage) error {
+	return row.Scan(&usage.Active, &usage.Processing, &usage.CreatedToday)
+}
+
+func (qr *quotaRepository) Get(ctx context.Context, tenant string) (*models.Quota, error) {
+	op := quotaPlace + "Get"
+	quota := models.Quota{}
+	query := "SELECT " + quotaColumns + " FROM tenant_quotas WHERE tenant_id = $1"
+	if err := scanQuota(qr.Storage.Pool.QueryRow(ctx, query, tenant), &quota); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNotFound(op)
+		}
+		return nil, errs.NewAppError(op, err)
+	}
+	return &quota, nil
+}
+
+func (qr *quotaRepository) List(ctx context.Context) ([]models.Quota, error) {
+	op := quotaPlace + "List"
+	rows, err := qr.Storage.Pool.Query(ctx, "SELECT "+quotaColumns+" FROM tenant_quotas ORDER BY tenant_id")
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	defer rows.Close()
+	quotas := []models.Quota{}
+	for rows.Next() {
+		quota := models.Quota{}
+		if err := scanQuota(rows, &quota); err != nil {
+			return nil, errs.NewAppError(op, err)
+		}
+		quotas = append(quotas, quota)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	return quotas, nil
+}
+
+// Set creates or replaces the quota of quota.Tenant and fills in UpdatedAt.
+func (qr *quotaRepository) Set(ctx context.Context, quota *models.Quota) error {
+	op := quotaPlace + "Set"
+	query := `INSERT INTO tenant_quotas (tenant_id, max_active, max_processing, max_daily) VALUES ($1,$2,$3,$4)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET max_active = EXCLUDED.max_active, max_processing = EXCLUDED.max_processing,
+			max_daily = EXCLUDED.max_daily, updated_at = now()
+		RETURNING ` + quotaColumns
+	row := qr.Storage.Pool.QueryRow(ctx, query, quota.Tenant, quota.MaxActive, quota.MaxProcessing, quota.MaxDaily)
+	if err := scanQuota(row, quota); err != nil {
+		if storage.CheckErr(err) {
+			return errs.ErrInvalidValues(op, err)
+		}
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
+
+func (qr *quotaRepository) Delete(ctx context.Context, tenant string) error {
+	op := quotaPlace + "Delete"
+	res, err := qr.Storage.Pool.Exec(ctx, "DELETE FROM tenant_quotas WHERE tenant_id = $1", tenant)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return errs.ErrNotFound(op)
+	}
+	return nil
+}
+
+func (qr *quotaRepository) Usage(ctx context.Context, tenant string) (*models.QuotaUsage, error) {
+	op := quotaPlace + "Usage"
+	usage := models.QuotaUsage{}
+	err := qr.Storage.InTenant(ctx, tenant, func(q storage.Querier) error {
+		return scanUsage(q.QueryRow(ctx, usageQuery, tenant), &usage)
+	})
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	return &usage, nil
+}
@@ -7,13 +7,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type TaskRepository interface {
-	Create(ctx context.Context, task *models.Task) (*string, error)
+	Create(ctx context.Context, task *models.Task, quota models.Quota) (*string, error)
 	GetById(ctx context.Context, id string) (*models.Task, error)
-	Get(ctx context.Context, amount, page int, statusFilter string) ([]models.Task, error)
+	Get(ctx context.Context, amount, page int, filter models.TaskFilter) ([]models.Task, error)
 	UpdateStatus(ctx context.Context, id, status string) error
+	UpdateStatusIfVersion(ctx context.Context, id, status string, version int) error
+	Assign(ctx context.Context, id, assignee string, version *int) (*models.Task, error)
+	GetPageByFilter(ctx context.Context, ids []uuid.UUID, filter models.TaskFilter, after uuid.UUID, limit int) ([]models.Task, error)
+	UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status string, from []string) ([]uuid.UUID, error)
+	DeleteBatch(ctx context.Context, ids []uuid.UUID) (int, error)
+	Export(ctx context.Context, filter models.TaskFilter, fn func(*models.Task) error) error
+	CreateBatch(ctx context.Context, tasks []models.Task, quota models.Quota) ([]uuid.UUID, int, error)
+	StartProcessing(ctx context.Context, id, actor string, maxProcessing int) (bool, error)
+	Stats(ctx context.Context, from, to time.Time, bucket time.Duration) (*models.TaskStats, error)
 }
 
 type taskRepository struct {
@@ -26,93 +39,566 @@ func NewTaskRepository(s *storage.Storage) TaskRepository {
 	}
 }
 
-const place = "taskRepository."
+const (
+	place       = "taskRepository."
+	taskColumns = "id, title, description, status, version, COALESCE(callback_url, ''), COALESCE(created_by, ''), COALESCE(assignee, ''), COALESCE(processed_by, '')"
+)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row rowScanner, task *models.Task) error {
+	return row.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Version, &task.CallbackURL,
+		&task.CreatedBy, &task.Assignee, &task.ProcessedBy)
+}
 
-func (tr *taskRepository) Create(ctx context.Context, task *models.Task) (*string, error) {
+// Create inserts task unless the tenant has reached the active or daily
+// limit of quota, which fails with a quota error.
+func (tr *taskRepository) Create(ctx context.Context, task *models.Task, quota models.Quota) (*string, error) {
 	op := place + "Create"
-	query := "INSERT INTO tasks (id, title, description, status) VALUES ($1,$2,$3,$4)"
-	res, err := tr.Storage.Pool.Exec(ctx, query, task.ID, task.Title, task.Description, task.Status)
-	if err != nil {
-		if storage.ErrorAlreadyExists(err) {
-			return nil, errs.ErrAlreadyExists(op, err)
+	err := inTenantTx(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		allowed, exceeded, err := reserve(ctx, q, tenant, quota, 1)
+		if err != nil {
+			return errs.NewAppError(op, err)
 		}
-		return nil, errs.NewAppError(op, err)
-	}
-	if res.RowsAffected() == 0 {
-		return nil, errs.ErrNotFound(op)
+		if allowed == 0 {
+			return errs.ErrQuota(op, exceeded, quotaLimit(quota, exceeded))
+		}
+		query := `INSERT INTO tasks (id, tenant_id, title, description, status, callback_url, created_by, assignee)
+			VALUES ($1,$2,$3,$4,$5,NULLIF($6, ''),NULLIF($7, ''),NULLIF($8, ''))`
+		res, err := q.Exec(ctx, query, task.ID, tenant, task.Title, task.Description, task.Status, task.CallbackURL, task.CreatedBy, task.Assignee)
+		if err != nil {
+			if storage.ErrorAlreadyExists(err) {
+				return errs.ErrAlreadyExists(op, err)
+			}
+			return errs.NewAppError(op, err)
+		}
+		if res.RowsAffected() == 0 {
+			return errs.ErrNotFound(op)
+		}
+		if err := countCreated(ctx, q, tenant, 1); err != nil {
+			return errs.NewAppError(op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	taskId := task.ID.String()
 	return &taskId, nil
 }
 
+// CreateBatch inserts tasks in a single statement and returns the ids of the
+// inserted ones. Tasks whose title is already taken in the tenant, including
+// by an earlier task of the same batch, are skipped. Only as many tasks as
+// quota allows are tried; their number is returned, so the tasks past it are
+// the ones left out by the quota.
+func (tr *taskRepository) CreateBatch(ctx context.Context, tasks []models.Task, quota models.Quota) ([]uuid.UUID, int, error) {
+	op := place + "CreateBatch"
+	ids := make([]uuid.UUID, 0, len(tasks))
+	titles := make([]string, 0, len(tasks))
+	descriptions := make([]string, 0, len(tasks))
+	statuses := make([]string, 0, len(tasks))
+	callbackURLs := make([]string, 0, len(tasks))
+	creators := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+		titles = append(titles, task.Title)
+		descriptions = append(descriptions, task.Description)
+		statuses = append(statuses, task.Status)
+		callbackURLs = append(callbackURLs, task.CallbackURL)
+		creators = append(creators, task.CreatedBy)
+	}
+	created := []uuid.UUID{}
+	allowed := 0
+	err := inTenantTx(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		var err error
+		if allowed, _, err = reserve(ctx, q, tenant, quota, len(tasks)); err != nil {
+			return errs.NewAppError(op, err)
+		}
+		if allowed == 0 {
+			return nil
+		}
+		ids, titles, descriptions, statuses, callbackURLs, creators := ids[:allowed], titles[:allowed], descriptions[:allowed], statuses[:allowed], callbackURLs[:allowed], creators[:allowed]
+		query := `INSERT INTO tasks (id, tenant_id, title, description, status, callback_url, created_by)
+			SELECT id, $7, title, description, status, NULLIF(callback_url, ''), NULLIF(created_by, '')
+			FROM unnest($1::uuid[], $2::varchar[], $3::varchar[], $4::varchar[], $5::varchar[], $6::varchar[])
+				AS t(id, title, description, status, callback_url, created_by)
+			ON CONFLICT (tenant_id, title) DO NOTHING
+			RETURNING id`
+		rows, err := q.Query(ctx, query, ids, titles, descriptions, statuses, callbackURLs, creators, tenant)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				return errs.NewAppError(op, err)
+			}
+			created = append(created, id)
+		}
+		if err := rows.Err(); err != nil {
+			if storage.CheckErr(err) {
+				return errs.ErrInvalidValues(op, err)
+			}
+			return errs.NewAppError(op, err)
+		}
+		if err := countCreated(ctx, q, tenant, len(created)); err != nil {
+			return errs.NewAppError(op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return created, allowed, nil
+}
+
+// StartProcessing moves the task to processing by actor unless the tenant
+// already has maxProcessing tasks processing, in which case it reports false.
+// Zero maxProcessing is unlimited.
+func (tr *taskRepository) StartProcessing(ctx context.Context, id, actor string, maxProcessing int) (bool, error) {
+	op := place + "StartProcessing"
+	started := false
+	err := inTenantTx(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		if maxProcessing > 0 {
+			usage, err := lockUsage(ctx, q, tenant)
+			if err != nil {
+				return errs.NewAppError(op, err)
+			}
+			if usage.Processing >= maxProcessing {
+				return nil
+			}
+		}
+		query := "UPDATE tasks SET status = 'processing', processed_by = NULLIF($3, ''), version = version + 1 WHERE id = $1 AND tenant_id = $2"
+		res, err := q.Exec(ctx, query, id, tenant, actor)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		if res.RowsAffected() == 0 {
+			return errs.ErrNotFound(op)
+		}
+		started = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return started, nil
+}
+
+// lockUsage takes the quota lock of tenant and returns its usage, which
+// stays true until the transaction of q ends.
+func lockUsage(ctx context.Context, q storage.Querier, tenant string) (*models.QuotaUsage, error) {
+	if _, err := q.Exec(ctx, quotaLockQuery, tenant); err != nil {
+		return nil, err
+	}
+	usage := models.QuotaUsage{}
+	if err := scanUsage(q.QueryRow(ctx, usageQuery, tenant), &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// reserve returns how many of n tasks the tenant may create under quota and,
+// when that is fewer than n, the quota that limits them.
+func reserve(ctx context.Context, q storage.Querier, tenant string, quota models.Quota, n int) (int, string, error) {
+	if quota.MaxActive == 0 && quota.MaxDaily == 0 {
+		return n, "", nil
+	}
+	usage, err := lockUsage(ctx, q, tenant)
+	if err != nil {
+		return 0, "", err
+	}
+	allowed, exceeded := n, ""
+	if quota.MaxActive > 0 && quota.MaxActive-usage.Active < allowed {
+		allowed, exceeded = max(quota.MaxActive-usage.Active, 0), models.QuotaMaxActive
+	}
+	if quota.MaxDaily > 0 && quota.MaxDaily-usage.CreatedToday < allowed {
+		allowed, exceeded = max(quota.MaxDaily-usage.CreatedToday, 0), models.QuotaMaxDaily
+	}
+	return allowed, exceeded, nil
+}
+
+func quotaLimit(quota models.Quota, name string) int {
+	switch name {
+	case models.QuotaMaxActive:
+		return quota.MaxActive
+	case models.QuotaMaxProcessing:
+		return quota.MaxProcessing
+	default:
+		return quota.MaxDaily
+	}
+}
+
+// countCreated adds n to the tasks the tenant created today. The count is
+// kept apart from the tasks, so deleting tasks does not free the daily quota.
+func countCreated(ctx context.Context, q storage.Querier, tenant string, n int) error {
+	if n == 0 {
+		return nil
+	}
+	_, err := q.Exec(ctx, `INSERT INTO tenant_usage (tenant_id, day, created) VALUES ($1, (now() AT TIME ZONE 'UTC')::date, $2)
+		ON CONFLICT (tenant_id, day) DO UPDATE SET created = tenant_usage.created + EXCLUDED.created`, tenant, n)
+	return err
+}
+
 func (tr *taskRepository) GetById(ctx context.Context, id string) (*models.Task, error) {
 	op := place + "GetById"
-	query := "SELECT * FROM tasks WHERE id = $1"
 	task := models.Task{}
-	if err := tr.Storage.Pool.QueryRow(ctx, query, id).Scan(&task.ID, &task.Title, &task.Description, &task.Status); err != nil {
-		if errors.Is(err, storage.ErrNotFound()) {
-			return nil, errs.ErrNotFound(op)
+	err := inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		query := "SELECT " + taskColumns + " FROM tasks WHERE id = $1 AND tenant_id = $2"
+		if err := scanTask(q.QueryRow(ctx, query, id, tenant), &task); err != nil {
+			if errors.Is(err, storage.ErrNotFound()) {
+				return errs.ErrNotFound(op)
+			}
+			return errs.NewAppError(op, err)
 		}
-		return nil, errs.NewAppError(op, err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return &task, nil
 }
 
-func (tr *taskRepository) Get(ctx context.Context, amount, page int, statusFilter string) ([]models.Task, error) {
+func (tr *taskRepository) Get(ctx context.Context, amount, page int, filter models.TaskFilter) ([]models.Task, error) {
 	op := place + "Get"
-	args := []any{}
-	strs := []string{}
-	validStatus := map[string]bool{
-		"created":    true,
-		"processing": true,
-		"done":       true,
-	}
-	i := 0
-	if validStatus[statusFilter] {
-		i++
-		strs = append(strs, fmt.Sprintf("status = $%d", i))
-		args = append(args, statusFilter)
-	}
-	query := "SELECT * FROM tasks"
-	if len(strs) > 0 {
-		query += " WHERE " + strs[0]
-	}
-	if amount > 0 && page > 0 {
-		i++
-		offset := (page - 1) * amount
-		query += fmt.Sprintf(" OFFSET $%d LIMIT $%d", i, i+1)
-		args = append(args, offset, amount)
+	tasks := []models.Task{}
+	err := inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		strs, args := filterConditions(tenant, nil, filter)
+		i := len(args)
+		query := "SELECT " + taskColumns + " FROM tasks WHERE " + strings.Join(strs, " AND ")
+		if amount > 0 && page > 0 {
+			i++
+			offset := (page - 1) * amount
+			query += fmt.Sprintf(" OFFSET $%d LIMIT $%d", i, i+1)
+			args = append(args, offset, amount)
+		}
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			task := models.Task{}
+			if err := scanTask(rows, &task); err != nil {
+				return errs.NewAppError(op, err)
+			}
+			tasks = append(tasks, task)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return tasks, nil
+}
 
-	tasks := []models.Task{}
-	fmt.Println(query)
-	rows, err := tr.Storage.Pool.Query(ctx, query, args...)
+func (tr *taskRepository) UpdateStatus(ctx context.Context, id, status string) error {
+	op := place + "UpdateStatus"
+	return inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		query := "UPDATE tasks SET status = $1, version = version + 1 WHERE id = $2 AND tenant_id = $3"
+		res, err := q.Exec(ctx, query, status, id, tenant)
+		if err != nil {
+			if storage.CheckErr(err) {
+				return errs.ErrInvalidValues(op, err)
+			}
+			return errs.NewAppError(op, err)
+		}
+		if res.RowsAffected() == 0 {
+			return errs.ErrNotFound(op)
+		}
+		return nil
+	})
+}
+
+// UpdateStatusIfVersion updates the status only while the task still has the
+// given version, so concurrent writers cannot overwrite each other.
+func (tr *taskRepository) UpdateStatusIfVersion(ctx context.Context, id, status string, version int) error {
+	op := place + "UpdateStatusIfVersion"
+	return inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		query := `WITH current AS (SELECT version FROM tasks WHERE id = $2 AND tenant_id = $4),
+			updated AS (UPDATE tasks SET status = $1, version = version + 1 WHERE id = $2 AND tenant_id = $4 AND version = $3 RETURNING id)
+			SELECT (SELECT count(*) FROM current), (SELECT count(*) FROM updated)`
+		var found, updated int
+		if err := q.QueryRow(ctx, query, status, id, version, tenant).Scan(&found, &updated); err != nil {
+			if storage.CheckErr(err) {
+				return errs.ErrInvalidValues(op, err)
+			}
+			return errs.NewAppError(op, err)
+		}
+		if found == 0 {
+			return errs.ErrNotFound(op)
+		}
+		if updated == 0 {
+			return errs.ErrPreconditionFailed(op)
+		}
+		return nil
+	})
+}
+
+// Assign sets the assignee of the task, an empty one unassigning it. With
+// version set it only succeeds while the task still has that version. The
+// version is left as is when the assignee does not change.
+func (tr *taskRepository) Assign(ctx context.Context, id, assignee string, version *int) (*models.Task, error) {
+	op := place + "Assign"
+	task := models.Task{}
+	err := inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		query := `UPDATE tasks SET assignee = NULLIF($1, ''), version = version + 1
+			WHERE id = $2 AND tenant_id = $3 AND ($4::int IS NULL OR version = $4) AND assignee IS DISTINCT FROM NULLIF($1, '')
+			RETURNING ` + taskColumns
+		err := scanTask(q.QueryRow(ctx, query, assignee, id, tenant, version), &task)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, storage.ErrNotFound()) {
+			return errs.NewAppError(op, err)
+		}
+		query = "SELECT " + taskColumns + " FROM tasks WHERE id = $1 AND tenant_id = $2"
+		if err := scanTask(q.QueryRow(ctx, query, id, tenant), &task); err != nil {
+			if errors.Is(err, storage.ErrNotFound()) {
+				return errs.ErrNotFound(op)
+			}
+			return errs.NewAppError(op, err)
+		}
+		if version != nil && task.Version != *version {
+			return errs.ErrPreconditionFailed(op)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errs.NewAppError(op, err)
+		return nil, err
 	}
-	defer rows.Close()
-	for rows.Next() {
-		task := models.Task{}
-		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status); err != nil {
-			return nil, errs.NewAppError(op, err)
+	return &task, nil
+}
+
+// GetPageByFilter returns up to limit tasks matching the filter with ids
+// after the given one in id order, so callers page through a selection while
+// changing it. uuid.Nil starts from the first task.
+func (tr *taskRepository) GetPageByFilter(ctx context.Context, ids []uuid.UUID, filter models.TaskFilter, after uuid.UUID, limit int) ([]models.Task, error) {
+	op := place + "GetPageByFilter"
+	tasks := []models.Task{}
+	err := inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		strs, args := filterConditions(tenant, ids, filter)
+		args = append(args, after)
+		strs = append(strs, fmt.Sprintf("id > $%d", len(args)))
+		args = append(args, limit)
+		query := "SELECT " + taskColumns + " FROM tasks WHERE " + strings.Join(strs, " AND ") + fmt.Sprintf(" ORDER BY id LIMIT $%d", len(args))
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			task := models.Task{}
+			if err := scanTask(rows, &task); err != nil {
+				return errs.NewAppError(op, err)
+			}
+			tasks = append(tasks, task)
+		}
+		if err := rows.Err(); err != nil {
+			return errs.NewAppError(op, err)
 		}
-		tasks = append(tasks, task)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return tasks, nil
 }
 
-func (tr *taskRepository) UpdateStatus(ctx context.Context, id, status string) error {
-	op := place + "UpdateStatus"
-	query := "UPDATE tasks SET status = $1 WHERE id = $2"
-	res, err := tr.Storage.Pool.Exec(ctx, query, status, id)
+func (tr *taskRepository) UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status string, from []string) ([]uuid.UUID, error) {
+	op := place + "UpdateStatusBatch"
+	updated := []uuid.UUID{}
+	err := inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		query := "UPDATE tasks SET status = $1, version = version + 1 WHERE id = ANY($2) AND status = ANY($3) AND tenant_id = $4 RETURNING id"
+		rows, err := q.Query(ctx, query, status, ids, from, tenant)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				return errs.NewAppError(op, err)
+			}
+			updated = append(updated, id)
+		}
+		if err := rows.Err(); err != nil {
+			if storage.CheckErr(err) {
+				return errs.ErrInvalidValues(op, err)
+			}
+			return errs.NewAppError(op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (tr *taskRepository) DeleteBatch(ctx context.Context, ids []uuid.UUID) (int, error) {
+	op := place + "DeleteBatch"
+	deleted := 0
+	err := inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		query := "DELETE FROM tasks WHERE id = ANY($1) AND tenant_id = $2"
+		res, err := q.Exec(ctx, query, ids, tenant)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		deleted = int(res.RowsAffected())
+		return nil
+	})
 	if err != nil {
-		if storage.CheckErr(err) {
-			return errs.ErrInvalidValues(op, err)
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// Export calls fn for every task matching the filter. Rows are read from the
+// connection as fn consumes them, so memory stays flat however many tasks
+// match. An error returned by fn stops the export and is returned as is.
+func (tr *taskRepository) Export(ctx context.Context, filter models.TaskFilter, fn func(*models.Task) error) error {
+	op := place + "Export"
+	return inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		strs, args := filterConditions(tenant, nil, filter)
+		query := "SELECT " + taskColumns + " FROM tasks WHERE " + strings.Join(strs, " AND ")
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return errs.NewAppError(op, err)
 		}
-		return errs.NewAppError(op, err)
+		defer rows.Close()
+		task := models.Task{}
+		for rows.Next() {
+			if err := scanTask(rows, &task); err != nil {
+				return errs.NewAppError(op, err)
+			}
+			if err := fn(&task); err != nil {
+				return err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return errs.NewAppError(op, err)
+		}
+		return nil
+	})
+}
+
+// Stats aggregates tasks in the database, so the cost does not depend on
+// the number of tasks sent to the client. from must be aligned to bucket.
+func (tr *taskRepository) Stats(ctx context.Context, from, to time.Time, bucket time.Duration) (*models.TaskStats, error) {
+	op := place + "Stats"
+	stats := &models.TaskStats{
+		From:    from,
+		To:      to,
+		Bucket:  bucket,
+		Counts:  map[string]int{},
+		Buckets: []models.StatsBucket{},
 	}
-	if res.RowsAffected() == 0 {
-		return errs.ErrNotFound(op)
+	for _, status := range models.Statuses() {
+		stats.Counts[status] = 0
+	}
+	err := inTenant(ctx, tr.Storage, op, func(q storage.Querier, tenant string) error {
+		rows, err := q.Query(ctx, "SELECT status, count(*) FROM tasks WHERE tenant_id = $1 GROUP BY status", tenant)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		for rows.Next() {
+			var (
+				status string
+				count  int
+			)
+			if err := rows.Scan(&status, &count); err != nil {
+				rows.Close()
+				return errs.NewAppError(op, err)
+			}
+			stats.Counts[status] = count
+			stats.Total += count
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return errs.NewAppError(op, err)
+		}
+
+		query := `WITH buckets AS (
+				SELECT generate_series($1::timestamptz, $2::timestamptz - interval '1 microsecond', $3::interval) AS start
+			), created AS (
+				SELECT date_bin($3::interval, created_at, $1::timestamptz) AS start, count(*) AS n
+				FROM tasks WHERE tenant_id = $4 AND created_at >= $1 AND created_at < $2 GROUP BY 1
+			), finished AS (
+				SELECT date_bin($3::interval, finished_at, $1::timestamptz) AS start, count(*) AS n
+				FROM tasks WHERE tenant_id = $4 AND finished_at >= $1 AND finished_at < $2 GROUP BY 1
+			)
+			SELECT b.start, COALESCE(c.n, 0), COALESCE(f.n, 0)
+			FROM buckets b LEFT JOIN created c USING (start) LEFT JOIN finished f USING (start)
+			ORDER BY b.start`
+		rows, err = q.Query(ctx, query, from, to, bucket, tenant)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		for rows.Next() {
+			b := models.StatsBucket{}
+			if err := rows.Scan(&b.Start, &b.Created, &b.Finished); err != nil {
+				rows.Close()
+				return errs.NewAppError(op, err)
+			}
+			stats.Buckets = append(stats.Buckets, b)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return errs.NewAppError(op, err)
+		}
+
+		query = `SELECT count(d),
+				COALESCE(avg(d), interval '0'),
+				COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY d), interval '0'),
+				COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY d), interval '0'),
+				COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY d), interval '0'),
+				COALESCE(max(d), interval '0')
+			FROM (
+				SELECT finished_at - started_at AS d FROM tasks
+				WHERE tenant_id = $3 AND finished_at >= $1 AND finished_at < $2 AND started_at IS NOT NULL
+			) durations`
+		p := &stats.Processing
+		if err := q.QueryRow(ctx, query, from, to, tenant).Scan(&p.Count, &p.Avg, &p.P50, &p.P90, &p.P99, &p.Max); err != nil {
+			return errs.NewAppError(op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// filterConditions builds the WHERE conditions shared by the list and bulk
+// queries. The tenant condition comes first and is always present.
+func filterConditions(tenant string, ids []uuid.UUID, filter models.TaskFilter) ([]string, []any) {
+	strs := []string{"tenant_id = $1"}
+	args := []any{tenant}
+	if len(ids) > 0 {
+		args = append(args, ids)
+		strs = append(strs, fmt.Sprintf("id = ANY($%d)", len(args)))
+	}
+	if models.IsValidStatus(filter.Status) {
+		args = append(args, filter.Status)
+		strs = append(strs, fmt.Sprintf("status = $%d", len(args)))
+	}
+	for _, actor := range []struct{ column, value string }{
+		{"created_by", filter.CreatedBy},
+		{"assignee", filter.Assignee},
+	} {
+		switch actor.value {
+		case "":
+		case models.ActorNone:
+			strs = append(strs, actor.column+" IS NULL")
+		default:
+			args = append(args, actor.value)
+			strs = append(strs, fmt.Sprintf("%s = $%d", actor.column, len(args)))
+		}
 	}
-	return nil
+	return strs, args
 }
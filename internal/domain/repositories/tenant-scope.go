@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/storage"
+	"context"
+	"errors"
+)
+
+// ErrNoTenant is returned by the tenant scoped queries of a context without a
+// tenant, so a missing scope fails instead of reaching every tenant.
+var ErrNoTenant = errors.New("no tenant in context")
+
+// tenantOf returns the tenant of ctx for queries filtering by tenant_id.
+func tenantOf(ctx context.Context, op string) (string, error) {
+	tenant := models.TenantFromContext(ctx)
+	if tenant == "" {
+		return "", errs.NewAppError(op, ErrNoTenant)
+	}
+	return tenant, nil
+}
+
+// inTenant runs fn with the tenant of ctx, inside the row-level security
+// transaction when it is enabled. Queries of fn still filter by tenant_id
+// themselves. Errors of fn are returned as is, others are attributed to op.
+func inTenant(ctx context.Context, s *storage.Storage, op string, fn func(q storage.Querier, tenant string) error) error {
+	return scoped(ctx, s.InTenant, op, fn)
+}
+
+// inTenantTx is inTenant that always runs fn in a transaction.
+func inTenantTx(ctx context.Context, s *storage.Storage, op string, fn func(q storage.Querier, tenant string) error) error {
+	return scoped(ctx, s.InTenantTx, op, fn)
+}
+
+func scoped(ctx context.Context, run func(context.Context, string, func(storage.Querier) error) error, op string, fn func(q storage.Querier, tenant string) error) error {
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	err = run(ctx, tenant, func(q storage.Querier) error {
+		fnErr = fn(q, tenant)
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		return errs.NewAppError(op, err)
+	}
+	return err
+}
@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/storage"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetById(ctx context.Context, id uuid.UUID) (*models.Webhook, error)
+	Get(ctx context.Context) ([]models.Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Enable(ctx context.Context, id uuid.UUID) error
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, attempt models.WebhookAttempt, status string, nextAttemptAt time.Time) error
+	TrackHealth(ctx context.Context, id uuid.UUID, success bool, disableAfter int) (bool, error)
+	GetDeliveries(ctx context.Context, webhookId uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	Storage *storage.Storage
+}
+
+func NewWebhookRepository(s *storage.Storage) WebhookRepository {
+	return &webhookRepository{
+		Storage: s,
+	}
+}
+
+const (
+	webhookPlace   = "webhookRepository."
+	webhookColumns = "id, url, secret, statuses, active, failure_count, disabled_at, created_at"
+)
+
+func scanWebhook(row rowScanner, webhook *models.Webhook) error {
+	return row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &webhook.Statuses, &webhook.Active,
+		&webhook.FailureCount, &webhook.DisabledAt, &webhook.CreatedAt)
+}
+
+func (wr *webhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	op := webhookPlace + "Create"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO webhooks (id, tenant_id, url, secret, statuses) VALUES ($1,$2,$3,$4,$5) RETURNING " + webhookColumns
+	if err := scanWebhook(wr.Storage.Pool.QueryRow(ctx, query, webhook.ID, tenant, webhook.URL, webhook.Secret, webhook.Statuses), webhook); err != nil {
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
+
+func (wr *webhookRepository) GetById(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
+	op := webhookPlace + "GetById"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + webhookColumns + " FROM webhooks WHERE id = $1 AND tenant_id = $2"
+	webhook := models.Webhook{}
+	if err := scanWebhook(wr.Storage.Pool.QueryRow(ctx, query, id, tenant), &webhook); err != nil {
+		if errors.Is(err, storage.ErrNotFound()) {
+			return nil, errs.ErrNotFound(op)
+		}
+		return nil, errs.NewAppError(op, err)
+	}
+	return &webhook, nil
+}
+
+func (wr *webhookRepository) Get(ctx context.Context) ([]models.Webhook, error) {
+	op := webhookPlace + "Get"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT " + webhookColumns + " FROM webhooks WHERE tenant_id = $1 ORDER BY created_at"
+	rows, err := wr.Storage.Pool.Query(ctx, query, tenant)
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	defer rows.Close()
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook := models.Webhook{}
+		if err := scanWebhook(rows, &webhook); err != nil {
+			return nil, errs.NewAppError(op, err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	return webhooks, nil
+}
+
+func (wr *webhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	op := webhookPlace + "Delete"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return err
+	}
+	query := "DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2"
+	res, err := wr.Storage.Pool.Exec(ctx, query, id, tenant)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return errs.ErrNotFound(op)
+	}
+	return nil
+}
+
+func (wr *webhookRepository) Enable(ctx context.Context, id uuid.UUID) error {
+	op := webhookPlace + "Enable"
+	tenant, err := tenantOf(ctx, op)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE webhooks SET active = true, failure_count = 0, disabled_at = NULL WHERE id = $1 AND tenant_id = $2"
+	res, err := wr.Storage.Pool.Exec(ctx, query, id, tenant)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return errs.ErrNotFound(op)
+	}
+	return nil
+}
+
+// ClaimDeliveries picks due pending deliveries and pushes their next attempt
+// forward by the lease, so other replicas skip them while they are being
+// sent. Deliveries of disabled webhooks stay pending until re-enabled.
+func (wr *webhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	op := webhookPlace + "ClaimDeliveries"
+	query := `WITH due AS (
+			SELECT d.id FROM webhook_deliveries d
+			LEFT JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND (d.webhook_id IS NULL OR w.active)
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d SET next_attempt_at = now() + $2::interval, updated_at = now()
+		FROM due WHERE d.id = due.id
+		RETURNING d.id, d.webhook_id, d.task_id, d.tenant_id, d.url, COALESCE((SELECT secret FROM webhooks WHERE id = d.webhook_id), ''),
+			d.payload, d.status, d.attempts, d.next_attempt_at, d.created_at`
+	rows, err := wr.Storage.Pool.Query(ctx, query, limit, lease)
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	defer rows.Close()
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d := models.WebhookDelivery{}
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.TaskID, &d.Tenant, &d.URL, &d.Secret, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.CreatedAt); err != nil {
+			return nil, errs.NewAppError(op, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt appends the attempt to the delivery log and moves the
+// delivery to its new status in one statement.
+func (wr *webhookRepository) RecordAttempt(ctx context.Context, attempt models.WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	op := webhookPlace + "RecordAttempt"
+	query := `WITH attempt AS (
+			INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+			VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, ''), $5)
+		)
+		UPDATE webhook_deliveries SET status = $6, attempts = $2, last_status_code = NULLIF($3, 0),
+			last_error = NULLIF($4, ''), next_attempt_at = $7, updated_at = now()
+		WHERE id = $1`
+	_, err := wr.Storage.Pool.Exec(ctx, query, attempt.DeliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error,
+		attempt.DurationMs, status, nextAttemptAt)
+	if err != nil {
+		return errs.NewAppError(op, err)
+	}
+	return nil
+}
+
+// TrackHealth resets the webhook's consecutive failure counter on success or
+// increments it on failure, disabling the webhook once it reaches
+// disableAfter. It reports whether the webhook is still active.
+func (wr *webhookRepository) TrackHealth(ctx context.Context, id uuid.UUID, success bool, disableAfter int) (bool, error) {
+	op := webhookPlace + "TrackHealth"
+	query := `UPDATE webhooks SET
+			failure_count = CASE WHEN $2 THEN 0 ELSE failure_count + 1 END,
+			active = active AND ($2 OR failure_count + 1 < $3),
+			disabled_at = CASE WHEN active AND NOT $2 AND failure_count + 1 >= $3 THEN now() ELSE disabled_at END
+		WHERE id = $1
+		RETURNING active`
+	var active bool
+	if err := wr.Storage.Pool.QueryRow(ctx, query, id, success, disableAfter).Scan(&active); err != nil {
+		if errors.Is(err, storage.ErrNotFound()) {
+			return false, errs.ErrNotFound(op)
+		}
+		return false, errs.NewAppError(op, err)
+	}
+	return active, nil
+}
+
+// GetDeliveries is not scoped to a tenant; the webhook must be looked up with
+// GetById first.
+func (wr *webhookRepository) GetDeliveries(ctx context.Context, webhookId uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	op := webhookPlace + "GetDeliveries"
+	query := `SELECT id, webhook_id, task_id, url, status, attempts, COALESCE(last_status_code, 0), COALESCE(last_error, ''),
+			next_attempt_at, created_at
+		FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2`
+	rows, err := wr.Storage.Pool.Query(ctx, query, webhookId, limit)
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	defer rows.Close()
+	deliveries := []models.WebhookDelivery{}
+	index := map[int64]int{}
+	for rows.Next() {
+		d := models.WebhookDelivery{AttemptLog: []models.WebhookAttempt{}}
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.TaskID, &d.URL, &d.Status, &d.Attempts, &d.LastStatusCode, &d.LastError,
+			&d.NextAttemptAt, &d.CreatedAt); err != nil {
+			return nil, errs.NewAppError(op, err)
+		}
+		index[d.ID] = len(deliveries)
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	ids := make([]int64, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.ID)
+	}
+	query = `SELECT delivery_id, attempt, COALESCE(status_code, 0), COALESCE(error, ''), duration_ms, created_at
+		FROM webhook_attempts WHERE delivery_id = ANY($1) ORDER BY delivery_id, attempt`
+	attemptRows, err := wr.Storage.Pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	defer attemptRows.Close()
+	for attemptRows.Next() {
+		a := models.WebhookAttempt{}
+		if err := attemptRows.Scan(&a.DeliveryID, &a.Attempt, &a.StatusCode, &a.Error, &a.DurationMs, &a.CreatedAt); err != nil {
+			return nil, errs.NewAppError(op, err)
+		}
+		d := &deliveries[index[a.DeliveryID]]
+		d.AttemptLog = append(d.AttemptLog, a)
+	}
+	if err := attemptRows.Err(); err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/repositories"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPlace = "apiKeyService."
+	// apiKeyTokenPrefix marks issued keys, so leaked ones are easy to find
+	// in code and logs.
+	apiKeyTokenPrefix = "btz_"
+	apiKeyPrefixLen   = len(apiKeyTokenPrefix) + 8
+	// apiKeyTouchInterval limits how often last use of a key is written.
+	apiKeyTouchInterval = time.Minute
+	bootstrapKeyMinLen  = 32
+	bootstrapKeyName    = "bootstrap"
+)
+
+type ApiKeyService interface {
+	Create(ctx context.Context, name, tenant string, scopes []string) (*models.ApiKey, string, error)
+	Get(ctx context.Context) ([]models.ApiKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	Rotate(ctx context.Context, id uuid.UUID) (*models.ApiKey, string, error)
+	Authenticate(ctx context.Context, token string) (*models.ApiKey, error)
+	Bootstrap(ctx context.Context, token string) error
+}
+
+type apiKeyService struct {
+	ApiKeyRepository repositories.ApiKeyRepository
+	Logger           *logger.Logger
+}
+
+func NewApiKeyService(ar repositories.ApiKeyRepository, l *logger.Logger) ApiKeyService {
+	return &apiKeyService{
+		ApiKeyRepository: ar,
+		Logger:           l,
+	}
+}
+
+// Create issues a new key bound to tenant, or to no tenant when it is empty.
+// The returned token is the only copy of the key; it cannot be recovered from
+// the stored hash. Keys are managed by callers bound to no tenant only, so a
+// tenant cannot issue keys for another one or unbound ones.
+func (as *apiKeyService) Create(ctx context.Context, name, tenant string, scopes []string) (*models.ApiKey, string, error) {
+	op := apiKeyPlace + "Create"
+	log := as.Logger.AddOp(op)
+	log.Info("creating api key")
+	if err := requireUnbound(ctx, op); err != nil {
+		return nil, "", err
+	}
+	v := &validator{}
+	if v.required("name", name) {
+		v.maxLength("name", name, models.ApiKeyNameMaxLength)
+	}
+	if tenant != "" && !models.IsValidTenant(tenant) {
+		v.add("tenant", tenantMessage)
+	}
+	if len(scopes) == 0 {
+		v.add("scopes", "is required")
+	}
+	for i, scope := range scopes {
+		v.oneOf(fmt.Sprintf("scopes[%d]", i), scope, models.Scopes())
+	}
+	if err := v.err(op); err != nil {
+		log.Error("invalid api key", logger.Err(err))
+		return nil, "", err
+	}
+	token, err := newApiKeyToken()
+	if err != nil {
+		log.Error("failed to generate api key", logger.Err(err))
+		return nil, "", errs.NewAppError(op, err)
+	}
+	key := &models.ApiKey{
+		ID:     uuid.New(),
+		Name:   name,
+		Prefix: token[:apiKeyPrefixLen],
+		Tenant: tenant,
+		Hash:   hashApiKey(token),
+		Scopes: append([]string{}, scopes...),
+	}
+	if err := as.ApiKeyRepository.Create(ctx, key); err != nil {
+		log.Error("failed to create api key", logger.Err(err))
+		return nil, "", errs.NewAppError(op, err)
+	}
+	log.Info("api key created", "api_key_id", key.ID)
+	return key, token, nil
+}
+
+func (as *apiKeyService) Get(ctx context.Context) ([]models.ApiKey, error) {
+	op := apiKeyPlace + "Get"
+	log := as.Logger.AddOp(op)
+	log.Info("fetching api keys")
+	if err := requireUnbound(ctx, op); err != nil {
+		return nil, err
+	}
+	keys, err := as.ApiKeyRepository.Get(ctx)
+	if err != nil {
+		log.Error("failed to fetch api keys", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("api keys fetched")
+	return keys, nil
+}
+
+func (as *apiKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	op := apiKeyPlace + "Revoke"
+	log := as.Logger.AddOp(op)
+	log.Info("revoking api key", "api_key_id", id)
+	if err := requireUnbound(ctx, op); err != nil {
+		return err
+	}
+	if err := as.ApiKeyRepository.Revoke(ctx, id); err != nil {
+		log.Error("failed to revoke api key", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("api key revoked")
+	return nil
+}
+
+// Rotate issues a new token for an active key. The previous token stops
+// working immediately.
+func (as *apiKeyService) Rotate(ctx context.Context, id uuid.UUID) (*models.ApiKey, string, error) {
+	op := apiKeyPlace + "Rotate"
+	log := as.Logger.AddOp(op)
+	log.Info("rotating api key", "api_key_id", id)
+	if err := requireUnbound(ctx, op); err != nil {
+		return nil, "", err
+	}
+	token, err := newApiKeyToken()
+	if err != nil {
+		log.Error("failed to generate api key", logger.Err(err))
+		return nil, "", errs.NewAppError(op, err)
+	}
+	key, err := as.ApiKeyRepository.Rotate(ctx, id, hashApiKey(token), token[:apiKeyPrefixLen])
+	if err != nil {
+		log.Error("failed to rotate api key", logger.Err(err))
+		return nil, "", errs.NewAppError(op, err)
+	}
+	log.Info("api key rotated")
+	return key, token, nil
+}
+
+// Authenticate resolves the key of a token. Unknown and revoked keys are
+// both reported as unauthorized so a caller cannot probe for revoked keys.
+func (as *apiKeyService) Authenticate(ctx context.Context, token string) (*models.ApiKey, error) {
+	op := apiKeyPlace + "Authenticate"
+	log := as.Logger.AddOp(op)
+	if token == "" {
+		return nil, errs.ErrUnauthorized(op)
+	}
+	key, err := as.ApiKeyRepository.GetByHash(ctx, hashApiKey(token))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFoundBase) {
+			log.Info("unknown api key")
+			return nil, errs.ErrUnauthorized(op)
+		}
+		log.Error("failed to receive api key", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	if key.RevokedAt != nil {
+		log.Info("revoked api key used", "api_key_id", key.ID)
+		return nil, errs.ErrUnauthorized(op)
+	}
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := as.ApiKeyRepository.Touch(ctx, key.ID, apiKeyTouchInterval); err != nil {
+			// a missed last use is not worth failing the request
+			log.Error("failed to record api key use", logger.Err(err))
+		}
+	}
+	log.Debug("api key authenticated", "api_key_id", key.ID)
+	return key, nil
+}
+
+// Bootstrap stores token as an admin key unless it is already known, so the
+// first keys can be created through the API. An empty token is skipped.
+// A revoked bootstrap key stays revoked.
+func (as *apiKeyService) Bootstrap(ctx context.Context, token string) error {
+	op := apiKeyPlace + "Bootstrap"
+	log := as.Logger.AddOp(op)
+	if token == "" {
+		log.Info("no bootstrap api key configured")
+		return nil
+	}
+	if len(token) < bootstrapKeyMinLen {
+		return errs.ErrInvalidValues(op, fmt.Errorf("bootstrap api key must be at least %d characters", bootstrapKeyMinLen))
+	}
+	hash := hashApiKey(token)
+	if _, err := as.ApiKeyRepository.GetByHash(ctx, hash); err == nil {
+		log.Info("bootstrap api key already stored")
+		return nil
+	} else if !errors.Is(err, errs.ErrNotFoundBase) {
+		log.Error("failed to receive api key", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	key := &models.ApiKey{
+		ID:     uuid.New(),
+		Name:   bootstrapKeyName,
+		Prefix: token[:min(apiKeyPrefixLen, len(token)/4)],
+		Hash:   hash,
+		Scopes: []string{models.ScopeAdmin},
+	}
+	if err := as.ApiKeyRepository.Create(ctx, key); err != nil && !errors.Is(err, errs.ErrAlreadyExistsBase) {
+		log.Error("failed to store bootstrap api key", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("bootstrap api key stored", "api_key_id", key.ID)
+	return nil
+}
+
+func newApiKeyToken() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyTokenPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashApiKey hashes a token for storage. Keys are random, so a plain SHA-256
+// is enough; a slow password hash would only slow every request down.
+func hashApiKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
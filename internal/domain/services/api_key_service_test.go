@@ -0,0 +1,383 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockApiKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyRepository) Create(ctx context.Context, key *models.ApiKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) Get(ctx context.Context) ([]models.ApiKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) GetByHash(ctx context.Context, hash string) (*models.ApiKey, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) Rotate(ctx context.Context, id uuid.UUID, hash, prefix string) (*models.ApiKey, error) {
+	args := m.Called(ctx, id, hash, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) Touch(ctx context.Context, id uuid.UUID, interval time.Duration) error {
+	args := m.Called(ctx, id, interval)
+	return args.Error(0)
+}
+
+func newTestApiKeyService(mockRepo *MockApiKeyRepository) *apiKeyService {
+	return &apiKeyService{
+		ApiKeyRepository: mockRepo,
+		Logger:           logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"}),
+	}
+}
+
+func TestApiKeyService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		keyName       string
+		tenant        string
+		scopes        []string
+		caller        *models.Caller
+		mockSetup     func(*MockApiKeyRepository)
+		expectedError bool
+		errorIs       error
+	}{
+		{
+			name:    "successful key creation",
+			keyName: "ci-pipeline",
+			scopes:  []string{models.ScopeTasksRead, models.ScopeTasksWrite},
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(key *models.ApiKey) bool {
+					return key.Name == "ci-pipeline" && len(key.Hash) == 64 && strings.HasPrefix(key.Prefix, apiKeyTokenPrefix)
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "key bound to a tenant",
+			keyName: "team-a-ci",
+			tenant:  "team-a",
+			scopes:  []string{models.ScopeTasksWrite},
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(key *models.ApiKey) bool {
+					return key.Tenant == "team-a"
+				})).Return(nil)
+			},
+		},
+		{
+			name:          "unbound key minted by a tenant-bound admin",
+			keyName:       "escape",
+			scopes:        []string{models.ScopeAdmin},
+			caller:        &models.Caller{Type: models.CallerApiKey, Subject: "team-a-admin", Roles: []string{models.ScopeAdmin}, Tenant: "team-a"},
+			mockSetup:     func(mockRepo *MockApiKeyRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrForbiddenBase,
+		},
+		{
+			name:          "key of another tenant minted by a tenant-bound admin",
+			keyName:       "team-b-ci",
+			tenant:        "team-b",
+			scopes:        []string{models.ScopeTasksWrite},
+			caller:        &models.Caller{Type: models.CallerUser, Subject: "alice", Roles: []string{models.ScopeAdmin}, Tenant: "team-a"},
+			mockSetup:     func(mockRepo *MockApiKeyRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrForbiddenBase,
+		},
+		{
+			name:          "invalid tenant",
+			keyName:       "ci-pipeline",
+			tenant:        "Team A",
+			scopes:        []string{models.ScopeTasksRead},
+			mockSetup:     func(mockRepo *MockApiKeyRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "missing name and scopes",
+			mockSetup:     func(mockRepo *MockApiKeyRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "unknown scope",
+			keyName:       "ci-pipeline",
+			scopes:        []string{"tasks:delete"},
+			mockSetup:     func(mockRepo *MockApiKeyRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name:    "repository error",
+			keyName: "ci-pipeline",
+			scopes:  []string{models.ScopeAdmin},
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("database error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockApiKeyRepository)
+			tt.mockSetup(mockRepo)
+			service := newTestApiKeyService(mockRepo)
+			ctx := context.Background()
+			if tt.caller != nil {
+				ctx = WithCaller(ctx, tt.caller)
+			}
+
+			key, token, err := service.Create(ctx, tt.keyName, tt.tenant, tt.scopes)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
+				assert.Nil(t, key)
+				assert.Empty(t, token)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, hashApiKey(token), key.Hash)
+				assert.True(t, strings.HasPrefix(token, key.Prefix))
+				assert.Equal(t, tt.scopes, key.Scopes)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestApiKeyService_Authenticate(t *testing.T) {
+	token := "btz_test-token"
+	recently := time.Now().Add(-time.Second)
+	revokedAt := time.Now().Add(-time.Hour)
+	id := uuid.New()
+	tests := []struct {
+		name          string
+		token         string
+		mockSetup     func(*MockApiKeyRepository)
+		expectedError bool
+		errorIs       error
+	}{
+		{
+			name:  "valid key records its use",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(&models.ApiKey{ID: id}, nil)
+				mockRepo.On("Touch", mock.Anything, id, apiKeyTouchInterval).Return(nil)
+			},
+		},
+		{
+			name:  "recently used key is not touched",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(&models.ApiKey{ID: id, LastUsedAt: &recently}, nil)
+			},
+		},
+		{
+			name:  "failed touch does not fail the request",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(&models.ApiKey{ID: id}, nil)
+				mockRepo.On("Touch", mock.Anything, id, apiKeyTouchInterval).Return(errors.New("database error"))
+			},
+		},
+		{
+			name:          "missing token",
+			mockSetup:     func(mockRepo *MockApiKeyRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:  "unknown key",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(nil, errs.ErrNotFound("test"))
+			},
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:  "revoked key",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(&models.ApiKey{ID: id, RevokedAt: &revokedAt}, nil)
+			},
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:  "repository error",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(nil, errors.New("database error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockApiKeyRepository)
+			tt.mockSetup(mockRepo)
+			service := newTestApiKeyService(mockRepo)
+
+			key, err := service.Authenticate(context.Background(), tt.token)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				} else {
+					assert.NotErrorIs(t, err, errs.ErrUnauthorizedBase)
+				}
+				assert.Nil(t, key)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, id, key.ID)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestApiKeyService_Rotate(t *testing.T) {
+	id := uuid.New()
+	mockRepo := new(MockApiKeyRepository)
+	service := newTestApiKeyService(mockRepo)
+	var hash, prefix string
+	mockRepo.On("Rotate", mock.Anything, id, mock.Anything, mock.Anything).Return(&models.ApiKey{ID: id}, nil).Run(func(args mock.Arguments) {
+		hash, prefix = args.String(2), args.String(3)
+	})
+
+	key, token, err := service.Rotate(context.Background(), id)
+
+	assert.NoError(t, err)
+	assert.Equal(t, id, key.ID)
+	assert.Equal(t, hashApiKey(token), hash)
+	assert.True(t, strings.HasPrefix(token, prefix))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestApiKeyService_TenantBoundCaller(t *testing.T) {
+	id := uuid.New()
+	mockRepo := new(MockApiKeyRepository)
+	service := newTestApiKeyService(mockRepo)
+	ctx := WithCaller(context.Background(), &models.Caller{Type: models.CallerApiKey, Subject: "team-a-admin", Roles: []string{models.ScopeAdmin}, Tenant: "team-a"})
+
+	_, err := service.Get(ctx)
+	assert.ErrorIs(t, err, errs.ErrForbiddenBase)
+	assert.ErrorIs(t, service.Revoke(ctx, id), errs.ErrForbiddenBase)
+	_, _, err = service.Rotate(ctx, id)
+	assert.ErrorIs(t, err, errs.ErrForbiddenBase)
+	mockRepo.AssertNotCalled(t, "Get", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApiKeyService_Bootstrap(t *testing.T) {
+	token := strings.Repeat("k", bootstrapKeyMinLen)
+	tests := []struct {
+		name          string
+		token         string
+		mockSetup     func(*MockApiKeyRepository)
+		expectedError bool
+		errorIs       error
+	}{
+		{
+			name:      "no bootstrap key",
+			mockSetup: func(mockRepo *MockApiKeyRepository) {},
+		},
+		{
+			name:  "stores a new key as admin",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(nil, errs.ErrNotFound("test"))
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(key *models.ApiKey) bool {
+					return key.Name == bootstrapKeyName && slices.Equal(key.Scopes, []string{models.ScopeAdmin}) && key.Hash == hashApiKey(token)
+				})).Return(nil)
+			},
+		},
+		{
+			name:  "known key is kept as is",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				revokedAt := time.Now()
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(&models.ApiKey{RevokedAt: &revokedAt}, nil)
+			},
+		},
+		{
+			name:  "key stored concurrently by another replica",
+			token: token,
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey(token)).Return(nil, errs.ErrNotFound("test"))
+				mockRepo.On("Create", mock.Anything, mock.Anything).Return(errs.ErrAlreadyExists("test", errors.New("duplicate key")))
+			},
+		},
+		{
+			name:          "short key",
+			token:         "short",
+			mockSetup:     func(mockRepo *MockApiKeyRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockApiKeyRepository)
+			tt.mockSetup(mockRepo)
+			service := newTestApiKeyService(mockRepo)
+
+			err := service.Bootstrap(context.Background(), tt.token)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/jwks"
+	"betera-tz/pkg/logger"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const authPlace = "authService."
+
+// signatureAlgorithms are the asymmetric algorithms accepted for tokens.
+// Symmetric ones are left out, as a public key set could not verify them.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+type AuthService interface {
+	Authenticate(ctx context.Context, token string) (*models.Caller, error)
+}
+
+type KeySet interface {
+	Key(ctx context.Context, kid string) (*jose.JSONWebKey, error)
+}
+
+type authService struct {
+	ApiKeyService ApiKeyService
+	// KeySet verifies JWTs; nil disables them.
+	KeySet KeySet
+	Logger *logger.Logger
+	Config config.JWTConfig
+}
+
+func NewAuthService(as ApiKeyService, ks *jwks.KeySet, l *logger.Logger, cfg config.JWTConfig) AuthService {
+	service := &authService{
+		ApiKeyService: as,
+		Logger:        l,
+		Config:        cfg,
+	}
+	if ks != nil {
+		service.KeySet = ks
+	}
+	return service
+}
+
+type callerCtxKey struct{}
+
+// WithCaller returns a context carrying the authenticated caller.
+func WithCaller(ctx context.Context, caller *models.Caller) context.Context {
+	return context.WithValue(ctx, callerCtxKey{}, caller)
+}
+
+// CallerFromContext returns the authenticated caller or nil.
+func CallerFromContext(ctx context.Context) *models.Caller {
+	caller, _ := ctx.Value(callerCtxKey{}).(*models.Caller)
+	return caller
+}
+
+// callerID identifies the caller of ctx for logs and records. Work not
+// started by a request, such as workers, has no caller.
+func callerID(ctx context.Context) string {
+	if caller := CallerFromContext(ctx); caller != nil {
+		return caller.ID()
+	}
+	return ""
+}
+
+// Authenticate resolves the caller of a bearer token, which is either a JWT
+// of the identity provider or an API key.
+func (as *authService) Authenticate(ctx context.Context, token string) (*models.Caller, error) {
+	op := authPlace + "Authenticate"
+	if token == "" {
+		return nil, errs.ErrUnauthorized(op)
+	}
+	if as.KeySet != nil && strings.Count(token, ".") == 2 {
+		return as.verifyToken(ctx, token)
+	}
+	key, err := as.ApiKeyService.Authenticate(ctx, token)
+	if err != nil {
+		return nil, errs.NewAppError(op, err)
+	}
+	return &models.Caller{
+		Type:    models.CallerApiKey,
+		Subject: key.ID.String(),
+		Roles:   key.Scopes,
+		Tenant:  key.Tenant,
+	}, nil
+}
+
+// verifyToken checks the signature, issuer, audience and lifetime of a JWT.
+// Tokens without an expiry are rejected so a leaked one cannot live forever.
+func (as *authService) verifyToken(ctx context.Context, token string) (*models.Caller, error) {
+	op := authPlace + "verifyToken"
+	log := as.Logger.AddOp(op)
+	tok, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		log.Info("malformed token", logger.Err(err))
+		return nil, errs.ErrUnauthorized(op)
+	}
+	header := tok.Headers[0]
+	key, err := as.KeySet.Key(ctx, header.KeyID)
+	if err != nil {
+		if errors.Is(err, jwks.ErrUnknownKey) {
+			log.Info("token signed with unknown key", "kid", header.KeyID, logger.Err(err))
+			return nil, errs.ErrUnauthorized(op)
+		}
+		log.Error("failed to receive signing key", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+		log.Info("token algorithm does not match its key", "alg", header.Algorithm)
+		return nil, errs.ErrUnauthorized(op)
+	}
+	claims := jwt.Claims{}
+	extra := map[string]any{}
+	if err := tok.Claims(key.Key, &claims, &extra); err != nil {
+		log.Info("invalid token signature", logger.Err(err))
+		return nil, errs.ErrUnauthorized(op)
+	}
+	expected := jwt.Expected{Issuer: as.Config.Issuer, Time: time.Now()}
+	if as.Config.Audience != "" {
+		expected.AnyAudience = jwt.Audience{as.Config.Audience}
+	}
+	if err := claims.ValidateWithLeeway(expected, as.Config.Leeway); err != nil {
+		log.Info("invalid token claims", logger.Err(err))
+		return nil, errs.ErrUnauthorized(op)
+	}
+	if claims.Expiry == nil || claims.Subject == "" {
+		log.Info("token without expiry or subject")
+		return nil, errs.ErrUnauthorized(op)
+	}
+	caller := &models.Caller{
+		Type:    models.CallerUser,
+		Subject: claims.Subject,
+		Roles:   claimStrings(extra, as.Config.RolesClaim),
+	}
+	if as.Config.TenantClaim != "" {
+		if tenants := claimStrings(extra, as.Config.TenantClaim); len(tenants) > 0 {
+			caller.Tenant = tenants[0]
+		}
+		if caller.Tenant != "" && !models.IsValidTenant(caller.Tenant) {
+			log.Info("token with invalid tenant", "tenant", caller.Tenant)
+			return nil, errs.ErrUnauthorized(op)
+		}
+	}
+	log.Debug("token verified", "subject", caller.Subject, "roles", caller.Roles, "tenant", caller.Tenant)
+	return caller, nil
+}
+
+// claimStrings reads a list of strings at a dot-separated path of the claims.
+// A string value is split on spaces, like the OAuth scope claim.
+func claimStrings(claims map[string]any, path string) []string {
+	var value any = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
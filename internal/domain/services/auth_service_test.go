@@ -0,0 +1,164 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/jwks"
+	"betera-tz/pkg/logger"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthService_Authenticate(t *testing.T) {
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keySet := jwks.NewStaticKeySet(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: signingKey.Public(), KeyID: "key-1", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+	cfg := config.JWTConfig{Issuer: "https://sso.example.com", Audience: "betera-tz", RolesClaim: "realm_access.roles", TenantClaim: "tenant", Leeway: time.Second}
+
+	sign := func(key any, kid string, claims jwt.Claims, extra map[string]any) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, (&jose.SignerOptions{}).WithHeader("kid", kid))
+		assert.NoError(t, err)
+		token, err := jwt.Signed(signer).Claims(claims).Claims(extra).Serialize()
+		assert.NoError(t, err)
+		return token
+	}
+	now := time.Now()
+	valid := jwt.Claims{
+		Issuer:   cfg.Issuer,
+		Subject:  "user-1",
+		Audience: jwt.Audience{"betera-tz"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(now),
+	}
+	roles := map[string]any{"realm_access": map[string]any{"roles": []string{models.ScopeTasksRead, "offline_access"}}}
+	with := func(change func(*jwt.Claims)) jwt.Claims {
+		claims := valid
+		change(&claims)
+		return claims
+	}
+	apiKeyID := uuid.New()
+
+	tests := []struct {
+		name          string
+		token         string
+		mockSetup     func(*MockApiKeyRepository)
+		expectedError bool
+		errorIs       error
+		expected      *models.Caller
+	}{
+		{
+			name:     "valid token",
+			token:    sign(signingKey, "key-1", valid, roles),
+			expected: &models.Caller{Type: models.CallerUser, Subject: "user-1", Roles: []string{models.ScopeTasksRead, "offline_access"}},
+		},
+		{
+			name:     "token without roles",
+			token:    sign(signingKey, "key-1", valid, nil),
+			expected: &models.Caller{Type: models.CallerUser, Subject: "user-1"},
+		},
+		{
+			name:     "token bound to a tenant",
+			token:    sign(signingKey, "key-1", valid, map[string]any{"tenant": "team-a"}),
+			expected: &models.Caller{Type: models.CallerUser, Subject: "user-1", Tenant: "team-a"},
+		},
+		{
+			name:          "token with invalid tenant",
+			token:         sign(signingKey, "key-1", valid, map[string]any{"tenant": "Team A"}),
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:          "expired token",
+			token:         sign(signingKey, "key-1", with(func(c *jwt.Claims) { c.Expiry = jwt.NewNumericDate(now.Add(-time.Minute)) }), roles),
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:          "token without expiry",
+			token:         sign(signingKey, "key-1", with(func(c *jwt.Claims) { c.Expiry = nil }), roles),
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:          "wrong issuer",
+			token:         sign(signingKey, "key-1", with(func(c *jwt.Claims) { c.Issuer = "https://evil.example.com" }), roles),
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:          "wrong audience",
+			token:         sign(signingKey, "key-1", with(func(c *jwt.Claims) { c.Audience = jwt.Audience{"other"} }), roles),
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:          "signed by another key",
+			token:         sign(otherKey, "key-1", valid, roles),
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:          "unknown key id",
+			token:         sign(signingKey, "key-2", valid, roles),
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:          "malformed token",
+			token:         "not.a.token",
+			expectedError: true,
+			errorIs:       errs.ErrUnauthorizedBase,
+		},
+		{
+			name:  "api key",
+			token: "btz_test-token",
+			mockSetup: func(mockRepo *MockApiKeyRepository) {
+				lastUsed := time.Now()
+				mockRepo.On("GetByHash", mock.Anything, hashApiKey("btz_test-token")).
+					Return(&models.ApiKey{ID: apiKeyID, Tenant: "team-a", Scopes: []string{models.ScopeTasksWrite}, LastUsedAt: &lastUsed}, nil)
+			},
+			expected: &models.Caller{Type: models.CallerApiKey, Subject: apiKeyID.String(), Roles: []string{models.ScopeTasksWrite}, Tenant: "team-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockApiKeyRepository)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockRepo)
+			}
+			l := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+			service := NewAuthService(&apiKeyService{ApiKeyRepository: mockRepo, Logger: l}, keySet, l, cfg)
+
+			caller, err := service.Authenticate(context.Background(), tt.token)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
+				assert.Nil(t, caller)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, caller)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/repositories"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"context"
+	"sync"
+	"time"
+)
+
+type EventService interface {
+	Publish(event models.TaskEvent)
+	Subscribe(filter models.EventFilter) *Subscription
+	GetAfter(ctx context.Context, lastId int64, filter models.EventFilter) ([]models.TaskEvent, bool, error)
+	Prune(ctx context.Context) error
+	Close()
+}
+
+// Subscription delivers live events matching its filter. The channel is closed
+// when the subscriber falls behind or the subscription is closed.
+type Subscription struct {
+	Events <-chan models.TaskEvent
+	events chan models.TaskEvent
+	filter models.EventFilter
+	close  func()
+}
+
+func (s *Subscription) Close() {
+	s.close()
+}
+
+type eventService struct {
+	EventRepository repositories.EventRepository
+	Logger          *logger.Logger
+	Config          config.EventsConfig
+
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+func NewEventService(er repositories.EventRepository, l *logger.Logger, cfg config.EventsConfig) EventService {
+	return &eventService{
+		EventRepository: er,
+		Logger:          l,
+		Config:          cfg,
+		subscribers:     map[*Subscription]struct{}{},
+	}
+}
+
+const eventPlace = "eventService."
+
+func (es *eventService) Publish(event models.TaskEvent) {
+	es.mu.RLock()
+	lagging := []*Subscription{}
+	for sub := range es.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			lagging = append(lagging, sub)
+		}
+	}
+	es.mu.RUnlock()
+	for _, sub := range lagging {
+		sub.Close()
+	}
+}
+
+func (es *eventService) Subscribe(filter models.EventFilter) *Subscription {
+	events := make(chan models.TaskEvent, es.Config.BufferSize)
+	sub := &Subscription{
+		Events: events,
+		events: events,
+		filter: filter,
+	}
+	var once sync.Once
+	sub.close = func() {
+		once.Do(func() {
+			es.mu.Lock()
+			delete(es.subscribers, sub)
+			es.mu.Unlock()
+			close(events)
+		})
+	}
+	es.mu.Lock()
+	if es.closed {
+		es.mu.Unlock()
+		close(events)
+		sub.close = func() {}
+		return sub
+	}
+	es.subscribers[sub] = struct{}{}
+	es.mu.Unlock()
+	return sub
+}
+
+// Close ends every subscription so open streams finish before the server
+// shuts down.
+func (es *eventService) Close() {
+	es.mu.Lock()
+	es.closed = true
+	subs := make([]*Subscription, 0, len(es.subscribers))
+	for sub := range es.subscribers {
+		subs = append(subs, sub)
+	}
+	es.mu.Unlock()
+	for _, sub := range subs {
+		sub.Close()
+	}
+}
+
+// GetAfter returns at most ReplayLimit events after lastId, and whether more
+// events follow them.
+func (es *eventService) GetAfter(ctx context.Context, lastId int64, filter models.EventFilter) ([]models.TaskEvent, bool, error) {
+	op := eventPlace + "GetAfter"
+	log := es.Logger.AddOp(op)
+	log.Info("fetching missed events", "last_event_id", lastId)
+	// one event past the limit tells whether the replay is complete
+	events, err := es.EventRepository.GetAfter(ctx, lastId, filter, es.Config.ReplayLimit+1)
+	if err != nil {
+		log.Error("failed to fetch missed events", logger.Err(err))
+		return nil, false, errs.NewAppError(op, err)
+	}
+	more := len(events) > es.Config.ReplayLimit
+	if more {
+		events = events[:es.Config.ReplayLimit]
+	}
+	log.Info("missed events fetched", "amount", len(events), "more", more)
+	return events, more, nil
+}
+
+func (es *eventService) Prune(ctx context.Context) error {
+	op := eventPlace + "Prune"
+	log := es.Logger.AddOp(op)
+	deleted, err := es.EventRepository.DeleteBefore(ctx, time.Now().Add(-es.Config.Retention))
+	if err != nil {
+		log.Error("failed to prune events", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("events pruned", "amount", deleted)
+	return nil
+}
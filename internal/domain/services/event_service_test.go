@@ -0,0 +1,200 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockEventRepository) GetAfter(ctx context.Context, lastId int64, filter models.EventFilter, limit int) ([]models.TaskEvent, error) {
+	args := m.Called(ctx, lastId, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TaskEvent), args.Error(1)
+}
+
+func (m *MockEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func newTestEventService(repo *MockEventRepository, bufferSize int) EventService {
+	logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+	return NewEventService(repo, logger, config.EventsConfig{BufferSize: bufferSize, ReplayLimit: 2, Retention: time.Hour})
+}
+
+func TestEventService_PublishSubscribe(t *testing.T) {
+	taskId := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	otherId := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
+	tests := []struct {
+		name     string
+		filter   models.EventFilter
+		events   []models.TaskEvent
+		expected []int64
+	}{
+		{
+			name:   "tenant only receives everything of the tenant",
+			filter: models.EventFilter{Tenant: testTenant},
+			events: []models.TaskEvent{
+				{ID: 1, TaskID: taskId, TenantID: testTenant, Type: "created", Status: "created"},
+				{ID: 2, TaskID: otherId, TenantID: testTenant, Type: "updated", Status: "done"},
+			},
+			expected: []int64{1, 2},
+		},
+		{
+			name:   "events of other tenants are skipped",
+			filter: models.EventFilter{Tenant: testTenant},
+			events: []models.TaskEvent{
+				{ID: 1, TaskID: taskId, TenantID: "other", Type: "created", Status: "created"},
+				{ID: 2, TaskID: otherId, TenantID: testTenant, Type: "updated", Status: "done"},
+			},
+			expected: []int64{2},
+		},
+		{
+			name:   "filter by task id",
+			filter: models.EventFilter{Tenant: testTenant, TaskID: &taskId},
+			events: []models.TaskEvent{
+				{ID: 1, TaskID: taskId, TenantID: testTenant, Type: "created", Status: "created"},
+				{ID: 2, TaskID: otherId, TenantID: testTenant, Type: "updated", Status: "done"},
+			},
+			expected: []int64{1},
+		},
+		{
+			name:   "filter by status",
+			filter: models.EventFilter{Tenant: testTenant, Status: "done"},
+			events: []models.TaskEvent{
+				{ID: 1, TaskID: taskId, TenantID: testTenant, Type: "created", Status: "created"},
+				{ID: 2, TaskID: otherId, TenantID: testTenant, Type: "updated", Status: "done"},
+			},
+			expected: []int64{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestEventService(new(MockEventRepository), 10)
+			sub := service.Subscribe(tt.filter)
+			defer sub.Close()
+
+			for _, event := range tt.events {
+				service.Publish(event)
+			}
+
+			received := []int64{}
+			for len(received) < len(tt.expected) {
+				select {
+				case event := <-sub.Events:
+					received = append(received, event.ID)
+				case <-time.After(time.Second):
+					t.Fatal("event not received")
+				}
+			}
+			assert.Equal(t, tt.expected, received)
+			assert.Len(t, sub.Events, 0)
+		})
+	}
+}
+
+func TestEventService_LaggingSubscriberClosed(t *testing.T) {
+	service := newTestEventService(new(MockEventRepository), 1)
+	sub := service.Subscribe(models.EventFilter{Tenant: testTenant})
+
+	service.Publish(models.TaskEvent{ID: 1, TenantID: testTenant})
+	service.Publish(models.TaskEvent{ID: 2, TenantID: testTenant})
+
+	event, ok := <-sub.Events
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), event.ID)
+	_, ok = <-sub.Events
+	assert.False(t, ok)
+}
+
+func TestEventService_Close(t *testing.T) {
+	service := newTestEventService(new(MockEventRepository), 1)
+	sub := service.Subscribe(models.EventFilter{})
+
+	service.Close()
+
+	_, ok := <-sub.Events
+	assert.False(t, ok)
+	_, ok = <-service.Subscribe(models.EventFilter{}).Events
+	assert.False(t, ok)
+}
+
+func TestEventService_GetAfter(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockEventRepository)
+		expectedError  bool
+		expectedResult []models.TaskEvent
+		expectedMore   bool
+	}{
+		{
+			name: "successful replay",
+			mockSetup: func(mockRepo *MockEventRepository) {
+				mockRepo.On("GetAfter", mock.Anything, int64(41), models.EventFilter{}, 3).Return([]models.TaskEvent{{ID: 42}}, nil)
+			},
+			expectedError:  false,
+			expectedResult: []models.TaskEvent{{ID: 42}},
+		},
+		{
+			name: "replay limit reached",
+			mockSetup: func(mockRepo *MockEventRepository) {
+				mockRepo.On("GetAfter", mock.Anything, int64(41), models.EventFilter{}, 3).Return([]models.TaskEvent{{ID: 42}, {ID: 43}, {ID: 44}}, nil)
+			},
+			expectedError:  false,
+			expectedResult: []models.TaskEvent{{ID: 42}, {ID: 43}},
+			expectedMore:   true,
+		},
+		{
+			name: "replay limit exactly",
+			mockSetup: func(mockRepo *MockEventRepository) {
+				mockRepo.On("GetAfter", mock.Anything, int64(41), models.EventFilter{}, 3).Return([]models.TaskEvent{{ID: 42}, {ID: 43}}, nil)
+			},
+			expectedError:  false,
+			expectedResult: []models.TaskEvent{{ID: 42}, {ID: 43}},
+		},
+		{
+			name: "repository error",
+			mockSetup: func(mockRepo *MockEventRepository) {
+				mockRepo.On("GetAfter", mock.Anything, int64(41), models.EventFilter{}, 3).Return(nil, errors.New("database error"))
+			},
+			expectedError:  true,
+			expectedResult: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockEventRepository)
+			tt.mockSetup(mockRepo)
+			service := newTestEventService(mockRepo, 1)
+
+			result, more, err := service.GetAfter(context.Background(), 41, models.EventFilter{})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+			assert.Equal(t, tt.expectedMore, more)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/logger"
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// HealthCheck checks a component, returning nil if it is healthy.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthService runs the checks of the liveness and readiness probes. The
+// app is live while its live checks pass, e.g. its workers are running, and
+// ready to serve requests while all its checks pass and it is not draining.
+type HealthService interface {
+	Live(ctx context.Context) models.HealthReport
+	Ready(ctx context.Context) models.HealthReport
+	Drain()
+}
+
+type healthService struct {
+	LiveChecks  []HealthCheck
+	ReadyChecks []HealthCheck
+	Logger      *logger.Logger
+	Config      config.HealthConfig
+	draining    atomic.Bool
+	checks      singleflight.Group
+	mu          sync.Mutex
+	cache       map[string]cachedHealthResult
+}
+
+type cachedHealthResult struct {
+	result    models.HealthResult
+	checkedAt time.Time
+}
+
+func NewHealthService(live, ready []HealthCheck, l *logger.Logger, cfg config.HealthConfig) HealthService {
+	return &healthService{
+		LiveChecks:  live,
+		ReadyChecks: ready,
+		Logger:      l,
+		Config:      cfg,
+		cache:       make(map[string]cachedHealthResult),
+	}
+}
+
+const healthPlace = "healthService."
+
+func (hs *healthService) Live(ctx context.Context) models.HealthReport {
+	return hs.report(ctx, healthPlace+"Live", hs.LiveChecks)
+}
+
+// Ready runs the live checks too, an app that is not live is not ready.
+func (hs *healthService) Ready(ctx context.Context) models.HealthReport {
+	report := hs.report(ctx, healthPlace+"Ready", slices.Concat(hs.LiveChecks, hs.ReadyChecks))
+	if hs.draining.Load() {
+		report.Status = models.HealthDraining
+	}
+	return report
+}
+
+// Drain makes the app report not ready from now on, so load balancers stop
+// sending it requests before it shuts down.
+func (hs *healthService) Drain() {
+	hs.draining.Store(true)
+}
+
+// report runs the checks without a fresh cached result at once. Concurrent
+// probes running the same check share its result, while a probe never waits
+// for checks it does not run, so liveness is not held up by readiness.
+func (hs *healthService) report(ctx context.Context, op string, checks []HealthCheck) models.HealthReport {
+	log := hs.Logger.AddOp(op)
+	results := make([]models.HealthResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = hs.check(ctx, log, check)
+		}()
+	}
+	wg.Wait()
+
+	report := models.HealthReport{Status: models.HealthOk, Checks: make(map[string]models.HealthResult, len(checks))}
+	for i, check := range checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status != models.HealthOk {
+			report.Status = models.HealthFailed
+		}
+	}
+	return report
+}
+
+// check returns the cached result of the check while it is fresh, otherwise
+// runs the check once for all the probes asking for it.
+func (hs *healthService) check(ctx context.Context, log *logger.Logger, check HealthCheck) models.HealthResult {
+	hs.mu.Lock()
+	cached, ok := hs.cache[check.Name]
+	hs.mu.Unlock()
+	if ok && time.Since(cached.checkedAt) < hs.Config.CacheTTL {
+		return cached.result
+	}
+	result, _, _ := hs.checks.Do(check.Name, func() (any, error) {
+		result := hs.run(ctx, check)
+		if result.Status != models.HealthOk {
+			log.Error("health check failed", "check", check.Name, "error", result.Error)
+		}
+		hs.mu.Lock()
+		hs.cache[check.Name] = cachedHealthResult{result: result, checkedAt: time.Now()}
+		hs.mu.Unlock()
+		return result, nil
+	})
+	return result.(models.HealthResult)
+}
+
+// run gives up on a check after the timeout even if the check ignores its
+// context. The check is not cancelled with the probe, as its result is
+// cached for other probes.
+func (hs *healthService) run(ctx context.Context, check HealthCheck) models.HealthResult {
+	ctx = context.WithoutCancel(ctx)
+	if hs.Config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hs.Config.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := models.HealthResult{Status: models.HealthOk, Duration: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = models.HealthFailed
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/logger"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHealthService(live, ready []HealthCheck, cfg config.HealthConfig) HealthService {
+	return NewHealthService(live, ready, logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"}), cfg)
+}
+
+func TestHealthService_Ready(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	hanging := func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+	tests := []struct {
+		name           string
+		live           []HealthCheck
+		ready          []HealthCheck
+		drain          bool
+		expectedStatus string
+		expectedChecks map[string]string
+	}{
+		{
+			name:           "all checks pass",
+			live:           []HealthCheck{{Name: "taskWorker", Check: ok}},
+			ready:          []HealthCheck{{Name: "postgres", Check: ok}, {Name: "kafka", Check: ok}},
+			expectedStatus: models.HealthOk,
+			expectedChecks: map[string]string{"taskWorker": models.HealthOk, "postgres": models.HealthOk, "kafka": models.HealthOk},
+		},
+		{
+			name: "dependency unreachable",
+			live: []HealthCheck{{Name: "taskWorker", Check: ok}},
+			ready: []HealthCheck{{Name: "postgres", Check: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			}}, {Name: "kafka", Check: ok}},
+			expectedStatus: models.HealthFailed,
+			expectedChecks: map[string]string{"taskWorker": models.HealthOk, "postgres": models.HealthFailed, "kafka": models.HealthOk},
+		},
+		{
+			name:           "check over the timeout",
+			ready:          []HealthCheck{{Name: "kafka", Check: hanging}},
+			expectedStatus: models.HealthFailed,
+			expectedChecks: map[string]string{"kafka": models.HealthFailed},
+		},
+		{
+			name:           "draining",
+			ready:          []HealthCheck{{Name: "postgres", Check: ok}},
+			drain:          true,
+			expectedStatus: models.HealthDraining,
+			expectedChecks: map[string]string{"postgres": models.HealthOk},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestHealthService(tt.live, tt.ready, config.HealthConfig{Timeout: 50 * time.Millisecond})
+			if tt.drain {
+				service.Drain()
+			}
+
+			start := time.Now()
+			report := service.Ready(context.Background())
+
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+			assert.Equal(t, tt.expectedStatus, report.Status)
+			checks := map[string]string{}
+			for name, result := range report.Checks {
+				checks[name] = result.Status
+			}
+			assert.Equal(t, tt.expectedChecks, checks)
+		})
+	}
+}
+
+func TestHealthService_Cache(t *testing.T) {
+	var calls atomic.Int32
+	check := func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}
+	service := newTestHealthService([]HealthCheck{{Name: "taskWorker", Check: check}}, nil, config.HealthConfig{CacheTTL: time.Minute})
+
+	live := service.Live(context.Background())
+	ready := service.Ready(context.Background())
+
+	assert.True(t, live.Ok())
+	assert.True(t, ready.Ok())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHealthService_LiveIgnoresDrain(t *testing.T) {
+	service := newTestHealthService(nil, []HealthCheck{{Name: "postgres", Check: func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}}}, config.HealthConfig{})
+	service.Drain()
+
+	report := service.Live(context.Background())
+
+	assert.True(t, report.Ok())
+	assert.Empty(t, report.Checks)
+}
+
+func TestHealthService_LiveNotBlockedByReady(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	slow := func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}
+	ok := func(ctx context.Context) error { return nil }
+	service := newTestHealthService([]HealthCheck{{Name: "taskWorker", Check: ok}}, []HealthCheck{{Name: "kafka", Check: slow}}, config.HealthConfig{})
+
+	go service.Ready(context.Background())
+	<-started
+	done := make(chan models.HealthReport, 1)
+	go func() {
+		done <- service.Live(context.Background())
+	}()
+
+	select {
+	case report := <-done:
+		assert.True(t, report.Ok())
+	case <-time.After(time.Second):
+		t.Fatal("liveness waited for a readiness check")
+	}
+}
+
+func TestHealthService_SharedCheck(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	check := func(ctx context.Context) error {
+		calls.Add(1)
+		<-release
+		return nil
+	}
+	service := newTestHealthService(nil, []HealthCheck{{Name: "postgres", Check: check}}, config.HealthConfig{})
+
+	reports := make(chan models.HealthReport, 2)
+	for range 2 {
+		go func() {
+			reports <- service.Ready(context.Background())
+		}()
+	}
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	assert.True(t, (<-reports).Ok())
+	assert.True(t, (<-reports).Ok())
+	assert.Equal(t, int32(1), calls.Load())
+}
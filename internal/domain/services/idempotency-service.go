@@ -0,0 +1,107 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/repositories"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"cmp"
+	"context"
+	"time"
+)
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, key, fingerprint string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, statusCode int, response []byte) error
+	Release(ctx context.Context, key string) error
+}
+
+type idempotencyService struct {
+	IdempotencyRepository repositories.IdempotencyRepository
+	Logger                *logger.Logger
+	TTL                   time.Duration
+	Lease                 time.Duration
+}
+
+// defaultIdempotencyLease is the lease of a request in progress when the
+// configuration has none.
+const defaultIdempotencyLease = time.Minute
+
+func NewIdempotencyService(ir repositories.IdempotencyRepository, l *logger.Logger, cfg config.IdempotencyConfig) IdempotencyService {
+	return &idempotencyService{
+		IdempotencyRepository: ir,
+		Logger:                l,
+		TTL:                   cfg.TTL,
+		Lease:                 cmp.Or(cfg.Lease, defaultIdempotencyLease),
+	}
+}
+
+const idempotencyPlace = "idempotencyService."
+
+// Begin reserves the key for the caller and returns nil, or returns the stored
+// record when a completed request with the same fingerprint can be replayed.
+// Keys are the caller's own. A request in progress holds the key for the
+// lease only, so the retries of a request that never finished are not
+// blocked until the key expires.
+func (is *idempotencyService) Begin(ctx context.Context, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	op := idempotencyPlace + "Begin"
+	log := is.Logger.AddOp(op)
+	log.Info("reserving idempotency key")
+	v := &validator{}
+	v.maxLength("Idempotency-Key", key, models.IdempotencyKeyMaxLength)
+	if err := v.err(op); err != nil {
+		log.Error("invalid idempotency key", logger.Err(err))
+		return nil, err
+	}
+	caller, now := callerID(ctx), time.Now()
+	reserved, err := is.IdempotencyRepository.Reserve(ctx, caller, key, fingerprint, now.Add(is.Lease), now.Add(is.TTL))
+	if err != nil {
+		log.Error("failed to reserve idempotency key", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	if reserved {
+		log.Info("idempotency key reserved")
+		return nil, nil
+	}
+	record, err := is.IdempotencyRepository.GetByKey(ctx, caller, key)
+	if err != nil {
+		log.Error("failed to receive idempotency key", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	if record.Fingerprint != fingerprint {
+		log.Info("idempotency key reused with different request")
+		return nil, errs.ErrKeyMismatch(op)
+	}
+	if !record.Completed() {
+		log.Info("request with idempotency key is in progress")
+		return nil, errs.ErrInProgress(op)
+	}
+	log.Info("replaying stored response")
+	return record, nil
+}
+
+func (is *idempotencyService) Complete(ctx context.Context, key string, statusCode int, response []byte) error {
+	op := idempotencyPlace + "Complete"
+	log := is.Logger.AddOp(op)
+	log.Info("storing response for idempotency key")
+	if err := is.IdempotencyRepository.Complete(ctx, callerID(ctx), key, statusCode, response); err != nil {
+		log.Error("failed to store response", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("response stored")
+	return nil
+}
+
+// Release frees the key after a failed request so the client can retry it.
+func (is *idempotencyService) Release(ctx context.Context, key string) error {
+	op := idempotencyPlace + "Release"
+	log := is.Logger.AddOp(op)
+	log.Info("releasing idempotency key")
+	if err := is.IdempotencyRepository.Delete(ctx, callerID(ctx), key); err != nil {
+		log.Error("failed to release idempotency key", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("idempotency key released")
+	return nil
+}
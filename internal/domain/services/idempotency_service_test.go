@@ -0,0 +1,196 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"cmp"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, caller, key, fingerprint string, lockedUntil, expiresAt time.Time) (bool, error) {
+	args := m.Called(ctx, caller, key, fingerprint, lockedUntil, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) GetByKey(ctx context.Context, caller, key string) (*models.IdempotencyRecord, error) {
+	args := m.Called(ctx, caller, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, caller, key string, statusCode int, response []byte) error {
+	args := m.Called(ctx, caller, key, statusCode, response)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, caller, key string) error {
+	args := m.Called(ctx, caller, key)
+	return args.Error(0)
+}
+
+// within matches a time d from now, give or take a second.
+func within(d time.Duration) any {
+	return mock.MatchedBy(func(t time.Time) bool {
+		return (time.Until(t) - d).Abs() < time.Second
+	})
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	leaseUntil, expiresAt := within(time.Minute), within(time.Hour)
+	caller := &models.Caller{Type: models.CallerApiKey, Subject: "key-1", Roles: []string{models.ScopeTasksWrite}}
+	stored := &models.IdempotencyRecord{
+		Key:         "key",
+		Fingerprint: "fingerprint",
+		StatusCode:  201,
+		Response:    []byte(`{"id":"550e8400-e29b-41d4-a716-446655440000"}`),
+	}
+	tests := []struct {
+		name           string
+		key            string
+		fingerprint    string
+		mockSetup      func(*MockIdempotencyRepository)
+		expectedError  bool
+		expectedErrIs  error
+		expectedResult *models.IdempotencyRecord
+	}{
+		{
+			name:        "new key reserved",
+			fingerprint: "fingerprint",
+			mockSetup: func(mockRepo *MockIdempotencyRepository) {
+				mockRepo.On("Reserve", mock.Anything, "api_key:key-1", "key", "fingerprint", leaseUntil, expiresAt).Return(true, nil)
+			},
+			expectedError:  false,
+			expectedResult: nil,
+		},
+		{
+			name:        "completed request replayed",
+			fingerprint: "fingerprint",
+			mockSetup: func(mockRepo *MockIdempotencyRepository) {
+				mockRepo.On("Reserve", mock.Anything, "api_key:key-1", "key", "fingerprint", leaseUntil, expiresAt).Return(false, nil)
+				mockRepo.On("GetByKey", mock.Anything, "api_key:key-1", "key").Return(stored, nil)
+			},
+			expectedError:  false,
+			expectedResult: stored,
+		},
+		{
+			name:        "different request with same key",
+			fingerprint: "other",
+			mockSetup: func(mockRepo *MockIdempotencyRepository) {
+				mockRepo.On("Reserve", mock.Anything, "api_key:key-1", "key", "other", leaseUntil, expiresAt).Return(false, nil)
+				mockRepo.On("GetByKey", mock.Anything, "api_key:key-1", "key").Return(stored, nil)
+			},
+			expectedError:  true,
+			expectedErrIs:  errs.ErrKeyMismatchBase,
+			expectedResult: nil,
+		},
+		{
+			name:        "request in progress",
+			fingerprint: "fingerprint",
+			mockSetup: func(mockRepo *MockIdempotencyRepository) {
+				mockRepo.On("Reserve", mock.Anything, "api_key:key-1", "key", "fingerprint", leaseUntil, expiresAt).Return(false, nil)
+				mockRepo.On("GetByKey", mock.Anything, "api_key:key-1", "key").Return(&models.IdempotencyRecord{Key: "key", Fingerprint: "fingerprint"}, nil)
+			},
+			expectedError:  true,
+			expectedErrIs:  errs.ErrInProgressBase,
+			expectedResult: nil,
+		},
+		{
+			name:          "key too long",
+			key:           strings.Repeat("k", models.IdempotencyKeyMaxLength+1),
+			fingerprint:   "fingerprint",
+			mockSetup:     func(mockRepo *MockIdempotencyRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+		{
+			name:        "repository error",
+			fingerprint: "fingerprint",
+			mockSetup: func(mockRepo *MockIdempotencyRepository) {
+				mockRepo.On("Reserve", mock.Anything, "api_key:key-1", "key", "fingerprint", leaseUntil, expiresAt).Return(false, errors.New("database error"))
+			},
+			expectedError:  true,
+			expectedResult: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockIdempotencyRepository)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+			tt.mockSetup(mockRepo)
+
+			service := &idempotencyService{
+				IdempotencyRepository: mockRepo,
+				Logger:                logger,
+				TTL:                   time.Hour,
+				Lease:                 time.Minute,
+			}
+
+			result, err := service.Begin(WithCaller(context.Background(), caller), cmp.Or(tt.key, "key"), tt.fingerprint)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.expectedErrIs != nil {
+					assert.ErrorIs(t, err, tt.expectedErrIs)
+				}
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIdempotencyService_CallerScope(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+	alice := WithCaller(context.Background(), &models.Caller{Type: models.CallerUser, Subject: "alice", Tenant: "team-a"})
+	bob := WithCaller(context.Background(), &models.Caller{Type: models.CallerUser, Subject: "bob", Tenant: "team-a"})
+
+	mockRepo.On("Reserve", mock.Anything, "user:alice", "key", "fingerprint", mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("Reserve", mock.Anything, "user:bob", "key", "fingerprint", mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("Complete", mock.Anything, "user:alice", "key", 201, []byte(`{}`)).Return(nil)
+	mockRepo.On("Delete", mock.Anything, "user:bob", "key").Return(nil)
+
+	service := NewIdempotencyService(mockRepo, logger, config.IdempotencyConfig{TTL: time.Hour})
+
+	// the same key of another caller of the tenant is not replayed
+	for _, ctx := range []context.Context{alice, bob} {
+		record, err := service.Begin(ctx, "key", "fingerprint")
+		assert.NoError(t, err)
+		assert.Nil(t, record)
+	}
+	assert.NoError(t, service.Complete(alice, "key", 201, []byte(`{}`)))
+	assert.NoError(t, service.Release(bob, "key"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestNewIdempotencyService_Lease(t *testing.T) {
+	logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+	service := NewIdempotencyService(new(MockIdempotencyRepository), logger, config.IdempotencyConfig{TTL: time.Hour})
+	assert.Equal(t, defaultIdempotencyLease, service.(*idempotencyService).Lease)
+
+	service = NewIdempotencyService(new(MockIdempotencyRepository), logger, config.IdempotencyConfig{TTL: time.Hour, Lease: 30 * time.Second})
+	assert.Equal(t, 30*time.Second, service.(*idempotencyService).Lease)
+}
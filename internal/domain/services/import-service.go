@@ -0,0 +1,542 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/repositories"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/queue"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// importLineMaxSize bounds a single NDJSON line so a file without line breaks
+// cannot be read into memory at once.
+const importLineMaxSize = 1 << 20
+
+const (
+	interruptedMessage = "import was interrupted"
+	notStartedMessage  = "import was not started"
+	internalMessage    = "import failed due to an internal error"
+)
+
+type ImportService interface {
+	Create(ctx context.Context, format string, enqueue bool, body io.Reader) (*models.Import, error)
+	GetById(ctx context.Context, id uuid.UUID) (*models.Import, error)
+	GetErrors(ctx context.Context, id uuid.UUID, fn func(*models.ImportError) error) error
+	Queue() <-chan ImportJob
+	Process(ctx context.Context, id uuid.UUID) error
+	FailStale(ctx context.Context) (int, error)
+}
+
+// ImportJob is a queued import with the tenant it is processed in.
+type ImportJob struct {
+	ID     uuid.UUID
+	Tenant string
+}
+
+type BatchProducer interface {
+	SendMessages(ctx context.Context, messages ...queue.Message) error
+}
+
+type importService struct {
+	ImportRepository repositories.ImportRepository
+	TaskRepository   repositories.TaskRepository
+	QuotaService     QuotaService
+	Producer         BatchProducer
+	Logger           *logger.Logger
+	Config           config.ImportsConfig
+	queue            chan ImportJob
+}
+
+func NewImportService(ir repositories.ImportRepository, tr repositories.TaskRepository, qs QuotaService, p *queue.Producer, l *logger.Logger, cfg config.ImportsConfig) ImportService {
+	return &importService{
+		ImportRepository: ir,
+		TaskRepository:   tr,
+		QuotaService:     qs,
+		Producer:         p,
+		Logger:           l,
+		Config:           cfg,
+		queue:            make(chan ImportJob, cfg.QueueSize),
+	}
+}
+
+const importPlace = "importService."
+
+// Create stores the uploaded file and queues it for processing. The file is
+// kept on local disk, so the import is processed by the instance that
+// received it.
+func (is *importService) Create(ctx context.Context, format string, enqueue bool, body io.Reader) (*models.Import, error) {
+	op := importPlace + "Create"
+	log := is.Logger.AddOp(op)
+	log.Info("creating import")
+	v := &validator{}
+	v.oneOf("format", format, models.ImportFormats())
+	if err := v.err(op); err != nil {
+		log.Error("invalid import", logger.Err(err))
+		return nil, err
+	}
+	imp := &models.Import{
+		ID:        uuid.New(),
+		Format:    format,
+		Status:    models.ImportPending,
+		Enqueue:   enqueue,
+		CreatedBy: callerID(ctx),
+	}
+	path := is.path(imp)
+	if err := is.save(path, body); err != nil {
+		log.Error("failed to save import file", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	if err := is.ImportRepository.Create(ctx, imp); err != nil {
+		log.Error("failed to create import", logger.Err(err))
+		os.Remove(path)
+		return nil, errs.NewAppError(op, err)
+	}
+	select {
+	case is.queue <- ImportJob{ID: imp.ID, Tenant: models.TenantFromContext(ctx)}:
+	case <-ctx.Done():
+		log.Error("failed to queue import", logger.Err(ctx.Err()), "import_id", imp.ID)
+		os.Remove(path)
+		is.ImportRepository.Finish(context.WithoutCancel(ctx), imp.ID, models.ImportFailed, notStartedMessage)
+		return nil, errs.NewAppError(op, ctx.Err())
+	}
+	log.Info("import created", "import_id", imp.ID)
+	return imp, nil
+}
+
+// save copies the upload to path, rejecting empty files and files larger
+// than the configured limit.
+func (is *importService) save(path string, body io.Reader) error {
+	op := importPlace + "save"
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(file, io.LimitReader(body, is.Config.MaxSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	switch {
+	case err != nil:
+	case n > is.Config.MaxSize:
+		err = errs.ErrTooLarge(op)
+	case n == 0:
+		err = errs.ErrValidation(op, []errs.Violation{{Message: "request body is required"}})
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+func (is *importService) path(imp *models.Import) string {
+	return filepath.Join(is.Config.Dir, imp.ID.String()+"."+imp.Format)
+}
+
+func (is *importService) GetById(ctx context.Context, id uuid.UUID) (*models.Import, error) {
+	op := importPlace + "GetById"
+	log := is.Logger.AddOp(op)
+	log.Info("receiving import by id")
+	imp, err := is.ImportRepository.GetById(ctx, id)
+	if err != nil {
+		log.Error("failed to receive import by id", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("import received")
+	return imp, nil
+}
+
+// GetErrors streams the rejected rows of an import to fn. The error passed to
+// fn is reused between calls and must not be retained.
+func (is *importService) GetErrors(ctx context.Context, id uuid.UUID, fn func(*models.ImportError) error) error {
+	op := importPlace + "GetErrors"
+	log := is.Logger.AddOp(op)
+	log.Info("exporting import errors")
+	if _, err := is.ImportRepository.GetById(ctx, id); err != nil {
+		log.Error("failed to receive import by id", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	if err := is.ImportRepository.GetErrors(ctx, id, fn); err != nil {
+		log.Error("failed to export import errors", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("import errors exported")
+	return nil
+}
+
+func (is *importService) Queue() <-chan ImportJob {
+	return is.queue
+}
+
+// Process reads the file of a queued import and inserts its tasks in batches,
+// saving the progress after every batch. ctx must carry the tenant of the
+// import. Rejected rows are recorded and do
+// not stop the import; only an unreadable file fails it as a whole.
+func (is *importService) Process(ctx context.Context, id uuid.UUID) error {
+	op := importPlace + "Process"
+	log := is.Logger.AddOp(op)
+	log.Info("processing import", "import_id", id)
+	claimed, err := is.ImportRepository.Claim(ctx, id)
+	if err != nil {
+		log.Error("failed to claim import", logger.Err(err), "import_id", id)
+		return errs.NewAppError(op, err)
+	}
+	imp, err := is.ImportRepository.GetById(ctx, id)
+	if err != nil {
+		log.Error("failed to receive import by id", logger.Err(err), "import_id", id)
+		return errs.NewAppError(op, err)
+	}
+	path := is.path(imp)
+	defer os.Remove(path)
+	if !claimed {
+		log.Info("import is no longer pending", "import_id", id, "status", imp.Status)
+		return nil
+	}
+
+	err = is.load(ctx, imp, path)
+	status, message := models.ImportDone, ""
+	if err != nil {
+		status, message = models.ImportFailed, internalMessage
+		var fileErr importFileError
+		switch {
+		case ctx.Err() != nil:
+			message = interruptedMessage
+		case errors.As(err, &fileErr):
+			message = fileErr.message
+		}
+	}
+	if finishErr := is.ImportRepository.Finish(context.WithoutCancel(ctx), id, status, message); finishErr != nil {
+		log.Error("failed to finish import", logger.Err(finishErr), "import_id", id)
+		return errs.NewAppError(op, finishErr)
+	}
+	if err != nil {
+		log.Error("import failed", logger.Err(err), "import_id", id, "processed", imp.ProcessedRows)
+		return errs.NewAppError(op, err)
+	}
+	log.Info("import processed", "import_id", id, "imported", imp.ImportedRows, "failed", imp.FailedRows)
+	return nil
+}
+
+// importBatch gathers the rows read since the last saved progress.
+type importBatch struct {
+	tasks  []models.Task
+	rows   []int
+	errors []models.ImportError
+	read   int
+}
+
+func (is *importService) load(ctx context.Context, imp *models.Import, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader, err := newImportReader(imp.Format, file)
+	if err != nil {
+		return err
+	}
+
+	stored := 0
+	batch := &importBatch{}
+	flush := func() error {
+		if err := is.insert(ctx, imp, batch); err != nil {
+			return err
+		}
+		imp.ProcessedRows += batch.read
+		imp.FailedRows += batch.read - len(batch.tasks)
+		// Only the first MaxErrors errors are kept; the counters stay exact.
+		keep := batch.errors[:min(len(batch.errors), max(is.Config.MaxErrors-stored, 0))]
+		stored += len(keep)
+		if err := is.ImportRepository.SaveProgress(ctx, imp, keep); err != nil {
+			return err
+		}
+		*batch = importBatch{}
+		return nil
+	}
+
+	for {
+		row, input, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr importRowError
+		switch {
+		case errors.As(err, &rowErr):
+			batch.errors = append(batch.errors, models.ImportError{Row: row, Message: rowErr.message})
+		case err != nil:
+			return err
+		default:
+			if violations := validateImportRow(input); len(violations) > 0 {
+				for _, v := range violations {
+					batch.errors = append(batch.errors, models.ImportError{Row: row, Field: v.Field, Message: v.Message})
+				}
+			} else {
+				batch.tasks = append(batch.tasks, input.task(imp.CreatedBy))
+				batch.rows = append(batch.rows, row)
+			}
+		}
+		batch.read++
+		if batch.read >= is.Config.BatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// insert creates the valid tasks of a batch. Tasks skipped because their
+// title is taken or the quota of the tenant is reached move from the task
+// list to the errors of the batch.
+func (is *importService) insert(ctx context.Context, imp *models.Import, batch *importBatch) error {
+	if len(batch.tasks) == 0 {
+		return nil
+	}
+	quota, err := is.QuotaService.Current(ctx)
+	if err != nil {
+		return err
+	}
+	created, tried, err := is.TaskRepository.CreateBatch(ctx, batch.tasks, *quota)
+	if err != nil {
+		return err
+	}
+	imp.ImportedRows += len(created)
+	inserted := make(map[uuid.UUID]struct{}, len(created))
+	for _, id := range created {
+		inserted[id] = struct{}{}
+	}
+	messages := []queue.Message{}
+	tasks := batch.tasks[:0]
+	for i, task := range batch.tasks {
+		if i >= tried {
+			batch.errors = append(batch.errors, models.ImportError{Row: batch.rows[i], Message: quotaMessage})
+			continue
+		}
+		if _, ok := inserted[task.ID]; !ok {
+			batch.errors = append(batch.errors, models.ImportError{Row: batch.rows[i], Field: "title", Message: "already exists"})
+			continue
+		}
+		tasks = append(tasks, task)
+		if imp.Enqueue && task.Status == "created" {
+			messages = append(messages, taskMessage(ctx, task.ID.String()))
+		}
+	}
+	batch.tasks = tasks
+	if len(messages) > 0 {
+		if err := is.Producer.SendMessages(ctx, messages...); err != nil {
+			is.Logger.Error("failed to send imported tasks to queue", logger.Err(err), "import_id", imp.ID)
+		}
+	}
+	slices.SortFunc(batch.errors, func(a, b models.ImportError) int {
+		return a.Row - b.Row
+	})
+	return nil
+}
+
+// FailStale fails imports whose instance stopped before finishing them.
+func (is *importService) FailStale(ctx context.Context) (int, error) {
+	op := importPlace + "FailStale"
+	log := is.Logger.AddOp(op)
+	failed, err := is.ImportRepository.FailStale(ctx, is.Config.StaleAfter, interruptedMessage)
+	if err != nil {
+		log.Error("failed to fail stale imports", logger.Err(err))
+		return 0, errs.NewAppError(op, err)
+	}
+	if failed > 0 {
+		log.Info("stale imports failed", "failed", failed)
+	}
+	return failed, nil
+}
+
+// importRow is a task as read from an import file.
+type importRow struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	CallbackURL string `json:"callbackUrl"`
+}
+
+// task returns the task of the row, created on behalf of the caller who
+// uploaded the import.
+func (ir importRow) task(createdBy string) models.Task {
+	status := ir.Status
+	if status == "" {
+		status = "created"
+	}
+	return models.Task{
+		ID:          uuid.New(),
+		Title:       ir.Title,
+		Description: ir.Description,
+		Status:      status,
+		CallbackURL: ir.CallbackURL,
+		CreatedBy:   createdBy,
+	}
+}
+
+// validateImportRow applies the rules of task creation to a row. The status
+// is optional and defaults to created.
+func validateImportRow(row importRow) []errs.Violation {
+	v := &validator{}
+	if v.required("title", row.Title) {
+		v.maxLength("title", row.Title, models.TitleMaxLength)
+	}
+	if v.required("description", row.Description) {
+		v.maxLength("description", row.Description, models.DescriptionMaxLength)
+	}
+	if row.Status != "" {
+		v.oneOf("status", row.Status, models.Statuses())
+	}
+	if row.CallbackURL != "" && v.maxLength("callbackUrl", row.CallbackURL, models.URLMaxLength) {
+		v.url("callbackUrl", row.CallbackURL)
+	}
+	return v.violations
+}
+
+// importFileError fails a whole import whose file cannot be read. Its message
+// is shown to the client.
+type importFileError struct {
+	message string
+}
+
+func (e importFileError) Error() string {
+	return e.message
+}
+
+// importRowError rejects a single row that could not be parsed.
+type importRowError struct {
+	message string
+}
+
+func (e importRowError) Error() string {
+	return e.message
+}
+
+// importReader reads the rows of an import file. next returns the line
+// number of the row, an importRowError for a malformed row and io.EOF at the
+// end of the file.
+type importReader interface {
+	next() (int, importRow, error)
+}
+
+func newImportReader(format string, r io.Reader) (importReader, error) {
+	if format == models.ImportFormatNDJSON {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), importLineMaxSize)
+		return &ndjsonImportReader{scanner: scanner}, nil
+	}
+	return newCSVImportReader(r)
+}
+
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (nr *ndjsonImportReader) next() (int, importRow, error) {
+	for nr.scanner.Scan() {
+		nr.line++
+		line := bytes.TrimSpace(nr.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		row := importRow{}
+		if err := json.Unmarshal(line, &row); err != nil {
+			return nr.line, row, importRowError{message: "is not a valid task object"}
+		}
+		return nr.line, row, nil
+	}
+	if err := nr.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nr.line + 1, importRow{}, importFileError{message: fmt.Sprintf("line %d is longer than %d bytes", nr.line+1, importLineMaxSize)}
+		}
+		return nr.line, importRow{}, err
+	}
+	return nr.line, importRow{}, io.EOF
+}
+
+// csvColumns are the columns an import file may have. Columns are matched by
+// the header row, so their order is free and unknown columns are ignored.
+var csvColumns = []string{"title", "description", "status", "callbackUrl"}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, importFileError{message: "file has no header row"}
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, importFileError{message: "header row is malformed: " + parseErr.Err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		for _, column := range csvColumns {
+			if strings.EqualFold(name, column) {
+				columns[column] = i
+			}
+		}
+	}
+	missing := []string{}
+	for _, column := range []string{"title", "description"} {
+		if _, ok := columns[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, importFileError{message: "header row is missing columns: " + strings.Join(missing, ", ")}
+	}
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (cr *csvImportReader) next() (int, importRow, error) {
+	record, err := cr.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.StartLine, importRow{}, importRowError{message: parseErr.Err.Error()}
+	}
+	if err != nil {
+		return 0, importRow{}, err
+	}
+	line, _ := cr.reader.FieldPos(0)
+	field := func(column string) string {
+		if i, ok := cr.columns[column]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	return line, importRow{
+		Title:       field("title"),
+		Description: field("description"),
+		Status:      field("status"),
+		CallbackURL: field("callbackUrl"),
+	}, nil
+}
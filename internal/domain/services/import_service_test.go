@@ -0,0 +1,326 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/queue"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportRepository) Create(ctx context.Context, imp *models.Import) error {
+	args := m.Called(ctx, imp)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) GetById(ctx context.Context, id uuid.UUID) (*models.Import, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Import), args.Error(1)
+}
+
+func (m *MockImportRepository) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockImportRepository) SaveProgress(ctx context.Context, imp *models.Import, importErrors []models.ImportError) error {
+	args := m.Called(ctx, imp, importErrors)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) Finish(ctx context.Context, id uuid.UUID, status, message string) error {
+	args := m.Called(ctx, id, status, message)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) FailStale(ctx context.Context, staleAfter time.Duration, message string) (int, error) {
+	args := m.Called(ctx, staleAfter, message)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockImportRepository) GetErrors(ctx context.Context, id uuid.UUID, fn func(*models.ImportError) error) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockBatchProducer struct {
+	mock.Mock
+}
+
+func (m *MockBatchProducer) SendMessages(ctx context.Context, messages ...queue.Message) error {
+	args := m.Called(messages)
+	return args.Error(0)
+}
+
+func newTestImportService(t *testing.T, ir *MockImportRepository, tr *MockTaskRepository, p *MockBatchProducer) *importService {
+	return &importService{
+		ImportRepository: ir,
+		TaskRepository:   tr,
+		QuotaService:     defaultQuotas(config.QuotasConfig{}),
+		Producer:         p,
+		Logger:           logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"}),
+		Config: config.ImportsConfig{
+			Dir:       t.TempDir(),
+			MaxSize:   1024,
+			BatchSize: 2,
+			MaxErrors: 100,
+		},
+		queue: make(chan ImportJob, 1),
+	}
+}
+
+func TestImportService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		body          string
+		mockSetup     func(*MockImportRepository)
+		expectedError bool
+		errorIs       error
+	}{
+		{
+			name:   "successful import creation",
+			format: "csv",
+			body:   "title,description\nTask,Description\n",
+			mockSetup: func(mockRepo *MockImportRepository) {
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(imp *models.Import) bool {
+					return imp.Format == "csv" && imp.Status == models.ImportPending && imp.Enqueue
+				})).Return(nil)
+			},
+		},
+		{
+			name:          "unknown format",
+			format:        "xml",
+			body:          "<tasks/>",
+			mockSetup:     func(mockRepo *MockImportRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "empty file",
+			format:        "ndjson",
+			mockSetup:     func(mockRepo *MockImportRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "file too large",
+			format:        "ndjson",
+			body:          strings.Repeat("x", 1025),
+			mockSetup:     func(mockRepo *MockImportRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrTooLargeBase,
+		},
+		{
+			name:   "repository error",
+			format: "csv",
+			body:   "title,description\n",
+			mockSetup: func(mockRepo *MockImportRepository) {
+				mockRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("database error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockImportRepository)
+			tt.mockSetup(mockRepo)
+			service := newTestImportService(t, mockRepo, new(MockTaskRepository), new(MockBatchProducer))
+
+			imp, err := service.Create(tenantCtx(), tt.format, true, strings.NewReader(tt.body))
+
+			files, _ := os.ReadDir(service.Config.Dir)
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
+				assert.Nil(t, imp)
+				assert.Empty(t, files)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, ImportJob{ID: imp.ID, Tenant: testTenant}, <-service.Queue())
+				content, _ := os.ReadFile(filepath.Join(service.Config.Dir, imp.ID.String()+".csv"))
+				assert.Equal(t, tt.body, string(content))
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportService_Process(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          string
+		content         string
+		enqueue         bool
+		taken           []string
+		maxActive       int
+		expectedStatus  string
+		expectedMessage string
+		expectedImport  models.Import
+		expectedErrors  []models.ImportError
+		expectedQueued  int
+	}{
+		{
+			name:   "csv with rejected rows",
+			format: "csv",
+			content: "Description,Title,Status\n" +
+				"First description,First,\n" +
+				",Second,\n" +
+				"Third description,Third,archived\n" +
+				"Fourth description,Taken,done\n" +
+				"\"Fifth \"description,Fifth,\n",
+			enqueue:        true,
+			taken:          []string{"Taken"},
+			expectedStatus: models.ImportDone,
+			expectedImport: models.Import{ProcessedRows: 5, ImportedRows: 1, FailedRows: 4},
+			expectedErrors: []models.ImportError{
+				{Row: 3, Field: "description", Message: "is required"},
+				{Row: 4, Field: "status", Message: "must be one of created, processing, done"},
+				{Row: 5, Field: "title", Message: "already exists"},
+				{Row: 6, Message: `extraneous or missing " in quoted-field`},
+			},
+			expectedQueued: 1,
+		},
+		{
+			name:   "csv over the quota",
+			format: "csv",
+			content: "title,description\n" +
+				"First,First description\n" +
+				"Taken,Taken description\n" +
+				"Second,Second description\n" +
+				"Third,Third description\n",
+			taken:          []string{"Taken"},
+			maxActive:      2,
+			expectedStatus: models.ImportDone,
+			expectedImport: models.Import{ProcessedRows: 4, ImportedRows: 2, FailedRows: 2},
+			expectedErrors: []models.ImportError{
+				{Row: 3, Field: "title", Message: "already exists"},
+				{Row: 5, Message: quotaMessage},
+			},
+		},
+		{
+			name:   "ndjson with malformed line",
+			format: "ndjson",
+			content: `{"title":"First","description":"First description","status":"done"}` + "\n\n" +
+				`{"title":` + "\n" +
+				`{"title":"Second","description":"Second description"}` + "\n",
+			expectedStatus: models.ImportDone,
+			expectedImport: models.Import{ProcessedRows: 3, ImportedRows: 2, FailedRows: 1},
+			expectedErrors: []models.ImportError{
+				{Row: 3, Message: "is not a valid task object"},
+			},
+		},
+		{
+			name:            "csv without required columns",
+			format:          "csv",
+			content:         "name,details\nFirst,First description\n",
+			expectedStatus:  models.ImportFailed,
+			expectedMessage: "header row is missing columns: title, description",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockImportRepo := new(MockImportRepository)
+			mockTaskRepo := new(MockTaskRepository)
+			mockProducer := new(MockBatchProducer)
+			service := newTestImportService(t, mockImportRepo, mockTaskRepo, mockProducer)
+			imp := &models.Import{ID: uuid.New(), Format: tt.format, Status: models.ImportProcessing, Enqueue: tt.enqueue, CreatedBy: "user:1b2c"}
+			path := filepath.Join(service.Config.Dir, imp.ID.String()+"."+tt.format)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			importErrors := []models.ImportError{}
+			mockImportRepo.On("Claim", mock.Anything, imp.ID).Return(true, nil)
+			mockImportRepo.On("GetById", mock.Anything, imp.ID).Return(imp, nil)
+			mockImportRepo.On("SaveProgress", mock.Anything, imp, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				importErrors = append(importErrors, args.Get(2).([]models.ImportError)...)
+			}).Maybe()
+			mockImportRepo.On("Finish", mock.Anything, imp.ID, tt.expectedStatus, tt.expectedMessage).Return(nil)
+			service.QuotaService = defaultQuotas(config.QuotasConfig{MaxActive: tt.maxActive})
+			active := 0
+			mockTaskRepo.On("CreateBatch", mock.Anything, mock.Anything, mock.Anything).Return(func(tasks []models.Task, quota models.Quota) ([]uuid.UUID, int) {
+				tried := len(tasks)
+				if quota.MaxActive > 0 {
+					tried = min(tried, quota.MaxActive-active)
+				}
+				created := []uuid.UUID{}
+				for _, task := range tasks[:tried] {
+					assert.Equal(t, imp.CreatedBy, task.CreatedBy)
+					if !slices.Contains(tt.taken, task.Title) {
+						created = append(created, task.ID)
+					}
+				}
+				active += len(created)
+				return created, tried
+			}, nil).Maybe()
+			queued := 0
+			mockProducer.On("SendMessages", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				for _, message := range args.Get(0).([]queue.Message) {
+					assert.Equal(t, testTenant, message.Headers[TenantHeader])
+					queued++
+				}
+			}).Maybe()
+
+			err := service.Process(tenantCtx(), imp.ID)
+
+			if tt.expectedStatus == models.ImportFailed {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedImport.ProcessedRows, imp.ProcessedRows)
+				assert.Equal(t, tt.expectedImport.ImportedRows, imp.ImportedRows)
+				assert.Equal(t, tt.expectedImport.FailedRows, imp.FailedRows)
+				assert.Equal(t, tt.expectedErrors, importErrors)
+			}
+			assert.Equal(t, tt.expectedQueued, queued)
+			_, statErr := os.Stat(path)
+			assert.True(t, os.IsNotExist(statErr))
+
+			mockImportRepo.AssertExpectations(t)
+			mockTaskRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportService_ProcessNotClaimed(t *testing.T) {
+	mockImportRepo := new(MockImportRepository)
+	mockTaskRepo := new(MockTaskRepository)
+	service := newTestImportService(t, mockImportRepo, mockTaskRepo, new(MockBatchProducer))
+	imp := &models.Import{ID: uuid.New(), Format: "csv", Status: models.ImportFailed}
+	path := filepath.Join(service.Config.Dir, imp.ID.String()+".csv")
+	assert.NoError(t, os.WriteFile(path, []byte("title,description\nTask,Description\n"), 0o600))
+
+	mockImportRepo.On("Claim", mock.Anything, imp.ID).Return(false, nil)
+	mockImportRepo.On("GetById", mock.Anything, imp.ID).Return(imp, nil)
+
+	err := service.Process(context.Background(), imp.ID)
+
+	assert.NoError(t, err)
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr))
+	mockImportRepo.AssertExpectations(t)
+	mockTaskRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything, mock.Anything)
+}
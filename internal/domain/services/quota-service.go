@@ -0,0 +1,162 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/repositories"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"context"
+	"errors"
+)
+
+const (
+	quotaPlace = "quotaService."
+	// quotaMessage is the error of import rows left out by the quota.
+	quotaMessage = "exceeds the task quota of the tenant"
+)
+
+type QuotaService interface {
+	Current(ctx context.Context) (*models.Quota, error)
+	Get(ctx context.Context, tenant string) (*models.Quota, *models.QuotaUsage, error)
+	List(ctx context.Context) ([]models.Quota, error)
+	Set(ctx context.Context, quota models.Quota) (*models.Quota, error)
+	Delete(ctx context.Context, tenant string) error
+}
+
+type quotaService struct {
+	QuotaRepository repositories.QuotaRepository
+	Logger          *logger.Logger
+	Config          config.QuotasConfig
+}
+
+func NewQuotaService(qr repositories.QuotaRepository, l *logger.Logger, cfg config.QuotasConfig) QuotaService {
+	return &quotaService{
+		QuotaRepository: qr,
+		Logger:          l,
+		Config:          cfg,
+	}
+}
+
+// Current returns the quota of the tenant of ctx.
+func (qs *quotaService) Current(ctx context.Context) (*models.Quota, error) {
+	op := quotaPlace + "Current"
+	quota, err := qs.quota(ctx, models.TenantFromContext(ctx))
+	if err != nil {
+		qs.Logger.AddOp(op).Error("failed to receive quota", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	return quota, nil
+}
+
+// Get returns the quota of tenant, the default one when it has none of its
+// own, with what the tenant uses of it. Quotas of any tenant are managed by
+// callers bound to no tenant only, so a tenant cannot read others' quotas or
+// lift its own.
+func (qs *quotaService) Get(ctx context.Context, tenant string) (*models.Quota, *models.QuotaUsage, error) {
+	op := quotaPlace + "Get"
+	log := qs.Logger.AddOp(op)
+	log.Info("receiving quota", "tenant", tenant)
+	if err := requireUnbound(ctx, op); err != nil {
+		return nil, nil, err
+	}
+	if err := validateQuotaTenant(op, tenant); err != nil {
+		return nil, nil, err
+	}
+	quota, err := qs.quota(ctx, tenant)
+	if err != nil {
+		log.Error("failed to receive quota", logger.Err(err))
+		return nil, nil, errs.NewAppError(op, err)
+	}
+	usage, err := qs.QuotaRepository.Usage(ctx, tenant)
+	if err != nil {
+		log.Error("failed to receive quota usage", logger.Err(err))
+		return nil, nil, errs.NewAppError(op, err)
+	}
+	log.Info("quota received")
+	return quota, usage, nil
+}
+
+// List returns the tenants with a quota of their own.
+func (qs *quotaService) List(ctx context.Context) ([]models.Quota, error) {
+	op := quotaPlace + "List"
+	log := qs.Logger.AddOp(op)
+	log.Info("fetching quotas")
+	if err := requireUnbound(ctx, op); err != nil {
+		return nil, err
+	}
+	quotas, err := qs.QuotaRepository.List(ctx)
+	if err != nil {
+		log.Error("failed to fetch quotas", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("quotas fetched")
+	return quotas, nil
+}
+
+// Set gives quota.Tenant a quota of its own in place of the default one.
+// Lowering a quota does not touch the tasks the tenant already has.
+func (qs *quotaService) Set(ctx context.Context, quota models.Quota) (*models.Quota, error) {
+	op := quotaPlace + "Set"
+	log := qs.Logger.AddOp(op)
+	log.Info("setting quota", "tenant", quota.Tenant, "caller", callerID(ctx))
+	if err := requireUnbound(ctx, op); err != nil {
+		return nil, err
+	}
+	v := &validator{}
+	if v.required("tenant", quota.Tenant) && !models.IsValidTenant(quota.Tenant) {
+		v.add("tenant", tenantMessage)
+	}
+	v.nonNegative(models.QuotaMaxActive, quota.MaxActive)
+	v.nonNegative(models.QuotaMaxProcessing, quota.MaxProcessing)
+	v.nonNegative(models.QuotaMaxDaily, quota.MaxDaily)
+	if err := v.err(op); err != nil {
+		log.Error("invalid quota", logger.Err(err))
+		return nil, err
+	}
+	if err := qs.QuotaRepository.Set(ctx, &quota); err != nil {
+		log.Error("failed to set quota", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("quota set")
+	return &quota, nil
+}
+
+// Delete returns tenant to the default quota.
+func (qs *quotaService) Delete(ctx context.Context, tenant string) error {
+	op := quotaPlace + "Delete"
+	log := qs.Logger.AddOp(op)
+	log.Info("deleting quota", "tenant", tenant, "caller", callerID(ctx))
+	if err := requireUnbound(ctx, op); err != nil {
+		return err
+	}
+	if err := validateQuotaTenant(op, tenant); err != nil {
+		return err
+	}
+	if err := qs.QuotaRepository.Delete(ctx, tenant); err != nil {
+		log.Error("failed to delete quota", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("quota deleted")
+	return nil
+}
+
+func (qs *quotaService) quota(ctx context.Context, tenant string) (*models.Quota, error) {
+	quota, err := qs.QuotaRepository.Get(ctx, tenant)
+	if errors.Is(err, errs.ErrNotFoundBase) {
+		return &models.Quota{
+			Tenant:        tenant,
+			MaxActive:     qs.Config.MaxActive,
+			MaxProcessing: qs.Config.MaxProcessing,
+			MaxDaily:      qs.Config.MaxDaily,
+		}, nil
+	}
+	return quota, err
+}
+
+func validateQuotaTenant(op, tenant string) error {
+	if !models.IsValidTenant(tenant) {
+		return errs.ErrValidation(op, []errs.Violation{{Field: "tenant", Message: tenantMessage}})
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQuotaRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuotaRepository) Get(ctx context.Context, tenant string) (*models.Quota, error) {
+	args := m.Called(ctx, tenant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Quota), args.Error(1)
+}
+
+func (m *MockQuotaRepository) List(ctx context.Context) ([]models.Quota, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Quota), args.Error(1)
+}
+
+func (m *MockQuotaRepository) Set(ctx context.Context, quota *models.Quota) error {
+	args := m.Called(ctx, quota)
+	return args.Error(0)
+}
+
+func (m *MockQuotaRepository) Delete(ctx context.Context, tenant string) error {
+	args := m.Called(ctx, tenant)
+	return args.Error(0)
+}
+
+func (m *MockQuotaRepository) Usage(ctx context.Context, tenant string) (*models.QuotaUsage, error) {
+	args := m.Called(ctx, tenant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuotaUsage), args.Error(1)
+}
+
+func newTestQuotaService(mockRepo *MockQuotaRepository, cfg config.QuotasConfig) *quotaService {
+	return &quotaService{
+		QuotaRepository: mockRepo,
+		Logger:          logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"}),
+		Config:          cfg,
+	}
+}
+
+// defaultQuotas returns a quota service giving every tenant the quota of cfg.
+func defaultQuotas(cfg config.QuotasConfig) *quotaService {
+	mockRepo := new(MockQuotaRepository)
+	mockRepo.On("Get", mock.Anything, mock.Anything).Return(nil, errs.ErrNotFound("test"))
+	return newTestQuotaService(mockRepo, cfg)
+}
+
+func TestQuotaService_Current(t *testing.T) {
+	updatedAt := time.Now()
+	tests := []struct {
+		name          string
+		mockSetup     func(*MockQuotaRepository)
+		expected      *models.Quota
+		expectedError bool
+	}{
+		{
+			name: "tenant with its own quota",
+			mockSetup: func(mockRepo *MockQuotaRepository) {
+				mockRepo.On("Get", mock.Anything, testTenant).Return(&models.Quota{Tenant: testTenant, MaxActive: 10, UpdatedAt: &updatedAt}, nil)
+			},
+			expected: &models.Quota{Tenant: testTenant, MaxActive: 10, UpdatedAt: &updatedAt},
+		},
+		{
+			name: "tenant with the default quota",
+			mockSetup: func(mockRepo *MockQuotaRepository) {
+				mockRepo.On("Get", mock.Anything, testTenant).Return(nil, errs.ErrNotFound("test"))
+			},
+			expected: &models.Quota{Tenant: testTenant, MaxActive: 100, MaxProcessing: 5, MaxDaily: 1000},
+		},
+		{
+			name: "repository error",
+			mockSetup: func(mockRepo *MockQuotaRepository) {
+				mockRepo.On("Get", mock.Anything, testTenant).Return(nil, errors.New("database error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuotaRepository)
+			tt.mockSetup(mockRepo)
+			service := newTestQuotaService(mockRepo, config.QuotasConfig{MaxActive: 100, MaxProcessing: 5, MaxDaily: 1000})
+
+			quota, err := service.Current(tenantCtx())
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, quota)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, quota)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestQuotaService_Set(t *testing.T) {
+	tests := []struct {
+		name               string
+		quota              models.Quota
+		mockSetup          func(*MockQuotaRepository)
+		expectedViolations []errs.Violation
+	}{
+		{
+			name:  "valid quota",
+			quota: models.Quota{Tenant: testTenant, MaxActive: 10, MaxProcessing: 2, MaxDaily: 0},
+			mockSetup: func(mockRepo *MockQuotaRepository) {
+				mockRepo.On("Set", mock.Anything, mock.MatchedBy(func(quota *models.Quota) bool {
+					return quota.Tenant == testTenant && quota.MaxActive == 10 && quota.MaxProcessing == 2
+				})).Return(nil)
+			},
+		},
+		{
+			name:      "negative limits and invalid tenant",
+			quota:     models.Quota{Tenant: "Team A", MaxActive: -1, MaxDaily: -5},
+			mockSetup: func(mockRepo *MockQuotaRepository) {},
+			expectedViolations: []errs.Violation{
+				{Field: "tenant", Message: tenantMessage},
+				{Field: models.QuotaMaxActive, Message: "must not be negative"},
+				{Field: models.QuotaMaxDaily, Message: "must not be negative"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuotaRepository)
+			tt.mockSetup(mockRepo)
+			service := newTestQuotaService(mockRepo, config.QuotasConfig{})
+
+			quota, err := service.Set(context.Background(), tt.quota)
+
+			if tt.expectedViolations != nil {
+				var ve errs.ValidationError
+				assert.True(t, errors.As(err, &ve))
+				assert.Equal(t, tt.expectedViolations, ve.Violations)
+				assert.Nil(t, quota)
+				mockRepo.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.quota.Tenant, quota.Tenant)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestQuotaService_Get(t *testing.T) {
+	mockRepo := new(MockQuotaRepository)
+	mockRepo.On("Get", mock.Anything, testTenant).Return(nil, errs.ErrNotFound("test"))
+	mockRepo.On("Usage", mock.Anything, testTenant).Return(&models.QuotaUsage{Active: 3, Processing: 1, CreatedToday: 7}, nil)
+	service := newTestQuotaService(mockRepo, config.QuotasConfig{MaxDaily: 50})
+
+	quota, usage, err := service.Get(context.Background(), testTenant)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Quota{Tenant: testTenant, MaxDaily: 50}, quota)
+	assert.Equal(t, &models.QuotaUsage{Active: 3, Processing: 1, CreatedToday: 7}, usage)
+	mockRepo.AssertExpectations(t)
+
+	_, _, err = service.Get(context.Background(), "../etc")
+	assert.ErrorIs(t, err, errs.ErrInvalidValuesBase)
+}
+
+func TestQuotaService_Delete(t *testing.T) {
+	mockRepo := new(MockQuotaRepository)
+	mockRepo.On("Delete", mock.Anything, testTenant).Return(errs.ErrNotFound("test"))
+	service := newTestQuotaService(mockRepo, config.QuotasConfig{})
+
+	err := service.Delete(context.Background(), testTenant)
+
+	assert.ErrorIs(t, err, errs.ErrNotFoundBase)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestQuotaService_TenantBoundCaller(t *testing.T) {
+	mockRepo := new(MockQuotaRepository)
+	service := newTestQuotaService(mockRepo, config.QuotasConfig{})
+	ctx := WithCaller(context.Background(), &models.Caller{Type: models.CallerApiKey, Subject: "team-a-admin", Roles: []string{models.ScopeAdmin}, Tenant: testTenant})
+
+	// neither another tenant's quota nor its own
+	for _, tenant := range []string{"team-b", testTenant} {
+		_, _, err := service.Get(ctx, tenant)
+		assert.ErrorIs(t, err, errs.ErrForbiddenBase)
+		_, err = service.Set(ctx, models.Quota{Tenant: tenant, MaxActive: 1000})
+		assert.ErrorIs(t, err, errs.ErrForbiddenBase)
+		assert.ErrorIs(t, service.Delete(ctx, tenant), errs.ErrForbiddenBase)
+	}
+	_, err := service.List(ctx)
+	assert.ErrorIs(t, err, errs.ErrForbiddenBase)
+	mockRepo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "List", mock.Anything)
+}
@@ -7,64 +7,97 @@ import (
 	"betera-tz/pkg/logger"
 	"betera-tz/pkg/queue"
 	"context"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type TaskService interface {
-	Create(ctx context.Context, title, description string) (*uuid.UUID, error)
+	Create(ctx context.Context, title, description, callbackURL string) (*uuid.UUID, error)
 	GetById(ctx context.Context, id string) (*models.Task, error)
-	Get(ctx context.Context, amount, page int, statusFilter string) ([]models.Task, error)
-	UpdateStatus(ctx context.Context, id, status string) error
+	Get(ctx context.Context, amount, page int, filter models.TaskFilter) ([]models.Task, error)
+	UpdateStatus(ctx context.Context, id, status string, version *int) error
+	Assign(ctx context.Context, id, assignee string, version *int) (*models.Task, error)
+	BulkUpdateStatus(ctx context.Context, selection models.BulkSelection, status string, dryRun bool) (*models.BulkResult, error)
+	BulkDelete(ctx context.Context, selection models.BulkSelection, dryRun bool) (*models.BulkResult, error)
+	WaitForStatus(ctx context.Context, id string, statuses []string, timeout time.Duration) (*models.Task, bool, error)
+	Export(ctx context.Context, filter models.TaskFilter, fn func(*models.Task) error) error
+	Stats(ctx context.Context, window, bucket time.Duration) (*models.TaskStats, error)
 }
 
 type MessageProducer interface {
-	SendMessage(message queue.Message) error
+	SendMessage(ctx context.Context, message queue.Message) error
 }
 
 type taskService struct {
 	TaskRepository repositories.TaskRepository
+	QuotaService   QuotaService
 	Producer       MessageProducer
+	EventService   EventService
 	Logger         *logger.Logger
 }
 
-func NewTaskService(tr repositories.TaskRepository, l *logger.Logger, p *queue.Producer) TaskService {
+func NewTaskService(tr repositories.TaskRepository, qs QuotaService, l *logger.Logger, p *queue.Producer, es EventService) TaskService {
 	return &taskService{
 		TaskRepository: tr,
+		QuotaService:   qs,
 		Producer:       p,
+		EventService:   es,
 		Logger:         l,
 	}
 }
 
-const place = "taskService."
+const (
+	place         = "taskService."
+	bulkBatchSize = 100
 
-func (ts *taskService) Create(ctx context.Context, title, description string) (*uuid.UUID, error) {
+	statsDefaultWindow = 24 * time.Hour
+	statsDefaultBucket = time.Hour
+	statsMaxWindow     = 90 * 24 * time.Hour
+	statsMinBucket     = time.Minute
+	statsMaxBuckets    = 1000
+)
+
+func (ts *taskService) Create(ctx context.Context, title, description, callbackURL string) (*uuid.UUID, error) {
 	op := place + "Create"
 	log := ts.Logger.AddOp(op)
-	log.Info("creating task")
+	log.Info("creating task", "caller", callerID(ctx))
+	v := &validator{}
+	if v.required("title", title) {
+		v.maxLength("title", title, models.TitleMaxLength)
+	}
+	if v.required("description", description) {
+		v.maxLength("description", description, models.DescriptionMaxLength)
+	}
+	if callbackURL != "" && v.maxLength("callbackUrl", callbackURL, models.URLMaxLength) {
+		v.url("callbackUrl", callbackURL)
+	}
+	if err := v.err(op); err != nil {
+		log.Error("invalid task", logger.Err(err))
+		return nil, err
+	}
 	task := &models.Task{
 		ID:          uuid.New(),
 		Title:       title,
 		Description: description,
 		Status:      "created",
+		CallbackURL: callbackURL,
+		CreatedBy:   callerID(ctx),
+	}
+	quota, err := ts.QuotaService.Current(ctx)
+	if err != nil {
+		log.Error("failed to receive quota", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
 	}
-	id, err := ts.TaskRepository.Create(ctx, task)
+	id, err := ts.TaskRepository.Create(ctx, task, *quota)
 	if err != nil {
 		log.Error("failed to create task", logger.Err(err))
 		return nil, errs.NewAppError(op, err)
 	}
 
-	msg := queue.Message{
-		Key:   *id,
-		Value: []byte(*id),
-		Time:  time.Now(),
-	}
-	if err := ts.Producer.SendMessage(msg); err != nil {
-		log.Error("failed to send task to queue", logger.Err(err))
-	} else {
-		log.Info("task sent to queue", "task_id", *id)
-	}
+	ts.enqueue(ctx, log, *id)
 
 	uid, err := uuid.Parse(*id)
 	if err != nil {
@@ -88,15 +121,21 @@ func (ts *taskService) GetById(ctx context.Context, id string) (*models.Task, er
 	return task, nil
 }
 
-func (ts *taskService) Get(ctx context.Context, amount, page int, statusFilter string) ([]models.Task, error) {
+func (ts *taskService) Get(ctx context.Context, amount, page int, filter models.TaskFilter) ([]models.Task, error) {
 	op := place + "Get"
 	log := ts.Logger.AddOp(op)
 	log.Info("fetching tasks")
+	v := &validator{}
+	resolveActors(ctx, v, "", &filter)
+	if err := v.err(op); err != nil {
+		log.Error("invalid task filter", logger.Err(err))
+		return nil, err
+	}
 	if amount <= 0 || page <= 0 {
 		page = -1
 		amount = -1
 	}
-	tasks, err := ts.TaskRepository.Get(ctx, amount, page, statusFilter)
+	tasks, err := ts.TaskRepository.Get(ctx, amount, page, filter)
 	if err != nil {
 		log.Error("failed to fetch tasks", logger.Err(err))
 		return nil, errs.NewAppError(op, err)
@@ -105,14 +144,364 @@ func (ts *taskService) Get(ctx context.Context, amount, page int, statusFilter s
 	return tasks, nil
 }
 
-func (ts *taskService) UpdateStatus(ctx context.Context, id, status string) error {
+// UpdateStatus changes the task's status. When version is set the update only
+// succeeds if the task has not been modified since that version was read.
+func (ts *taskService) UpdateStatus(ctx context.Context, id, status string, version *int) error {
 	op := place + "UpdateStatus"
 	log := ts.Logger.AddOp(op)
-	log.Info("updating task's status")
-	if err := ts.TaskRepository.UpdateStatus(ctx, id, status); err != nil {
+	log.Info("updating task's status", "caller", callerID(ctx))
+	v := &validator{}
+	v.oneOf("status", status, models.Statuses())
+	if err := v.err(op); err != nil {
+		log.Error("invalid status", logger.Err(err))
+		return err
+	}
+	var err error
+	if version != nil {
+		err = ts.TaskRepository.UpdateStatusIfVersion(ctx, id, status, *version)
+	} else {
+		err = ts.TaskRepository.UpdateStatus(ctx, id, status)
+	}
+	if err != nil {
 		log.Error("failed to update task's status", logger.Err(err))
 		return errs.NewAppError(op, err)
 	}
 	log.Info("task's status updated")
 	return nil
 }
+
+// Assign makes assignee responsible for the task. ActorMe assigns the caller,
+// an empty assignee or ActorNone unassigns the task. When version is set the
+// assignment only succeeds if the task has not been modified since.
+func (ts *taskService) Assign(ctx context.Context, id, assignee string, version *int) (*models.Task, error) {
+	op := place + "Assign"
+	log := ts.Logger.AddOp(op)
+	log.Info("assigning task", "caller", callerID(ctx))
+	v := &validator{}
+	if assignee == models.ActorNone {
+		assignee = ""
+	}
+	assignee = resolveActor(ctx, v, "assignee", assignee)
+	if err := v.err(op); err != nil {
+		log.Error("invalid assignee", logger.Err(err))
+		return nil, err
+	}
+	task, err := ts.TaskRepository.Assign(ctx, id, assignee, version)
+	if err != nil {
+		log.Error("failed to assign task", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("task assigned", "assignee", assignee)
+	return task, nil
+}
+
+func (ts *taskService) BulkUpdateStatus(ctx context.Context, selection models.BulkSelection, status string, dryRun bool) (*models.BulkResult, error) {
+	op := place + "BulkUpdateStatus"
+	log := ts.Logger.AddOp(op)
+	log.Info("bulk updating task's status", "status", status, "dry_run", dryRun, "caller", callerID(ctx))
+	v := &validator{}
+	if v.required("status", status) {
+		v.oneOf("status", status, models.Statuses())
+	}
+	filter, err := bulkFilter(ctx, op, v, selection)
+	if err != nil {
+		log.Error("invalid bulk request", logger.Err(err))
+		return nil, err
+	}
+	res := &models.BulkResult{DryRun: dryRun}
+	from := models.SourceStatuses(status)
+	err = ts.eachBulkPage(ctx, op, selection.IDs, filter, func(tasks []models.Task) error {
+		res.Matched += len(tasks)
+		eligible := []uuid.UUID{}
+		for _, t := range tasks {
+			if models.CanTransition(t.Status, status) {
+				eligible = append(eligible, t.ID)
+			}
+		}
+		if dryRun || len(eligible) == 0 {
+			res.Affected += len(eligible)
+			return nil
+		}
+		updated, err := ts.TaskRepository.UpdateStatusBatch(ctx, eligible, status, from)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		res.Affected += len(updated)
+		if status == models.StatusCreated {
+			for _, id := range updated {
+				ts.enqueue(ctx, log, id.String())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to update tasks", logger.Err(err), "affected", res.Affected)
+		return nil, err
+	}
+	res.Skipped = res.Matched - res.Affected
+	log.Info("task's status bulk updated", "matched", res.Matched, "affected", res.Affected)
+	return res, nil
+}
+
+func (ts *taskService) BulkDelete(ctx context.Context, selection models.BulkSelection, dryRun bool) (*models.BulkResult, error) {
+	op := place + "BulkDelete"
+	log := ts.Logger.AddOp(op)
+	log.Info("bulk deleting tasks", "dry_run", dryRun, "caller", callerID(ctx))
+	filter, err := bulkFilter(ctx, op, &validator{}, selection)
+	if err != nil {
+		log.Error("invalid bulk request", logger.Err(err))
+		return nil, err
+	}
+	res := &models.BulkResult{DryRun: dryRun}
+	err = ts.eachBulkPage(ctx, op, selection.IDs, filter, func(tasks []models.Task) error {
+		res.Matched += len(tasks)
+		if dryRun {
+			res.Affected += len(tasks)
+			return nil
+		}
+		batch := make([]uuid.UUID, 0, len(tasks))
+		for _, t := range tasks {
+			batch = append(batch, t.ID)
+		}
+		deleted, err := ts.TaskRepository.DeleteBatch(ctx, batch)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		res.Affected += deleted
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to delete tasks", logger.Err(err), "affected", res.Affected)
+		return nil, err
+	}
+	res.Skipped = res.Matched - res.Affected
+	log.Info("tasks bulk deleted", "matched", res.Matched, "affected", res.Affected)
+	return res, nil
+}
+
+// WaitForStatus blocks until the task reaches one of the statuses or the
+// timeout elapses. It waits on task events instead of polling, so no database
+// connection is held while waiting. The returned flag reports whether one of
+// the statuses was reached.
+func (ts *taskService) WaitForStatus(ctx context.Context, id string, statuses []string, timeout time.Duration) (*models.Task, bool, error) {
+	op := place + "WaitForStatus"
+	log := ts.Logger.AddOp(op)
+	log.Info("waiting for task's status", "statuses", statuses, "timeout", timeout)
+	v := &validator{}
+	for i, status := range statuses {
+		v.oneOf(fmt.Sprintf("waitFor[%d]", i), status, models.Statuses())
+	}
+	if err := v.err(op); err != nil {
+		log.Error("invalid status to wait for", logger.Err(err))
+		return nil, false, err
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		log.Error("failed to parse task id", logger.Err(err))
+		return nil, false, errs.ErrInvalidValues(op, err)
+	}
+
+	sub := ts.EventService.Subscribe(models.EventFilter{Tenant: models.TenantFromContext(ctx), TaskID: &uid})
+	defer sub.Close()
+
+	task, err := ts.TaskRepository.GetById(ctx, id)
+	if err != nil {
+		log.Error("failed to receive task by id", logger.Err(err))
+		return nil, false, errs.NewAppError(op, err)
+	}
+	if slices.Contains(statuses, task.Status) {
+		log.Info("task already has awaited status")
+		return task, true, nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("waiting cancelled")
+			return nil, false, errs.NewAppError(op, ctx.Err())
+		case <-timer.C:
+			break wait
+		case event, ok := <-sub.Events:
+			if !ok {
+				// the subscription was dropped, fall back to the stored state
+				break wait
+			}
+			if event.Type == models.EventDeleted {
+				log.Info("task deleted while waiting")
+				return nil, false, errs.ErrNotFound(op)
+			}
+			if slices.Contains(statuses, event.Status) {
+				break wait
+			}
+		}
+	}
+
+	task, err = ts.TaskRepository.GetById(ctx, id)
+	if err != nil {
+		log.Error("failed to receive task by id", logger.Err(err))
+		return nil, false, errs.NewAppError(op, err)
+	}
+	reached := slices.Contains(statuses, task.Status)
+	log.Info("waiting finished", "reached", reached)
+	return task, reached, nil
+}
+
+// Export streams every task matching the filter to fn. The task passed to fn
+// is reused between calls and must not be retained.
+func (ts *taskService) Export(ctx context.Context, filter models.TaskFilter, fn func(*models.Task) error) error {
+	op := place + "Export"
+	log := ts.Logger.AddOp(op)
+	log.Info("exporting tasks")
+	v := &validator{}
+	if filter.Status != "" {
+		v.oneOf("statusFilter", filter.Status, models.Statuses())
+	}
+	resolveActors(ctx, v, "", &filter)
+	if err := v.err(op); err != nil {
+		log.Error("invalid export filter", logger.Err(err))
+		return err
+	}
+	exported := 0
+	err := ts.TaskRepository.Export(ctx, filter, func(task *models.Task) error {
+		if err := fn(task); err != nil {
+			return err
+		}
+		exported++
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to export tasks", logger.Err(err), "exported", exported)
+		return errs.NewAppError(op, err)
+	}
+	log.Info("tasks exported", "exported", exported)
+	return nil
+}
+
+// Stats aggregates tasks over the last window split into buckets. The window
+// start is aligned down to the bucket, so the first bucket may begin before
+// now minus window. Zero window or bucket selects the default.
+func (ts *taskService) Stats(ctx context.Context, window, bucket time.Duration) (*models.TaskStats, error) {
+	op := place + "Stats"
+	log := ts.Logger.AddOp(op)
+	if window == 0 {
+		window = statsDefaultWindow
+	}
+	if bucket == 0 {
+		bucket = statsDefaultBucket
+	}
+	log.Info("computing task stats", "window", window, "bucket", bucket)
+	v := &validator{}
+	if window < 0 || window > statsMaxWindow {
+		v.add("window", fmt.Sprintf("must be positive and at most %s", statsMaxWindow))
+	}
+	if bucket < statsMinBucket {
+		v.add("bucket", fmt.Sprintf("must be at least %s", statsMinBucket))
+	} else if window > 0 && window/bucket > statsMaxBuckets {
+		v.add("bucket", fmt.Sprintf("must split the window into at most %d buckets", statsMaxBuckets))
+	}
+	if err := v.err(op); err != nil {
+		log.Error("invalid stats parameters", logger.Err(err))
+		return nil, err
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-window).Truncate(bucket)
+	stats, err := ts.TaskRepository.Stats(ctx, from, to, bucket)
+	if err != nil {
+		log.Error("failed to compute task stats", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("task stats computed", "total", stats.Total, "buckets", len(stats.Buckets))
+	return stats, nil
+}
+
+// bulkFilter validates the selection of a bulk operation along with the
+// violations already in v, and returns its filter with the actors resolved.
+// Ids, a filter setting at least one field or All is required, so an empty
+// request never touches the whole table.
+func bulkFilter(ctx context.Context, op string, v *validator, selection models.BulkSelection) (models.TaskFilter, error) {
+	f := models.TaskFilter{}
+	if selection.Filter != nil {
+		f = *selection.Filter
+	}
+	switch {
+	case selection.All && (len(selection.IDs) > 0 || !f.Empty()):
+		v.add("all", "cannot be combined with ids or filter")
+	case !selection.All && len(selection.IDs) == 0 && f.Empty():
+		v.add("", "either ids, a non-empty filter or all must be provided")
+	}
+	if f.Status != "" {
+		v.oneOf("filter.statusFilter", f.Status, models.Statuses())
+	}
+	resolveActors(ctx, v, "filter.", &f)
+	return f, v.err(op)
+}
+
+// eachBulkPage passes the tasks a bulk operation applies to to fn in pages of
+// bulkBatchSize, so the selection is never loaded at once. fn may change the
+// tasks of its page, as the next page starts after the last id of it.
+func (ts *taskService) eachBulkPage(ctx context.Context, op string, ids []uuid.UUID, filter models.TaskFilter, fn func([]models.Task) error) error {
+	after := uuid.Nil
+	for {
+		tasks, err := ts.TaskRepository.GetPageByFilter(ctx, ids, filter, after, bulkBatchSize)
+		if err != nil {
+			return errs.NewAppError(op, err)
+		}
+		if len(tasks) == 0 {
+			return nil
+		}
+		if err := fn(tasks); err != nil {
+			return err
+		}
+		if len(tasks) < bulkBatchSize {
+			return nil
+		}
+		after = tasks[len(tasks)-1].ID
+	}
+}
+
+// resolveActors replaces ActorMe in the actor fields of filter with the id of
+// the caller. prefix qualifies the field names of validation errors.
+func resolveActors(ctx context.Context, v *validator, prefix string, filter *models.TaskFilter) {
+	filter.CreatedBy = resolveActor(ctx, v, prefix+"createdBy", filter.CreatedBy)
+	filter.Assignee = resolveActor(ctx, v, prefix+"assignee", filter.Assignee)
+}
+
+// resolveActor returns the id of the caller for ActorMe, which therefore
+// needs an authenticated caller, and any other actor as is.
+func resolveActor(ctx context.Context, v *validator, field, actor string) string {
+	if actor != models.ActorMe {
+		v.maxLength(field, actor, models.ActorMaxLength)
+		return actor
+	}
+	id := callerID(ctx)
+	if id == "" {
+		v.add(field, "me requires an authenticated caller")
+	}
+	return id
+}
+
+// TenantHeader is the queue message header carrying the tenant of the task.
+const TenantHeader = "tenant-id"
+
+// taskMessage is the queue message asking the task worker to process a task
+// of the tenant of ctx.
+func taskMessage(ctx context.Context, id string) queue.Message {
+	return queue.Message{
+		Key:     id,
+		Value:   []byte(id),
+		Headers: map[string]string{TenantHeader: models.TenantFromContext(ctx)},
+		Time:    time.Now(),
+	}
+}
+
+func (ts *taskService) enqueue(ctx context.Context, log *logger.Logger, id string) {
+	if err := ts.Producer.SendMessage(ctx, taskMessage(ctx, id)); err != nil {
+		log.Error("failed to send task to queue", logger.Err(err))
+	} else {
+		log.Info("task sent to queue", "task_id", id)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"betera-tz/pkg/queue"
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -19,8 +21,8 @@ type MockTaskRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaskRepository) Create(ctx context.Context, task *models.Task) (*string, error) {
-	args := m.Called(ctx, task)
+func (m *MockTaskRepository) Create(ctx context.Context, task *models.Task, quota models.Quota) (*string, error) {
+	args := m.Called(ctx, task, quota)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -35,8 +37,8 @@ func (m *MockTaskRepository) GetById(ctx context.Context, id string) (*models.Ta
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) Get(ctx context.Context, amount, page int, statusFilter string) ([]models.Task, error) {
-	args := m.Called(ctx, amount, page, statusFilter)
+func (m *MockTaskRepository) Get(ctx context.Context, amount, page int, filter models.TaskFilter) ([]models.Task, error) {
+	args := m.Called(ctx, amount, page, filter)
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
@@ -45,11 +47,89 @@ func (m *MockTaskRepository) UpdateStatus(ctx context.Context, id, status string
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) UpdateStatusIfVersion(ctx context.Context, id, status string, version int) error {
+	args := m.Called(ctx, id, status, version)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) Assign(ctx context.Context, id, assignee string, version *int) (*models.Task, error) {
+	args := m.Called(ctx, id, assignee, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetPageByFilter(ctx context.Context, ids []uuid.UUID, filter models.TaskFilter, after uuid.UUID, limit int) ([]models.Task, error) {
+	args := m.Called(ctx, ids, filter, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status string, from []string) ([]uuid.UUID, error) {
+	args := m.Called(ctx, ids, status, from)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockTaskRepository) DeleteBatch(ctx context.Context, ids []uuid.UUID) (int, error) {
+	args := m.Called(ctx, ids)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateBatch(ctx context.Context, tasks []models.Task, quota models.Quota) ([]uuid.UUID, int, error) {
+	args := m.Called(ctx, tasks, quota)
+	if fn, ok := args.Get(0).(func([]models.Task, models.Quota) ([]uuid.UUID, int)); ok {
+		created, tried := fn(tasks, quota)
+		return created, tried, args.Error(1)
+	}
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]uuid.UUID), args.Int(1), args.Error(2)
+}
+
+func (m *MockTaskRepository) StartProcessing(ctx context.Context, id, actor string, maxProcessing int) (bool, error) {
+	args := m.Called(ctx, id, actor, maxProcessing)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) Export(ctx context.Context, filter models.TaskFilter, fn func(*models.Task) error) error {
+	args := m.Called(ctx, filter)
+	if tasks, ok := args.Get(0).([]models.Task); ok {
+		for i := range tasks {
+			if err := fn(&tasks[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTaskRepository) Stats(ctx context.Context, from, to time.Time, bucket time.Duration) (*models.TaskStats, error) {
+	args := m.Called(ctx, from, to, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskStats), args.Error(1)
+}
+
+const testTenant = "team-a"
+
+// tenantCtx returns the context of a request scoped to testTenant.
+func tenantCtx() context.Context {
+	return models.WithTenant(context.Background(), testTenant)
+}
+
 type MockProducer struct {
 	mock.Mock
 }
 
-func (m *MockProducer) SendMessage(message queue.Message) error {
+func (m *MockProducer) SendMessage(ctx context.Context, message queue.Message) error {
 	args := m.Called(message)
 	return args.Error(0)
 }
@@ -59,8 +139,10 @@ func TestTaskService_Create(t *testing.T) {
 		name           string
 		title          string
 		description    string
+		callbackURL    string
 		mockSetup      func(*MockTaskRepository, *MockProducer)
 		expectedError  bool
+		errorIs        error
 		expectedResult *uuid.UUID
 	}{
 		{
@@ -69,8 +151,10 @@ func TestTaskService_Create(t *testing.T) {
 			description: "Test Description",
 			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
 				taskId := "550e8400-e29b-41d4-a716-446655440000"
-				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(&taskId, nil)
-				mockProducer.On("SendMessage", mock.AnythingOfType("queue.Message")).Return(nil)
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task"), mock.Anything).Return(&taskId, nil)
+				mockProducer.On("SendMessage", mock.MatchedBy(func(message queue.Message) bool {
+					return string(message.Value) == taskId && message.Headers[TenantHeader] == testTenant
+				})).Return(nil)
 			},
 			expectedError:  false,
 			expectedResult: func() *uuid.UUID { id, _ := uuid.Parse("550e8400-e29b-41d4-a716-446655440000"); return &id }(),
@@ -80,9 +164,46 @@ func TestTaskService_Create(t *testing.T) {
 			title:       "Test Task",
 			description: "Test Description",
 			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
-				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil, errors.New("database error"))
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task"), mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedError:  true,
+			expectedResult: nil,
+		},
+		{
+			name:        "successful task creation with callback url",
+			title:       "Test Task",
+			description: "Test Description",
+			callbackURL: "https://example.com/hooks/tasks",
+			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
+				taskId := "550e8400-e29b-41d4-a716-446655440000"
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+					return task.CallbackURL == "https://example.com/hooks/tasks" && task.CreatedBy == "user:1b2c"
+				}), mock.Anything).Return(&taskId, nil)
+				mockProducer.On("SendMessage", mock.AnythingOfType("queue.Message")).Return(nil)
+			},
+			expectedError:  false,
+			expectedResult: func() *uuid.UUID { id, _ := uuid.Parse("550e8400-e29b-41d4-a716-446655440000"); return &id }(),
+		},
+		{
+			name:           "invalid callback url",
+			title:          "Test Task",
+			description:    "Test Description",
+			callbackURL:    "ftp://example.com/hooks",
+			mockSetup:      func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {},
+			expectedError:  true,
+			expectedResult: nil,
+		},
+		{
+			name:        "quota reached",
+			title:       "Test Task",
+			description: "Test Description",
+			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task"), mock.MatchedBy(func(quota models.Quota) bool {
+					return quota.Tenant == testTenant && quota.MaxActive == 5
+				})).Return(nil, errs.ErrQuota("test", models.QuotaMaxActive, 5))
 			},
 			expectedError:  true,
+			errorIs:        errs.ErrQuotaBase,
 			expectedResult: nil,
 		},
 		{
@@ -91,7 +212,7 @@ func TestTaskService_Create(t *testing.T) {
 			description: "Test Description",
 			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
 				taskId := "550e8400-e29b-41d4-a716-446655440000"
-				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(&taskId, nil)
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task"), mock.Anything).Return(&taskId, nil)
 				mockProducer.On("SendMessage", mock.AnythingOfType("queue.Message")).Return(errors.New("kafka error"))
 			},
 			expectedError:  false,
@@ -110,15 +231,20 @@ func TestTaskService_Create(t *testing.T) {
 
 			service := &taskService{
 				TaskRepository: mockRepo,
+				QuotaService:   defaultQuotas(config.QuotasConfig{MaxActive: 5}),
 				Producer:       mockProducer,
 				Logger:         logger,
 			}
 
-			result, err := service.Create(context.Background(), tt.title, tt.description)
+			ctx := WithCaller(tenantCtx(), &models.Caller{Type: models.CallerUser, Subject: "1b2c"})
+			result, err := service.Create(ctx, tt.title, tt.description, tt.callbackURL)
 
 			if tt.expectedError {
 				assert.Error(t, err)
 				assert.Nil(t, result)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -133,6 +259,80 @@ func TestTaskService_Create(t *testing.T) {
 	}
 }
 
+func TestTaskService_CreateValidation(t *testing.T) {
+	tests := []struct {
+		name               string
+		title              string
+		description        string
+		callbackURL        string
+		expectedViolations []errs.Violation
+	}{
+		{
+			name:        "missing title and description",
+			title:       "   ",
+			description: "",
+			expectedViolations: []errs.Violation{
+				{Field: "title", Message: "is required"},
+				{Field: "description", Message: "is required"},
+			},
+		},
+		{
+			name:        "fields longer than the columns",
+			title:       strings.Repeat("t", 151),
+			description: strings.Repeat("d", 651),
+			callbackURL: "https://example.com/" + strings.Repeat("p", 2048),
+			expectedViolations: []errs.Violation{
+				{Field: "title", Message: "must be at most 150 characters"},
+				{Field: "description", Message: "must be at most 650 characters"},
+				{Field: "callbackUrl", Message: "must be at most 2048 characters"},
+			},
+		},
+		{
+			name:        "length is counted in characters",
+			title:       strings.Repeat("я", 150),
+			description: "Test Description",
+			callbackURL: "mailto:ops@example.com",
+			expectedViolations: []errs.Violation{
+				{Field: "callbackUrl", Message: "must be an http or https url"},
+			},
+		},
+		{
+			name:        "callback url on an internal address",
+			title:       "Test Task",
+			description: "Test Description",
+			callbackURL: "http://[::ffff:169.254.169.254]/latest/meta-data",
+			expectedViolations: []errs.Violation{
+				{Field: "callbackUrl", Message: "must not point at a local or private address"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			mockProducer := new(MockProducer)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+			service := &taskService{
+				TaskRepository: mockRepo,
+				Producer:       mockProducer,
+				Logger:         logger,
+			}
+
+			result, err := service.Create(context.Background(), tt.title, tt.description, tt.callbackURL)
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, errs.ErrInvalidValuesBase)
+			var ve errs.ValidationError
+			if assert.ErrorAs(t, err, &ve) {
+				assert.Equal(t, tt.expectedViolations, ve.Violations)
+			}
+
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestTaskService_GetById(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -204,16 +404,16 @@ func TestTaskService_Get(t *testing.T) {
 		name           string
 		amount         int
 		page           int
-		statusFilter   string
+		filter         models.TaskFilter
+		caller         *models.Caller
 		mockSetup      func(*MockTaskRepository)
 		expectedError  bool
 		expectedResult []models.Task
 	}{
 		{
-			name:         "successful get with pagination",
-			amount:       10,
-			page:         1,
-			statusFilter: "",
+			name:   "successful get with pagination",
+			amount: 10,
+			page:   1,
 			mockSetup: func(mockRepo *MockTaskRepository) {
 				tasks := []models.Task{
 					{
@@ -229,7 +429,7 @@ func TestTaskService_Get(t *testing.T) {
 						Status:      "done",
 					},
 				}
-				mockRepo.On("Get", mock.Anything, 10, 1, "").Return(tasks, nil)
+				mockRepo.On("Get", mock.Anything, 10, 1, models.TaskFilter{}).Return(tasks, nil)
 			},
 			expectedError: false,
 			expectedResult: []models.Task{
@@ -248,10 +448,9 @@ func TestTaskService_Get(t *testing.T) {
 			},
 		},
 		{
-			name:         "get all tasks (no pagination)",
-			amount:       0,
-			page:         0,
-			statusFilter: "",
+			name:   "get all tasks (no pagination)",
+			amount: 0,
+			page:   0,
 			mockSetup: func(mockRepo *MockTaskRepository) {
 				tasks := []models.Task{
 					{
@@ -261,7 +460,7 @@ func TestTaskService_Get(t *testing.T) {
 						Status:      "created",
 					},
 				}
-				mockRepo.On("Get", mock.Anything, -1, -1, "").Return(tasks, nil)
+				mockRepo.On("Get", mock.Anything, -1, -1, models.TaskFilter{}).Return(tasks, nil)
 			},
 			expectedError: false,
 			expectedResult: []models.Task{
@@ -274,10 +473,10 @@ func TestTaskService_Get(t *testing.T) {
 			},
 		},
 		{
-			name:         "get all tasks (no pagination) with filter",
-			amount:       0,
-			page:         0,
-			statusFilter: "created",
+			name:   "get all tasks (no pagination) with filter",
+			amount: 0,
+			page:   0,
+			filter: models.TaskFilter{Status: "created"},
 			mockSetup: func(mockRepo *MockTaskRepository) {
 				tasks := []models.Task{
 					{
@@ -287,7 +486,7 @@ func TestTaskService_Get(t *testing.T) {
 						Status:      "created",
 					},
 				}
-				mockRepo.On("Get", mock.Anything, -1, -1, "created").Return(tasks, nil)
+				mockRepo.On("Get", mock.Anything, -1, -1, models.TaskFilter{Status: "created"}).Return(tasks, nil)
 			},
 			expectedError: false,
 			expectedResult: []models.Task{
@@ -300,10 +499,10 @@ func TestTaskService_Get(t *testing.T) {
 			},
 		},
 		{
-			name:         "get all tasks with pagination and filter",
-			amount:       10,
-			page:         1,
-			statusFilter: "created",
+			name:   "get all tasks with pagination and filter",
+			amount: 10,
+			page:   1,
+			filter: models.TaskFilter{Status: "created"},
 			mockSetup: func(mockRepo *MockTaskRepository) {
 				tasks := []models.Task{
 					{
@@ -313,7 +512,7 @@ func TestTaskService_Get(t *testing.T) {
 						Status:      "created",
 					},
 				}
-				mockRepo.On("Get", mock.Anything, 10, 1, "created").Return(tasks, nil)
+				mockRepo.On("Get", mock.Anything, 10, 1, models.TaskFilter{Status: "created"}).Return(tasks, nil)
 			},
 			expectedError: false,
 			expectedResult: []models.Task{
@@ -326,12 +525,27 @@ func TestTaskService_Get(t *testing.T) {
 			},
 		},
 		{
-			name:         "repository error",
-			amount:       10,
-			page:         1,
-			statusFilter: "created",
+			name:   "tasks assigned to the caller",
+			filter: models.TaskFilter{Assignee: models.ActorMe, CreatedBy: models.ActorNone},
+			caller: &models.Caller{Type: models.CallerUser, Subject: "1b2c"},
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("Get", mock.Anything, -1, -1, models.TaskFilter{Assignee: "user:1b2c", CreatedBy: models.ActorNone}).Return([]models.Task{}, nil)
+			},
+			expectedResult: []models.Task{},
+		},
+		{
+			name:          "me without a caller",
+			filter:        models.TaskFilter{CreatedBy: models.ActorMe},
+			mockSetup:     func(mockRepo *MockTaskRepository) {},
+			expectedError: true,
+		},
+		{
+			name:   "repository error",
+			amount: 10,
+			page:   1,
+			filter: models.TaskFilter{Status: "created"},
 			mockSetup: func(mockRepo *MockTaskRepository) {
-				mockRepo.On("Get", mock.Anything, 10, 1, "created").Return([]models.Task{}, errors.New("database error"))
+				mockRepo.On("Get", mock.Anything, 10, 1, models.TaskFilter{Status: "created"}).Return([]models.Task{}, errors.New("database error"))
 			},
 			expectedError:  true,
 			expectedResult: nil,
@@ -350,7 +564,11 @@ func TestTaskService_Get(t *testing.T) {
 				Logger:         logger,
 			}
 
-			result, err := service.Get(context.Background(), tt.amount, tt.page, tt.statusFilter)
+			ctx := context.Background()
+			if tt.caller != nil {
+				ctx = WithCaller(ctx, tt.caller)
+			}
+			result, err := service.Get(ctx, tt.amount, tt.page, tt.filter)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -370,8 +588,10 @@ func TestTaskService_UpdateStatus(t *testing.T) {
 		name          string
 		id            string
 		status        string
+		version       *int
 		mockSetup     func(*MockTaskRepository)
 		expectedError bool
+		expectedErrIs error
 	}{
 		{
 			name:   "successful status update",
@@ -400,6 +620,512 @@ func TestTaskService_UpdateStatus(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name:    "successful conditional status update",
+			id:      "550e8400-e29b-41d4-a716-446655440000",
+			status:  "done",
+			version: func() *int { v := 3; return &v }(),
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("UpdateStatusIfVersion", mock.Anything, "550e8400-e29b-41d4-a716-446655440000", "done", 3).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:    "version mismatch",
+			id:      "550e8400-e29b-41d4-a716-446655440000",
+			status:  "done",
+			version: func() *int { v := 2; return &v }(),
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("UpdateStatusIfVersion", mock.Anything, "550e8400-e29b-41d4-a716-446655440000", "done", 2).Return(errs.ErrPreconditionFailed("test"))
+			},
+			expectedError: true,
+			expectedErrIs: errs.ErrPreconditionBase,
+		},
+		{
+			name:          "unknown status",
+			id:            "550e8400-e29b-41d4-a716-446655440000",
+			status:        "archived",
+			mockSetup:     func(mockRepo *MockTaskRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+			tt.mockSetup(mockRepo)
+
+			service := &taskService{
+				TaskRepository: mockRepo,
+				Logger:         logger,
+			}
+
+			err := service.UpdateStatus(context.Background(), tt.id, tt.status, tt.version)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.expectedErrIs != nil {
+					assert.ErrorIs(t, err, tt.expectedErrIs)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskService_Assign(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	caller := &models.Caller{Type: models.CallerApiKey, Subject: "7c9e"}
+	tests := []struct {
+		name             string
+		assignee         string
+		version          *int
+		expectedAssignee string
+		repoErr          error
+		expectedErrIs    error
+	}{
+		{
+			name:             "assign to a caller id",
+			assignee:         "user:1b2c",
+			expectedAssignee: "user:1b2c",
+		},
+		{
+			name:             "assign to the caller",
+			assignee:         models.ActorMe,
+			version:          func() *int { v := 3; return &v }(),
+			expectedAssignee: "api_key:7c9e",
+		},
+		{
+			name:     "unassign with none",
+			assignee: models.ActorNone,
+		},
+		{
+			name:          "too long assignee",
+			assignee:      strings.Repeat("a", models.ActorMaxLength+1),
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+		{
+			name:             "version mismatch",
+			assignee:         "user:1b2c",
+			version:          func() *int { v := 2; return &v }(),
+			expectedAssignee: "user:1b2c",
+			repoErr:          errs.ErrPreconditionFailed("test"),
+			expectedErrIs:    errs.ErrPreconditionBase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			service := &taskService{
+				TaskRepository: mockRepo,
+				Logger:         logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"}),
+			}
+			if tt.expectedErrIs == nil || tt.repoErr != nil {
+				task := &models.Task{Assignee: tt.expectedAssignee}
+				if tt.repoErr != nil {
+					task = nil
+				}
+				mockRepo.On("Assign", mock.Anything, id, tt.expectedAssignee, tt.version).Return(task, tt.repoErr)
+			}
+
+			task, err := service.Assign(WithCaller(tenantCtx(), caller), id, tt.assignee, tt.version)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+				assert.Nil(t, task)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedAssignee, task.Assignee)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskService_BulkUpdateStatus(t *testing.T) {
+	first := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	second := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
+	tasks := []models.Task{
+		{ID: first, Title: "Task 1", Description: "Description 1", Status: "processing"},
+		{ID: second, Title: "Task 2", Description: "Description 2", Status: "created"},
+	}
+	tests := []struct {
+		name           string
+		selection      models.BulkSelection
+		status         string
+		dryRun         bool
+		mockSetup      func(*MockTaskRepository, *MockProducer)
+		expectedError  bool
+		expectedErrIs  error
+		expectedResult *models.BulkResult
+	}{
+		{
+			name:      "dry run counts eligible tasks",
+			selection: models.BulkSelection{All: true},
+			status:    "created",
+			dryRun:    true,
+			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID(nil), models.TaskFilter{}, uuid.Nil, bulkBatchSize).Return(tasks, nil)
+			},
+			expectedError:  false,
+			expectedResult: &models.BulkResult{Matched: 2, Affected: 1, Skipped: 1, DryRun: true},
+		},
+		{
+			name:      "requeue updates eligible tasks and sends them to queue",
+			selection: models.BulkSelection{IDs: []uuid.UUID{first, second}},
+			status:    "created",
+			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID{first, second}, models.TaskFilter{}, uuid.Nil, bulkBatchSize).Return(tasks, nil)
+				mockRepo.On("UpdateStatusBatch", mock.Anything, []uuid.UUID{first}, "created", mock.Anything).Return([]uuid.UUID{first}, nil)
+				mockProducer.On("SendMessage", mock.AnythingOfType("queue.Message")).Return(nil).Once()
+			},
+			expectedError:  false,
+			expectedResult: &models.BulkResult{Matched: 2, Affected: 1, Skipped: 1},
+		},
+		{
+			name:      "done does not requeue",
+			selection: models.BulkSelection{Filter: &models.TaskFilter{Status: "processing"}},
+			status:    "done",
+			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID(nil), models.TaskFilter{Status: "processing"}, uuid.Nil, bulkBatchSize).Return(tasks[:1], nil)
+				mockRepo.On("UpdateStatusBatch", mock.Anything, []uuid.UUID{first}, "done", mock.Anything).Return([]uuid.UUID{first}, nil)
+			},
+			expectedError:  false,
+			expectedResult: &models.BulkResult{Matched: 1, Affected: 1, Skipped: 0},
+		},
+		{
+			name:      "selection paged by id",
+			selection: models.BulkSelection{Filter: &models.TaskFilter{Status: "processing"}},
+			status:    "done",
+			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
+				page := make([]models.Task, bulkBatchSize)
+				ids := make([]uuid.UUID, bulkBatchSize)
+				for i := range page {
+					ids[i] = uuid.New()
+					page[i] = models.Task{ID: ids[i], Status: "processing"}
+				}
+				filter := models.TaskFilter{Status: "processing"}
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID(nil), filter, uuid.Nil, bulkBatchSize).Return(page, nil).Once()
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID(nil), filter, ids[bulkBatchSize-1], bulkBatchSize).Return([]models.Task{}, nil).Once()
+				mockRepo.On("UpdateStatusBatch", mock.Anything, ids, "done", mock.Anything).Return(ids, nil).Once()
+			},
+			expectedError:  false,
+			expectedResult: &models.BulkResult{Matched: bulkBatchSize, Affected: bulkBatchSize},
+		},
+		{
+			name:           "unknown status",
+			selection:      models.BulkSelection{IDs: []uuid.UUID{first}},
+			status:         "archived",
+			mockSetup:      func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {},
+			expectedError:  true,
+			expectedErrIs:  errs.ErrInvalidValuesBase,
+			expectedResult: nil,
+		},
+		{
+			name:           "neither ids nor filter",
+			status:         "done",
+			mockSetup:      func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {},
+			expectedError:  true,
+			expectedErrIs:  errs.ErrInvalidValuesBase,
+			expectedResult: nil,
+		},
+		{
+			name:           "empty filter without all",
+			selection:      models.BulkSelection{Filter: &models.TaskFilter{}},
+			status:         "done",
+			mockSetup:      func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {},
+			expectedError:  true,
+			expectedErrIs:  errs.ErrInvalidValuesBase,
+			expectedResult: nil,
+		},
+		{
+			name:           "all with ids",
+			selection:      models.BulkSelection{IDs: []uuid.UUID{first}, All: true},
+			status:         "done",
+			mockSetup:      func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {},
+			expectedError:  true,
+			expectedErrIs:  errs.ErrInvalidValuesBase,
+			expectedResult: nil,
+		},
+		{
+			name:      "repository error",
+			selection: models.BulkSelection{IDs: []uuid.UUID{first}},
+			status:    "done",
+			mockSetup: func(mockRepo *MockTaskRepository, mockProducer *MockProducer) {
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID{first}, models.TaskFilter{}, uuid.Nil, bulkBatchSize).Return(nil, errors.New("database error"))
+			},
+			expectedError:  true,
+			expectedResult: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			mockProducer := new(MockProducer)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+			tt.mockSetup(mockRepo, mockProducer)
+
+			service := &taskService{
+				TaskRepository: mockRepo,
+				Producer:       mockProducer,
+				Logger:         logger,
+			}
+
+			result, err := service.BulkUpdateStatus(context.Background(), tt.selection, tt.status, tt.dryRun)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.expectedErrIs != nil {
+					assert.ErrorIs(t, err, tt.expectedErrIs)
+				}
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockProducer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskService_BulkDelete(t *testing.T) {
+	first := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	tasks := []models.Task{
+		{ID: first, Title: "Task 1", Description: "Description 1", Status: "done"},
+	}
+	tests := []struct {
+		name           string
+		filter         *models.TaskFilter
+		dryRun         bool
+		mockSetup      func(*MockTaskRepository)
+		expectedError  bool
+		expectedResult *models.BulkResult
+	}{
+		{
+			name:   "dry run",
+			filter: &models.TaskFilter{Status: "done"},
+			dryRun: true,
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID(nil), models.TaskFilter{Status: "done"}, uuid.Nil, bulkBatchSize).Return(tasks, nil)
+			},
+			expectedError:  false,
+			expectedResult: &models.BulkResult{Matched: 1, Affected: 1, DryRun: true},
+		},
+		{
+			name:   "successful delete",
+			filter: &models.TaskFilter{Status: "done"},
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID(nil), models.TaskFilter{Status: "done"}, uuid.Nil, bulkBatchSize).Return(tasks, nil)
+				mockRepo.On("DeleteBatch", mock.Anything, []uuid.UUID{first}).Return(1, nil)
+			},
+			expectedError:  false,
+			expectedResult: &models.BulkResult{Matched: 1, Affected: 1},
+		},
+		{
+			name:           "invalid status filter",
+			filter:         &models.TaskFilter{Status: "archived"},
+			mockSetup:      func(mockRepo *MockTaskRepository) {},
+			expectedError:  true,
+			expectedResult: nil,
+		},
+		{
+			name:           "empty filter",
+			filter:         &models.TaskFilter{},
+			mockSetup:      func(mockRepo *MockTaskRepository) {},
+			expectedError:  true,
+			expectedResult: nil,
+		},
+		{
+			name:   "repository error",
+			filter: &models.TaskFilter{Status: "done"},
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("GetPageByFilter", mock.Anything, []uuid.UUID(nil), models.TaskFilter{Status: "done"}, uuid.Nil, bulkBatchSize).Return(tasks, nil)
+				mockRepo.On("DeleteBatch", mock.Anything, []uuid.UUID{first}).Return(0, errors.New("database error"))
+			},
+			expectedError:  true,
+			expectedResult: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+			tt.mockSetup(mockRepo)
+
+			service := &taskService{
+				TaskRepository: mockRepo,
+				Logger:         logger,
+			}
+
+			result, err := service.BulkDelete(context.Background(), models.BulkSelection{Filter: tt.filter}, tt.dryRun)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskService_Export(t *testing.T) {
+	tasks := []models.Task{
+		{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), Title: "Task 1", Description: "Description 1", Status: "done"},
+		{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"), Title: "Task 2", Description: "Description 2", Status: "done"},
+	}
+	writeErr := errors.New("client gone")
+	tests := []struct {
+		name          string
+		filter        models.TaskFilter
+		fnErr         error
+		mockSetup     func(*MockTaskRepository)
+		expectedError bool
+		errorIs       error
+		expectedTitle []string
+	}{
+		{
+			name:   "streams every task",
+			filter: models.TaskFilter{Status: "done"},
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("Export", mock.Anything, models.TaskFilter{Status: "done"}).Return(tasks, nil)
+			},
+			expectedTitle: []string{"Task 1", "Task 2"},
+		},
+		{
+			name:          "invalid status filter",
+			filter:        models.TaskFilter{Status: "archived"},
+			mockSetup:     func(mockRepo *MockTaskRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name:  "writer error stops the export",
+			fnErr: writeErr,
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("Export", mock.Anything, models.TaskFilter{}).Return(tasks, nil)
+			},
+			expectedError: true,
+			errorIs:       writeErr,
+			expectedTitle: []string{"Task 1"},
+		},
+		{
+			name: "repository error",
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("Export", mock.Anything, models.TaskFilter{}).Return(nil, errors.New("database error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+			tt.mockSetup(mockRepo)
+
+			service := &taskService{
+				TaskRepository: mockRepo,
+				Logger:         logger,
+			}
+
+			var titles []string
+			err := service.Export(context.Background(), tt.filter, func(task *models.Task) error {
+				titles = append(titles, task.Title)
+				return tt.fnErr
+			})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedTitle, titles)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskService_Stats(t *testing.T) {
+	tests := []struct {
+		name           string
+		window         time.Duration
+		bucket         time.Duration
+		mockSetup      func(*MockTaskRepository)
+		expectedError  bool
+		errorIs        error
+		expectedWindow time.Duration
+		expectedBucket time.Duration
+	}{
+		{
+			name: "defaults",
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("Stats", mock.Anything, mock.Anything, mock.Anything, time.Hour).Return(&models.TaskStats{}, nil)
+			},
+			expectedWindow: 24 * time.Hour,
+			expectedBucket: time.Hour,
+		},
+		{
+			name:   "custom window and bucket",
+			window: 7 * 24 * time.Hour,
+			bucket: 15 * time.Minute,
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("Stats", mock.Anything, mock.Anything, mock.Anything, 15*time.Minute).Return(&models.TaskStats{}, nil)
+			},
+			expectedWindow: 7 * 24 * time.Hour,
+			expectedBucket: 15 * time.Minute,
+		},
+		{
+			name:          "window too long",
+			window:        91 * 24 * time.Hour,
+			mockSetup:     func(mockRepo *MockTaskRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "bucket too short",
+			bucket:        time.Second,
+			mockSetup:     func(mockRepo *MockTaskRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "too many buckets",
+			window:        30 * 24 * time.Hour,
+			bucket:        time.Minute,
+			mockSetup:     func(mockRepo *MockTaskRepository) {},
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+		{
+			name: "repository error",
+			mockSetup: func(mockRepo *MockTaskRepository) {
+				mockRepo.On("Stats", mock.Anything, mock.Anything, mock.Anything, time.Hour).Return(nil, errors.New("database error"))
+			},
+			expectedError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -414,12 +1140,132 @@ func TestTaskService_UpdateStatus(t *testing.T) {
 				Logger:         logger,
 			}
 
-			err := service.UpdateStatus(context.Background(), tt.id, tt.status)
+			stats, err := service.Stats(context.Background(), tt.window, tt.bucket)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
+				assert.Nil(t, stats)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, stats)
+				call := mockRepo.Calls[0]
+				from, to := call.Arguments.Get(1).(time.Time), call.Arguments.Get(2).(time.Time)
+				assert.True(t, from.Equal(from.Truncate(tt.expectedBucket)))
+				assert.False(t, from.After(to.Add(-tt.expectedWindow)))
+				assert.True(t, to.Sub(from) < tt.expectedWindow+tt.expectedBucket)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskService_WaitForStatus(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	taskWithStatus := func(status string) *models.Task {
+		return &models.Task{
+			ID:          uuid.MustParse(id),
+			Title:       "Test Task",
+			Description: "Test Description",
+			Status:      status,
+		}
+	}
+	tests := []struct {
+		name            string
+		statuses        []string
+		timeout         time.Duration
+		mockSetup       func(*MockTaskRepository, EventService)
+		expectedError   bool
+		expectedErrIs   error
+		expectedReached bool
+		expectedStatus  string
+	}{
+		{
+			name:     "status already reached",
+			statuses: []string{"done"},
+			timeout:  time.Second,
+			mockSetup: func(mockRepo *MockTaskRepository, es EventService) {
+				mockRepo.On("GetById", mock.Anything, id).Return(taskWithStatus("done"), nil).Once()
+			},
+			expectedError:   false,
+			expectedReached: true,
+			expectedStatus:  "done",
+		},
+		{
+			name:     "status reached while waiting",
+			statuses: []string{"done"},
+			timeout:  5 * time.Second,
+			mockSetup: func(mockRepo *MockTaskRepository, es EventService) {
+				mockRepo.On("GetById", mock.Anything, id).Return(taskWithStatus("processing"), nil).Once().Run(func(args mock.Arguments) {
+					es.Publish(models.TaskEvent{ID: 1, TaskID: uuid.MustParse(id), TenantID: testTenant, Type: "updated", Status: "processing"})
+					es.Publish(models.TaskEvent{ID: 2, TaskID: uuid.MustParse(id), TenantID: testTenant, Type: "updated", Status: "done"})
+				})
+				mockRepo.On("GetById", mock.Anything, id).Return(taskWithStatus("done"), nil).Once()
+			},
+			expectedError:   false,
+			expectedReached: true,
+			expectedStatus:  "done",
+		},
+		{
+			name:     "timeout elapsed",
+			statuses: []string{"done"},
+			timeout:  10 * time.Millisecond,
+			mockSetup: func(mockRepo *MockTaskRepository, es EventService) {
+				mockRepo.On("GetById", mock.Anything, id).Return(taskWithStatus("processing"), nil).Twice()
+			},
+			expectedError:   false,
+			expectedReached: false,
+			expectedStatus:  "processing",
+		},
+		{
+			name:     "task deleted while waiting",
+			statuses: []string{"done"},
+			timeout:  5 * time.Second,
+			mockSetup: func(mockRepo *MockTaskRepository, es EventService) {
+				mockRepo.On("GetById", mock.Anything, id).Return(taskWithStatus("processing"), nil).Once().Run(func(args mock.Arguments) {
+					es.Publish(models.TaskEvent{ID: 1, TaskID: uuid.MustParse(id), TenantID: testTenant, Type: "deleted", Status: "processing"})
+				})
+			},
+			expectedError: true,
+			expectedErrIs: errs.ErrNotFoundBase,
+		},
+		{
+			name:          "unknown status",
+			statuses:      []string{"archived"},
+			timeout:       time.Second,
+			mockSetup:     func(mockRepo *MockTaskRepository, es EventService) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+			eventService := NewEventService(new(MockEventRepository), logger, config.EventsConfig{BufferSize: 10})
+
+			tt.mockSetup(mockRepo, eventService)
+
+			service := &taskService{
+				TaskRepository: mockRepo,
+				EventService:   eventService,
+				Logger:         logger,
+			}
+
+			result, reached, err := service.WaitForStatus(tenantCtx(), id, tt.statuses, tt.timeout)
 
 			if tt.expectedError {
 				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+				assert.Nil(t, result)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedReached, reached)
+				assert.Equal(t, tt.expectedStatus, result.Status)
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"context"
+)
+
+const (
+	tenantPlace   = "tenant."
+	tenantMessage = "must be lowercase letters, digits, '-' or '_', at most 63 characters"
+)
+
+// ResolveTenant returns the tenant the request of caller works in. requested
+// is the tenant asked for by the client, e.g. with the X-Tenant-ID header.
+// A caller bound to a tenant is confined to it. An unbound caller works in
+// the default tenant, or in any requested one when it has the admin role.
+func ResolveTenant(caller *models.Caller, requested, field string) (string, error) {
+	op := tenantPlace + "ResolveTenant"
+	if requested != "" && !models.IsValidTenant(requested) {
+		return "", errs.ErrValidation(op, []errs.Violation{{Field: field, Message: tenantMessage}})
+	}
+	switch {
+	case caller.Tenant != "":
+		if requested != "" && requested != caller.Tenant {
+			return "", errs.ErrForbidden(op)
+		}
+		return caller.Tenant, nil
+	case requested == "" || requested == models.DefaultTenant:
+		return models.DefaultTenant, nil
+	case caller.HasRole(models.ScopeAdmin):
+		return requested, nil
+	default:
+		return "", errs.ErrForbidden(op)
+	}
+}
+
+// requireUnbound rejects a caller bound to a tenant from operations that
+// span every tenant, such as managing API keys and quotas.
+func requireUnbound(ctx context.Context, op string) error {
+	if caller := CallerFromContext(ctx); caller != nil && caller.Tenant != "" {
+		return errs.ErrForbidden(op)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"betera-tz/internal/domain/models"
+	"betera-tz/pkg/errs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTenant(t *testing.T) {
+	bound := &models.Caller{Type: models.CallerApiKey, Subject: "1", Roles: []string{models.ScopeAdmin}, Tenant: "team-a"}
+	unbound := &models.Caller{Type: models.CallerUser, Subject: "2", Roles: []string{models.ScopeTasksWrite}}
+	admin := &models.Caller{Type: models.CallerUser, Subject: "3", Roles: []string{models.ScopeAdmin}}
+
+	tests := []struct {
+		name          string
+		caller        *models.Caller
+		requested     string
+		expected      string
+		expectedError bool
+		errorIs       error
+	}{
+		{
+			name:     "bound caller without header",
+			caller:   bound,
+			expected: "team-a",
+		},
+		{
+			name:      "bound caller naming its tenant",
+			caller:    bound,
+			requested: "team-a",
+			expected:  "team-a",
+		},
+		{
+			name:          "bound caller naming another tenant",
+			caller:        bound,
+			requested:     "team-b",
+			expectedError: true,
+			errorIs:       errs.ErrForbiddenBase,
+		},
+		{
+			name:     "unbound caller uses the default tenant",
+			caller:   unbound,
+			expected: models.DefaultTenant,
+		},
+		{
+			name:          "unbound caller naming a tenant",
+			caller:        unbound,
+			requested:     "team-b",
+			expectedError: true,
+			errorIs:       errs.ErrForbiddenBase,
+		},
+		{
+			name:      "unbound admin naming a tenant",
+			caller:    admin,
+			requested: "team-b",
+			expected:  "team-b",
+		},
+		{
+			name:          "invalid tenant",
+			caller:        admin,
+			requested:     "../team",
+			expectedError: true,
+			errorIs:       errs.ErrInvalidValuesBase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant, err := ResolveTenant(tt.caller, tt.requested, "X-Tenant-ID")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.errorIs)
+				assert.Empty(t, tenant)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, tenant)
+			}
+		})
+	}
+}
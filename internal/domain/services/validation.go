@@ -0,0 +1,88 @@
+package services
+
+import (
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/webhook"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// validator collects violations of a request so every invalid field is
+// reported at once instead of failing on the first one.
+type validator struct {
+	violations []errs.Violation
+}
+
+func (v *validator) add(field, message string) {
+	v.violations = append(v.violations, errs.Violation{Field: field, Message: message})
+}
+
+func (v *validator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+		return false
+	}
+	return true
+}
+
+// maxLength counts characters rather than bytes, as VARCHAR(n) does.
+func (v *validator) maxLength(field, value string, max int) bool {
+	if utf8.RuneCountInString(value) > max {
+		v.add(field, fmt.Sprintf("must be at most %d characters", max))
+		return false
+	}
+	return true
+}
+
+func (v *validator) oneOf(field, value string, allowed []string) bool {
+	if !slices.Contains(allowed, value) {
+		v.add(field, fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")))
+		return false
+	}
+	return true
+}
+
+func (v *validator) nonNegative(field string, value int) bool {
+	if value < 0 {
+		v.add(field, "must not be negative")
+		return false
+	}
+	return true
+}
+
+func (v *validator) url(field, value string) bool {
+	if err := validateWebhookURL(value); err != nil {
+		v.add(field, err.Error())
+		return false
+	}
+	return true
+}
+
+// err returns the collected violations as a validation error, or nil.
+func (v *validator) err(op string) error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return errs.ErrValidation(op, v.violations)
+}
+
+// validateWebhookURL accepts http(s) urls of hosts deliveries may reach.
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("must be a valid url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("must be an http or https url")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("must have a host")
+	}
+	if err := webhook.CheckURL(raw); err != nil {
+		return fmt.Errorf("must not point at a local or private address")
+	}
+	return nil
+}
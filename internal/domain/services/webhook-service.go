@@ -0,0 +1,280 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/repositories"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/webhook"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const webhookEvent = "task.status_changed"
+
+type WebhookService interface {
+	Create(ctx context.Context, endpoint string, statuses []string) (*models.Webhook, error)
+	GetById(ctx context.Context, id uuid.UUID) (*models.Webhook, error)
+	Get(ctx context.Context) ([]models.Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Enable(ctx context.Context, id uuid.UUID) error
+	GetDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+	CallbackSecret(ctx context.Context) (string, error)
+	Deliver(ctx context.Context) (int, error)
+}
+
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret string, payload []byte, header http.Header) (int, error)
+}
+
+type webhookService struct {
+	WebhookRepository repositories.WebhookRepository
+	Sender            WebhookSender
+	Logger            *logger.Logger
+	Config            config.WebhooksConfig
+}
+
+// NewWebhookService panics without a callback secret, as any task may set a
+// callback url and its deliveries would go out unsigned. The secret is not
+// used as is: the callbacks of each tenant are signed with one derived from it.
+func NewWebhookService(wr repositories.WebhookRepository, s *webhook.Sender, l *logger.Logger, cfg config.WebhooksConfig) WebhookService {
+	if cfg.CallbackSecret == "" {
+		panic(errors.New("webhooks callbackSecret is required to sign task callbacks"))
+	}
+	return &webhookService{
+		WebhookRepository: wr,
+		Sender:            s,
+		Logger:            l,
+		Config:            cfg,
+	}
+}
+
+const webhookPlace = "webhookService."
+
+func (ws *webhookService) Create(ctx context.Context, endpoint string, statuses []string) (*models.Webhook, error) {
+	op := webhookPlace + "Create"
+	log := ws.Logger.AddOp(op)
+	log.Info("creating webhook")
+	v := &validator{}
+	if v.required("url", endpoint) && v.maxLength("url", endpoint, models.URLMaxLength) {
+		v.url("url", endpoint)
+	}
+	for i, status := range statuses {
+		v.oneOf(fmt.Sprintf("statuses[%d]", i), status, models.Statuses())
+	}
+	if err := v.err(op); err != nil {
+		log.Error("invalid webhook", logger.Err(err))
+		return nil, err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Error("failed to generate webhook secret", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	hook := &models.Webhook{
+		ID:       uuid.New(),
+		URL:      endpoint,
+		Secret:   hex.EncodeToString(secret),
+		Statuses: append([]string{}, statuses...),
+	}
+	if err := ws.WebhookRepository.Create(ctx, hook); err != nil {
+		log.Error("failed to create webhook", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("webhook created", "webhook_id", hook.ID)
+	return hook, nil
+}
+
+func (ws *webhookService) GetById(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
+	op := webhookPlace + "GetById"
+	log := ws.Logger.AddOp(op)
+	log.Info("receiving webhook by id")
+	hook, err := ws.WebhookRepository.GetById(ctx, id)
+	if err != nil {
+		log.Error("failed to receive webhook by id", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("webhook received")
+	return hook, nil
+}
+
+func (ws *webhookService) Get(ctx context.Context) ([]models.Webhook, error) {
+	op := webhookPlace + "Get"
+	log := ws.Logger.AddOp(op)
+	log.Info("fetching webhooks")
+	webhooks, err := ws.WebhookRepository.Get(ctx)
+	if err != nil {
+		log.Error("failed to fetch webhooks", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("webhooks fetched")
+	return webhooks, nil
+}
+
+func (ws *webhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	op := webhookPlace + "Delete"
+	log := ws.Logger.AddOp(op)
+	log.Info("deleting webhook")
+	if err := ws.WebhookRepository.Delete(ctx, id); err != nil {
+		log.Error("failed to delete webhook", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("webhook deleted")
+	return nil
+}
+
+func (ws *webhookService) Enable(ctx context.Context, id uuid.UUID) error {
+	op := webhookPlace + "Enable"
+	log := ws.Logger.AddOp(op)
+	log.Info("enabling webhook")
+	if err := ws.WebhookRepository.Enable(ctx, id); err != nil {
+		log.Error("failed to enable webhook", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	log.Info("webhook enabled")
+	return nil
+}
+
+func (ws *webhookService) GetDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	op := webhookPlace + "GetDeliveries"
+	log := ws.Logger.AddOp(op)
+	log.Info("fetching webhook deliveries")
+	if _, err := ws.WebhookRepository.GetById(ctx, id); err != nil {
+		log.Error("failed to receive webhook by id", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	deliveries, err := ws.WebhookRepository.GetDeliveries(ctx, id, limit)
+	if err != nil {
+		log.Error("failed to fetch webhook deliveries", logger.Err(err))
+		return nil, errs.NewAppError(op, err)
+	}
+	log.Info("webhook deliveries fetched")
+	return deliveries, nil
+}
+
+// CallbackSecret returns the secret signing the task callbacks of the tenant
+// of ctx.
+func (ws *webhookService) CallbackSecret(ctx context.Context) (string, error) {
+	op := webhookPlace + "CallbackSecret"
+	log := ws.Logger.AddOp(op)
+	tenant := models.TenantFromContext(ctx)
+	if tenant == "" {
+		err := errs.NewAppError(op, repositories.ErrNoTenant)
+		log.Error("failed to derive callback secret", logger.Err(err))
+		return "", err
+	}
+	return webhook.CallbackSecret(ws.Config.CallbackSecret, tenant), nil
+}
+
+// Deliver sends one batch of due deliveries and returns how many were
+// claimed, so the caller can keep going while the batch comes back full.
+func (ws *webhookService) Deliver(ctx context.Context) (int, error) {
+	op := webhookPlace + "Deliver"
+	log := ws.Logger.AddOp(op)
+	deliveries, err := ws.WebhookRepository.ClaimDeliveries(ctx, ws.Config.BatchSize, ws.Config.Lease)
+	if err != nil {
+		log.Error("failed to claim webhook deliveries", logger.Err(err))
+		return 0, errs.NewAppError(op, err)
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+	log.Info("sending webhook deliveries", "amount", len(deliveries))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []error
+	)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ws.send(ctx, delivery); err != nil {
+				mu.Lock()
+				failures = append(failures, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(failures) > 0 {
+		return len(deliveries), errs.NewAppError(op, errors.Join(failures...))
+	}
+	return len(deliveries), nil
+}
+
+// send makes one attempt of the delivery and records its outcome. Failed
+// attempts are retried with exponential backoff until MaxAttempts is reached.
+func (ws *webhookService) send(ctx context.Context, delivery models.WebhookDelivery) error {
+	op := webhookPlace + "send"
+	log := ws.Logger.AddOp(op)
+
+	secret := delivery.Secret
+	if delivery.WebhookID == nil {
+		secret = webhook.CallbackSecret(ws.Config.CallbackSecret, delivery.Tenant)
+	}
+	header := http.Header{}
+	header.Set(webhook.DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	header.Set(webhook.EventHeader, webhookEvent)
+
+	start := time.Now()
+	statusCode, sendErr := ws.Sender.Send(ctx, delivery.URL, secret, delivery.Payload, header)
+	attempt := models.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts + 1,
+		StatusCode: statusCode,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	status, next := models.DeliveryDelivered, time.Now()
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+		status = models.DeliveryPending
+		next = next.Add(ws.backoff(attempt.Attempt))
+		if attempt.Attempt >= ws.Config.MaxAttempts {
+			status = models.DeliveryFailed
+		}
+		log.Info("webhook delivery attempt failed", "delivery_id", delivery.ID, "attempt", attempt.Attempt, "status", status, logger.Err(sendErr))
+	} else {
+		log.Info("webhook delivered", "delivery_id", delivery.ID, "attempt", attempt.Attempt)
+	}
+	if err := ws.WebhookRepository.RecordAttempt(ctx, attempt, status, next); err != nil {
+		log.Error("failed to record webhook attempt", "delivery_id", delivery.ID, logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+
+	if delivery.WebhookID == nil {
+		return nil
+	}
+	active, err := ws.WebhookRepository.TrackHealth(ctx, *delivery.WebhookID, sendErr == nil, ws.Config.DisableAfter)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFoundBase) {
+			return nil
+		}
+		log.Error("failed to track webhook health", logger.Err(err))
+		return errs.NewAppError(op, err)
+	}
+	if !active {
+		log.Info("webhook disabled after consecutive failures", "webhook_id", *delivery.WebhookID)
+	}
+	return nil
+}
+
+func (ws *webhookService) backoff(attempt int) time.Duration {
+	delay := ws.Config.BackoffBase
+	for i := 1; i < attempt && delay < ws.Config.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, ws.Config.BackoffMax)
+}
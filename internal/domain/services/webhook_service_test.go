@@ -0,0 +1,390 @@
+package services
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/repositories"
+	"betera-tz/pkg/errs"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/webhook"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetById(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Get(ctx context.Context) ([]models.Webhook, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Enable(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RecordAttempt(ctx context.Context, attempt models.WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, attempt, status, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) TrackHealth(ctx context.Context, id uuid.UUID, success bool, disableAfter int) (bool, error) {
+	args := m.Called(ctx, id, success, disableAfter)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveries(ctx context.Context, webhookId uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookId, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+var testWebhooksConfig = config.WebhooksConfig{
+	CallbackSecret: "callback-secret",
+	Timeout:        time.Second,
+	BatchSize:      10,
+	Lease:          time.Minute,
+	MaxAttempts:    3,
+	BackoffBase:    time.Second,
+	BackoffMax:     10 * time.Second,
+	DisableAfter:   5,
+}
+
+// testSender delivers to the local receivers, which the sender of NewSender
+// refuses to connect to.
+var testSender = &webhook.Sender{Client: &http.Client{Timeout: time.Second}}
+
+// receiver is a local webhook endpoint recording the requests whose
+// signature it could verify.
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	verified [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if webhook.Verify(rc.secret, r.Header.Get(webhook.SignatureHeader), body, time.Minute) == nil {
+		rc.verified = append(rc.verified, body)
+	}
+	w.WriteHeader(rc.status)
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		statuses      []string
+		mockSetup     func(*MockWebhookRepository)
+		expectedError bool
+		expectedErrIs error
+	}{
+		{
+			name:     "successful webhook creation",
+			url:      "https://example.com/hooks",
+			statuses: []string{"done"},
+			mockSetup: func(mockRepo *MockWebhookRepository) {
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(webhook *models.Webhook) bool {
+					return webhook.URL == "https://example.com/hooks" && len(webhook.Secret) == 64
+				})).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:          "invalid url scheme",
+			url:           "ftp://example.com/hooks",
+			mockSetup:     func(mockRepo *MockWebhookRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "url without host",
+			url:           "https:///hooks",
+			mockSetup:     func(mockRepo *MockWebhookRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "loopback url",
+			url:           "http://127.0.0.1:8080/hooks",
+			mockSetup:     func(mockRepo *MockWebhookRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "private url",
+			url:           "http://10.0.0.5/hooks",
+			mockSetup:     func(mockRepo *MockWebhookRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "link-local url",
+			url:           "http://169.254.169.254/latest/meta-data",
+			mockSetup:     func(mockRepo *MockWebhookRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "localhost url",
+			url:           "http://localhost/hooks",
+			mockSetup:     func(mockRepo *MockWebhookRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+		{
+			name:          "unknown status",
+			url:           "https://example.com/hooks",
+			statuses:      []string{"archived"},
+			mockSetup:     func(mockRepo *MockWebhookRepository) {},
+			expectedError: true,
+			expectedErrIs: errs.ErrInvalidValuesBase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockWebhookRepository)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+			tt.mockSetup(mockRepo)
+
+			service := NewWebhookService(mockRepo, testSender, logger, testWebhooksConfig)
+
+			result, err := service.Create(context.Background(), tt.url, tt.statuses)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.url, result.URL)
+				assert.NotNil(t, result.Statuses)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	webhookId := uuid.MustParse("9b2d3c1e-7f4a-4e0b-8c6d-2a1f5e3b7c90")
+	taskId := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	payload := []byte(`{"event":"task.status_changed","status":"done"}`)
+	tests := []struct {
+		name            string
+		delivery        models.WebhookDelivery
+		receiverSecret  string
+		receiverStatus  string
+		statusCode      int
+		mockSetup       func(*MockWebhookRepository)
+		expectedStatus  string
+		expectedBackoff time.Duration
+		expectedSigned  int
+	}{
+		{
+			name:           "delivered to webhook",
+			delivery:       models.WebhookDelivery{ID: 1, WebhookID: &webhookId, TaskID: taskId, Secret: "webhook-secret", Payload: payload},
+			receiverSecret: "webhook-secret",
+			statusCode:     http.StatusNoContent,
+			mockSetup: func(mockRepo *MockWebhookRepository) {
+				mockRepo.On("TrackHealth", mock.Anything, webhookId, true, 5).Return(true, nil)
+			},
+			expectedStatus: models.DeliveryDelivered,
+			expectedSigned: 1,
+		},
+		{
+			name:           "delivered to task callback with the callback secret of its tenant",
+			delivery:       models.WebhookDelivery{ID: 2, TaskID: taskId, Tenant: "team-a", Payload: payload},
+			receiverSecret: webhook.CallbackSecret("callback-secret", "team-a"),
+			statusCode:     http.StatusOK,
+			mockSetup:      func(mockRepo *MockWebhookRepository) {},
+			expectedStatus: models.DeliveryDelivered,
+			expectedSigned: 1,
+		},
+		{
+			name:           "task callback of another tenant is not signed with its secret",
+			delivery:       models.WebhookDelivery{ID: 6, TaskID: taskId, Tenant: "team-b", Payload: payload},
+			receiverSecret: webhook.CallbackSecret("callback-secret", "team-a"),
+			statusCode:     http.StatusOK,
+			mockSetup:      func(mockRepo *MockWebhookRepository) {},
+			expectedStatus: models.DeliveryDelivered,
+			expectedSigned: 0,
+		},
+		{
+			name:           "task callback is not signed with the configured secret",
+			delivery:       models.WebhookDelivery{ID: 7, TaskID: taskId, Tenant: "team-a", Payload: payload},
+			receiverSecret: "callback-secret",
+			statusCode:     http.StatusOK,
+			mockSetup:      func(mockRepo *MockWebhookRepository) {},
+			expectedStatus: models.DeliveryDelivered,
+			expectedSigned: 0,
+		},
+		{
+			name:           "failed attempt is retried with backoff",
+			delivery:       models.WebhookDelivery{ID: 3, WebhookID: &webhookId, TaskID: taskId, Secret: "webhook-secret", Payload: payload, Attempts: 1},
+			receiverSecret: "webhook-secret",
+			statusCode:     http.StatusServiceUnavailable,
+			mockSetup: func(mockRepo *MockWebhookRepository) {
+				mockRepo.On("TrackHealth", mock.Anything, webhookId, false, 5).Return(true, nil)
+			},
+			expectedStatus:  models.DeliveryPending,
+			expectedBackoff: 2 * time.Second,
+			expectedSigned:  1,
+		},
+		{
+			name:           "last attempt fails the delivery and disables the webhook",
+			delivery:       models.WebhookDelivery{ID: 4, WebhookID: &webhookId, TaskID: taskId, Secret: "webhook-secret", Payload: payload, Attempts: 2},
+			receiverSecret: "webhook-secret",
+			statusCode:     http.StatusInternalServerError,
+			mockSetup: func(mockRepo *MockWebhookRepository) {
+				mockRepo.On("TrackHealth", mock.Anything, webhookId, false, 5).Return(false, nil)
+			},
+			expectedStatus:  models.DeliveryFailed,
+			expectedBackoff: 4 * time.Second,
+			expectedSigned:  1,
+		},
+		{
+			name:           "signature with wrong secret is rejected by receiver",
+			delivery:       models.WebhookDelivery{ID: 5, WebhookID: &webhookId, TaskID: taskId, Secret: "rotated-secret", Payload: payload},
+			receiverSecret: "webhook-secret",
+			statusCode:     http.StatusOK,
+			mockSetup: func(mockRepo *MockWebhookRepository) {
+				mockRepo.On("TrackHealth", mock.Anything, webhookId, true, 5).Return(true, nil)
+			},
+			expectedStatus: models.DeliveryDelivered,
+			expectedSigned: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{secret: tt.receiverSecret, status: tt.statusCode}
+			srv := httptest.NewServer(rc)
+			defer srv.Close()
+
+			mockRepo := new(MockWebhookRepository)
+			logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+			delivery := tt.delivery
+			delivery.URL = srv.URL
+			mockRepo.On("ClaimDeliveries", mock.Anything, 10, time.Minute).Return([]models.WebhookDelivery{delivery}, nil)
+			before := time.Now()
+			mockRepo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(attempt models.WebhookAttempt) bool {
+				return attempt.DeliveryID == delivery.ID && attempt.Attempt == delivery.Attempts+1 && attempt.StatusCode == tt.statusCode
+			}), tt.expectedStatus, mock.MatchedBy(func(next time.Time) bool {
+				return !next.Before(before.Add(tt.expectedBackoff)) && next.Before(time.Now().Add(tt.expectedBackoff+time.Second))
+			})).Return(nil)
+			tt.mockSetup(mockRepo)
+
+			service := NewWebhookService(mockRepo, testSender, logger, testWebhooksConfig)
+
+			claimed, err := service.Deliver(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, claimed)
+			rc.mu.Lock()
+			assert.Len(t, rc.verified, tt.expectedSigned)
+			if tt.expectedSigned > 0 {
+				assert.Equal(t, payload, rc.verified[0])
+			}
+			rc.mu.Unlock()
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookService_DeliverRecordError(t *testing.T) {
+	srv := httptest.NewServer(&receiver{status: http.StatusOK})
+	defer srv.Close()
+
+	mockRepo := new(MockWebhookRepository)
+	logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+
+	mockRepo.On("ClaimDeliveries", mock.Anything, 10, time.Minute).Return([]models.WebhookDelivery{
+		{ID: 1, URL: srv.URL, Payload: []byte(`{}`)},
+	}, nil)
+	mockRepo.On("RecordAttempt", mock.Anything, mock.Anything, models.DeliveryDelivered, mock.Anything).Return(errors.New("database error"))
+
+	service := NewWebhookService(mockRepo, testSender, logger, testWebhooksConfig)
+
+	claimed, err := service.Deliver(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, claimed)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestNewWebhookService_CallbackSecretRequired(t *testing.T) {
+	logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+	cfg := testWebhooksConfig
+	cfg.CallbackSecret = ""
+
+	assert.Panics(t, func() {
+		NewWebhookService(new(MockWebhookRepository), webhook.NewSender(cfg), logger, cfg)
+	})
+}
+
+func TestWebhookService_CallbackSecret(t *testing.T) {
+	logger := logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+	service := NewWebhookService(new(MockWebhookRepository), testSender, logger, testWebhooksConfig)
+
+	teamA, err := service.CallbackSecret(models.WithTenant(context.Background(), "team-a"))
+	assert.NoError(t, err)
+	assert.Equal(t, webhook.CallbackSecret("callback-secret", "team-a"), teamA)
+	teamB, err := service.CallbackSecret(models.WithTenant(context.Background(), "team-b"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, teamA, teamB)
+	assert.NotEqual(t, "callback-secret", teamA)
+
+	_, err = service.CallbackSecret(context.Background())
+	assert.ErrorIs(t, err, repositories.ErrNoTenant)
+}
@@ -4,9 +4,78 @@
 package dto
 
 import (
+	"time"
+
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for BulkTaskRequestAction.
+const (
+	BulkTaskRequestActionDelete       BulkTaskRequestAction = "delete"
+	BulkTaskRequestActionUpdateStatus BulkTaskRequestAction = "updateStatus"
+)
+
+// Defines values for BulkTaskRequestStatus.
+const (
+	BulkTaskRequestStatusCreated    BulkTaskRequestStatus = "created"
+	BulkTaskRequestStatusDone       BulkTaskRequestStatus = "done"
+	BulkTaskRequestStatusProcessing BulkTaskRequestStatus = "processing"
+)
+
+// Defines values for CreateApiKeyRequestScopes.
+const (
+	CreateApiKeyRequestScopesAdmin      CreateApiKeyRequestScopes = "admin"
+	CreateApiKeyRequestScopesTasksRead  CreateApiKeyRequestScopes = "tasks:read"
+	CreateApiKeyRequestScopesTasksWrite CreateApiKeyRequestScopes = "tasks:write"
+)
+
+// Defines values for CreateWebhookRequestStatuses.
+const (
+	CreateWebhookRequestStatusesCreated    CreateWebhookRequestStatuses = "created"
+	CreateWebhookRequestStatusesDone       CreateWebhookRequestStatuses = "done"
+	CreateWebhookRequestStatusesProcessing CreateWebhookRequestStatuses = "processing"
+)
+
+// Defines values for ImportResponseFormat.
+const (
+	ImportResponseFormatCsv    ImportResponseFormat = "csv"
+	ImportResponseFormatNdjson ImportResponseFormat = "ndjson"
+)
+
+// Defines values for ImportResponseStatus.
+const (
+	ImportResponseStatusDone       ImportResponseStatus = "done"
+	ImportResponseStatusFailed     ImportResponseStatus = "failed"
+	ImportResponseStatusPending    ImportResponseStatus = "pending"
+	ImportResponseStatusProcessing ImportResponseStatus = "processing"
+)
+
+// Defines values for ProblemCode.
+const (
+	ProblemCodeAlreadyExists          ProblemCode = "already_exists"
+	ProblemCodeBadRequest             ProblemCode = "bad_request"
+	ProblemCodeForbidden              ProblemCode = "forbidden"
+	ProblemCodeIdempotencyKeyMismatch ProblemCode = "idempotency_key_mismatch"
+	ProblemCodeInternalError          ProblemCode = "internal_error"
+	ProblemCodeMethodNotAllowed       ProblemCode = "method_not_allowed"
+	ProblemCodeNotFound               ProblemCode = "not_found"
+	ProblemCodePayloadTooLarge        ProblemCode = "payload_too_large"
+	ProblemCodePreconditionFailed     ProblemCode = "precondition_failed"
+	ProblemCodeQuotaExceeded          ProblemCode = "quota_exceeded"
+	ProblemCodeRateLimited            ProblemCode = "rate_limited"
+	ProblemCodeRequestInProgress      ProblemCode = "request_in_progress"
+	ProblemCodeRequestTimeout         ProblemCode = "request_timeout"
+	ProblemCodeUnauthorized           ProblemCode = "unauthorized"
+	ProblemCodeValidationFailed       ProblemCode = "validation_failed"
+)
+
+// Defines values for TaskFilterStatusFilter.
+const (
+	TaskFilterStatusFilterCreated    TaskFilterStatusFilter = "created"
+	TaskFilterStatusFilterDone       TaskFilterStatusFilter = "done"
+	TaskFilterStatusFilterProcessing TaskFilterStatusFilter = "processing"
+)
+
 // Defines values for TaskResponseStatus.
 const (
 	TaskResponseStatusCreated    TaskResponseStatus = "created"
@@ -14,6 +83,20 @@ const (
 	TaskResponseStatusProcessing TaskResponseStatus = "processing"
 )
 
+// Defines values for WebhookDeliveryStatus.
+const (
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+)
+
+// Defines values for GetEventsParamsStatus.
+const (
+	GetEventsParamsStatusCreated    GetEventsParamsStatus = "created"
+	GetEventsParamsStatusDone       GetEventsParamsStatus = "done"
+	GetEventsParamsStatusProcessing GetEventsParamsStatus = "processing"
+)
+
 // Defines values for GetTasksParamsStatusFilter.
 const (
 	GetTasksParamsStatusFilterCreated    GetTasksParamsStatusFilter = "created"
@@ -21,16 +104,123 @@ const (
 	GetTasksParamsStatusFilterProcessing GetTasksParamsStatusFilter = "processing"
 )
 
+// Defines values for GetTasksExportParamsFormat.
+const (
+	GetTasksExportParamsFormatCsv    GetTasksExportParamsFormat = "csv"
+	GetTasksExportParamsFormatNdjson GetTasksExportParamsFormat = "ndjson"
+)
+
+// Defines values for GetTasksExportParamsStatusFilter.
+const (
+	GetTasksExportParamsStatusFilterCreated    GetTasksExportParamsStatusFilter = "created"
+	GetTasksExportParamsStatusFilterDone       GetTasksExportParamsStatusFilter = "done"
+	GetTasksExportParamsStatusFilterProcessing GetTasksExportParamsStatusFilter = "processing"
+)
+
+// Defines values for PostTasksImportParamsFormat.
+const (
+	PostTasksImportParamsFormatCsv    PostTasksImportParamsFormat = "csv"
+	PostTasksImportParamsFormatNdjson PostTasksImportParamsFormat = "ndjson"
+)
+
+// Defines values for GetTasksIdParamsWaitFor.
+const (
+	GetTasksIdParamsWaitForCreated    GetTasksIdParamsWaitFor = "created"
+	GetTasksIdParamsWaitForDone       GetTasksIdParamsWaitFor = "done"
+	GetTasksIdParamsWaitForProcessing GetTasksIdParamsWaitFor = "processing"
+)
+
+// ApiKeyResponse defines model for ApiKeyResponse.
+type ApiKeyResponse struct {
+	CreatedAt  time.Time          `json:"createdAt"`
+	Id         openapi_types.UUID `json:"id"`
+	LastUsedAt *time.Time         `json:"lastUsedAt,omitempty"`
+	Name       string             `json:"name"`
+
+	// Prefix First characters of the key to tell keys apart
+	Prefix    string     `json:"prefix"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	Tenant    *string    `json:"tenant,omitempty"`
+}
+
 // ApiResponse defines model for ApiResponse.
 type ApiResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
+// AssignTaskRequest defines model for AssignTaskRequest.
+type AssignTaskRequest struct {
+	// Assignee Caller id or me for the caller; null, empty or none unassigns the task
+	Assignee *string `json:"assignee"`
+}
+
+// BulkTaskRequest defines model for BulkTaskRequest.
+type BulkTaskRequest struct {
+	Action BulkTaskRequestAction `json:"action"`
+
+	// All Select every task of the tenant. Required when neither ids nor a non-empty filter is given
+	All    *bool                  `json:"all,omitempty"`
+	Filter *TaskFilter            `json:"filter,omitempty"`
+	Ids    *[]openapi_types.UUID  `json:"ids,omitempty"`
+	Status *BulkTaskRequestStatus `json:"status,omitempty"`
+}
+
+// BulkTaskRequestAction defines model for BulkTaskRequest.Action.
+type BulkTaskRequestAction string
+
+// BulkTaskRequestStatus defines model for BulkTaskRequest.Status.
+type BulkTaskRequestStatus string
+
+// BulkTaskResponse defines model for BulkTaskResponse.
+type BulkTaskResponse struct {
+	Affected int  `json:"affected"`
+	DryRun   bool `json:"dryRun"`
+	Matched  int  `json:"matched"`
+	Skipped  int  `json:"skipped"`
+}
+
+// CallbackSecretResponse defines model for CallbackSecretResponse.
+type CallbackSecretResponse struct {
+	// Secret Signs the callbackUrl deliveries of the tasks of the tenant
+	Secret string `json:"secret"`
+}
+
+// CreateApiKeyRequest defines model for CreateApiKeyRequest.
+type CreateApiKeyRequest struct {
+	Name   string                      `json:"name"`
+	Scopes []CreateApiKeyRequestScopes `json:"scopes"`
+
+	// Tenant Tenant the key is bound to. A key without one uses the default tenant, or any tenant chosen with X-Tenant-ID when it has the admin scope.
+	Tenant *string `json:"tenant,omitempty"`
+}
+
+// CreateApiKeyRequestScopes defines model for CreateApiKeyRequest.Scopes.
+type CreateApiKeyRequestScopes string
+
+// CreateApiKeyResponse defines model for CreateApiKeyResponse.
+type CreateApiKeyResponse struct {
+	CreatedAt  time.Time          `json:"createdAt"`
+	Id         openapi_types.UUID `json:"id"`
+	Key        string             `json:"key"`
+	LastUsedAt *time.Time         `json:"lastUsedAt,omitempty"`
+	Name       string             `json:"name"`
+
+	// Prefix First characters of the key to tell keys apart
+	Prefix    string     `json:"prefix"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	Tenant    *string    `json:"tenant,omitempty"`
+}
+
 // CreateTaskRequest defines model for CreateTaskRequest.
 type CreateTaskRequest struct {
-	Description string `json:"description"`
-	Title       string `json:"title"`
+	CallbackUrl *string `json:"callbackUrl,omitempty"`
+	Description string  `json:"description"`
+	Title       string  `json:"title"`
 }
 
 // CreateTaskResponse defines model for CreateTaskResponse.
@@ -38,31 +228,351 @@ type CreateTaskResponse struct {
 	Id openapi_types.UUID `json:"id"`
 }
 
+// CreateWebhookRequest defines model for CreateWebhookRequest.
+type CreateWebhookRequest struct {
+	// Statuses Statuses to be notified about, all when empty
+	Statuses *[]CreateWebhookRequestStatuses `json:"statuses,omitempty"`
+	Url      string                          `json:"url"`
+}
+
+// CreateWebhookRequestStatuses defines model for CreateWebhookRequest.Statuses.
+type CreateWebhookRequestStatuses string
+
+// CreateWebhookResponse defines model for CreateWebhookResponse.
+type CreateWebhookResponse struct {
+	Active       bool               `json:"active"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	DisabledAt   *time.Time         `json:"disabledAt,omitempty"`
+	FailureCount int                `json:"failureCount"`
+	Id           openapi_types.UUID `json:"id"`
+	Secret       string             `json:"secret"`
+	Statuses     []string           `json:"statuses"`
+	Url          string             `json:"url"`
+}
+
+// DurationStats Seconds from the start of processing to done for tasks finished within the window
+type DurationStats struct {
+	Avg   float64 `json:"avg"`
+	Count int     `json:"count"`
+	Max   float64 `json:"max"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+// ImportResponse defines model for ImportResponse.
+type ImportResponse struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// CreatedBy Caller id of the uploader, recorded as creator of the imported tasks
+	CreatedBy *string `json:"createdBy,omitempty"`
+	Enqueue   bool    `json:"enqueue"`
+
+	// Error Reason the whole import failed
+	Error         *string              `json:"error,omitempty"`
+	FailedRows    int                  `json:"failedRows"`
+	FinishedAt    *time.Time           `json:"finishedAt,omitempty"`
+	Format        ImportResponseFormat `json:"format"`
+	Id            openapi_types.UUID   `json:"id"`
+	ImportedRows  int                  `json:"importedRows"`
+	ProcessedRows int                  `json:"processedRows"`
+	Status        ImportResponseStatus `json:"status"`
+	UpdatedAt     time.Time            `json:"updatedAt"`
+}
+
+// ImportResponseFormat defines model for ImportResponse.Format.
+type ImportResponseFormat string
+
+// ImportResponseStatus defines model for ImportResponse.Status.
+type ImportResponseStatus string
+
+// Problem Error response as described by RFC 7807
+type Problem struct {
+	// Code Stable machine-readable error code
+	Code      ProblemCode `json:"code"`
+	Detail    *string     `json:"detail,omitempty"`
+	Instance  *string     `json:"instance,omitempty"`
+	RequestId *string     `json:"requestId,omitempty"`
+	Status    int         `json:"status"`
+	Title     string      `json:"title"`
+	Type      string      `json:"type"`
+
+	// Violations Every invalid field of the request, set for validation_failed
+	Violations *[]Violation `json:"violations,omitempty"`
+}
+
+// ProblemCode Stable machine-readable error code
+type ProblemCode string
+
+// QuotaResponse defines model for QuotaResponse.
+type QuotaResponse struct {
+	// MaxActive Tasks created or processing at once
+	MaxActive int `json:"maxActive"`
+
+	// MaxDaily Tasks created per UTC day
+	MaxDaily int `json:"maxDaily"`
+
+	// MaxProcessing Tasks processing at once
+	MaxProcessing int    `json:"maxProcessing"`
+	Tenant        string `json:"tenant"`
+
+	// UpdatedAt Unset for the default quota
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// QuotaUsageResponse defines model for QuotaUsageResponse.
+type QuotaUsageResponse struct {
+	// MaxActive Tasks created or processing at once
+	MaxActive int `json:"maxActive"`
+
+	// MaxDaily Tasks created per UTC day
+	MaxDaily int `json:"maxDaily"`
+
+	// MaxProcessing Tasks processing at once
+	MaxProcessing int    `json:"maxProcessing"`
+	Tenant        string `json:"tenant"`
+
+	// UpdatedAt Unset for the default quota
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	Usage     struct {
+		Active       int `json:"active"`
+		CreatedToday int `json:"createdToday"`
+		Processing   int `json:"processing"`
+	} `json:"usage"`
+}
+
+// SetQuotaRequest Limits of the tasks of a tenant; zero is unlimited
+type SetQuotaRequest struct {
+	// MaxActive Tasks created or processing at once
+	MaxActive int `json:"maxActive"`
+
+	// MaxDaily Tasks created per UTC day
+	MaxDaily int `json:"maxDaily"`
+
+	// MaxProcessing Tasks processing at once
+	MaxProcessing int `json:"maxProcessing"`
+}
+
+// StatsBucket defines model for StatsBucket.
+type StatsBucket struct {
+	Created  int       `json:"created"`
+	Finished int       `json:"finished"`
+	Start    time.Time `json:"start"`
+}
+
+// TaskFilter defines model for TaskFilter.
+type TaskFilter struct {
+	// Assignee Caller id of the assignee, me for the caller or none for unassigned tasks
+	Assignee *string `json:"assignee,omitempty"`
+
+	// CreatedBy Caller id of the creator, me for the caller or none for tasks without one
+	CreatedBy    *string                 `json:"createdBy,omitempty"`
+	StatusFilter *TaskFilterStatusFilter `json:"statusFilter,omitempty"`
+}
+
+// TaskFilterStatusFilter defines model for TaskFilter.StatusFilter.
+type TaskFilterStatusFilter string
+
 // TaskResponse defines model for TaskResponse.
 type TaskResponse struct {
+	// Assignee Caller id of the assignee
+	Assignee    *string `json:"assignee,omitempty"`
+	CallbackUrl *string `json:"callbackUrl,omitempty"`
+
+	// CreatedBy Caller id of the creator
+	CreatedBy   *string            `json:"createdBy,omitempty"`
 	Description string             `json:"description"`
 	Id          openapi_types.UUID `json:"id"`
+
+	// ProcessedBy Worker that last started processing the task
+	ProcessedBy *string            `json:"processedBy,omitempty"`
 	Status      TaskResponseStatus `json:"status"`
 	Title       string             `json:"title"`
+	Version     int                `json:"version"`
 }
 
 // TaskResponseStatus defines model for TaskResponse.Status.
 type TaskResponseStatus string
 
+// TaskStatsResponse defines model for TaskStatsResponse.
+type TaskStatsResponse struct {
+	Bucket  string        `json:"bucket"`
+	Buckets []StatsBucket `json:"buckets"`
+
+	// Counts Number of all tasks by status
+	Counts map[string]int `json:"counts"`
+
+	// From Start of the first bucket
+	From time.Time `json:"from"`
+
+	// Processing Seconds from the start of processing to done for tasks finished within the window
+	Processing DurationStats `json:"processing"`
+	To         time.Time     `json:"to"`
+
+	// Total Number of all tasks
+	Total int `json:"total"`
+}
+
+// Violation defines model for Violation.
+type Violation struct {
+	// Field Path to the invalid field, empty for the request body itself
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// WebhookAttempt defines model for WebhookAttempt.
+type WebhookAttempt struct {
+	Attempt    int       `json:"attempt"`
+	CreatedAt  time.Time `json:"createdAt"`
+	DurationMs int       `json:"durationMs"`
+	Error      *string   `json:"error,omitempty"`
+	StatusCode *int      `json:"statusCode,omitempty"`
+}
+
+// WebhookDelivery defines model for WebhookDelivery.
+type WebhookDelivery struct {
+	AttemptLog     []WebhookAttempt      `json:"attemptLog"`
+	Attempts       int                   `json:"attempts"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	Id             int64                 `json:"id"`
+	LastError      *string               `json:"lastError,omitempty"`
+	LastStatusCode *int                  `json:"lastStatusCode,omitempty"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	TaskId         openapi_types.UUID    `json:"taskId"`
+	Url            string                `json:"url"`
+	WebhookId      *openapi_types.UUID   `json:"webhookId,omitempty"`
+}
+
+// WebhookDeliveryStatus defines model for WebhookDelivery.Status.
+type WebhookDeliveryStatus string
+
+// WebhookResponse defines model for WebhookResponse.
+type WebhookResponse struct {
+	Active       bool               `json:"active"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	DisabledAt   *time.Time         `json:"disabledAt,omitempty"`
+	FailureCount int                `json:"failureCount"`
+	Id           openapi_types.UUID `json:"id"`
+	Statuses     []string           `json:"statuses"`
+	Url          string             `json:"url"`
+}
+
+// GetEventsParams defines parameters for GetEvents.
+type GetEventsParams struct {
+	TaskId      *openapi_types.UUID    `form:"taskId,omitempty" json:"taskId,omitempty"`
+	Status      *GetEventsParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+	LastEventID *string                `json:"Last-Event-ID,omitempty"`
+}
+
+// GetEventsParamsStatus defines parameters for GetEvents.
+type GetEventsParamsStatus string
+
 // GetTasksParams defines parameters for GetTasks.
 type GetTasksParams struct {
 	Amount       *int                        `form:"amount,omitempty" json:"amount,omitempty"`
 	Page         *int                        `form:"page,omitempty" json:"page,omitempty"`
 	StatusFilter *GetTasksParamsStatusFilter `form:"statusFilter,omitempty" json:"statusFilter,omitempty"`
+
+	// CreatedBy Caller id of the creator, me for the caller or none for tasks without one
+	CreatedBy *string `form:"createdBy,omitempty" json:"createdBy,omitempty"`
+
+	// Assignee Caller id of the assignee, me for the caller or none for unassigned tasks
+	Assignee *string `form:"assignee,omitempty" json:"assignee,omitempty"`
 }
 
 // GetTasksParamsStatusFilter defines parameters for GetTasks.
 type GetTasksParamsStatusFilter string
 
+// PostTasksParams defines parameters for PostTasks.
+type PostTasksParams struct {
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
+}
+
+// PostTasksBulkParams defines parameters for PostTasksBulk.
+type PostTasksBulkParams struct {
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+}
+
+// GetTasksExportParams defines parameters for GetTasksExport.
+type GetTasksExportParams struct {
+	Format       *GetTasksExportParamsFormat       `form:"format,omitempty" json:"format,omitempty"`
+	StatusFilter *GetTasksExportParamsStatusFilter `form:"statusFilter,omitempty" json:"statusFilter,omitempty"`
+
+	// CreatedBy Caller id of the creator, me for the caller or none for tasks without one
+	CreatedBy *string `form:"createdBy,omitempty" json:"createdBy,omitempty"`
+
+	// Assignee Caller id of the assignee, me for the caller or none for unassigned tasks
+	Assignee *string `form:"assignee,omitempty" json:"assignee,omitempty"`
+}
+
+// GetTasksExportParamsFormat defines parameters for GetTasksExport.
+type GetTasksExportParamsFormat string
+
+// GetTasksExportParamsStatusFilter defines parameters for GetTasksExport.
+type GetTasksExportParamsStatusFilter string
+
+// PostTasksImportParams defines parameters for PostTasksImport.
+type PostTasksImportParams struct {
+	// Format File format. Taken from Content-Type when omitted
+	Format *PostTasksImportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Enqueue Send imported tasks with status created to the processing queue
+	Enqueue *bool `form:"enqueue,omitempty" json:"enqueue,omitempty"`
+}
+
+// PostTasksImportParamsFormat defines parameters for PostTasksImport.
+type PostTasksImportParamsFormat string
+
+// GetTasksStatsParams defines parameters for GetTasksStats.
+type GetTasksStatsParams struct {
+	// Window How far back to aggregate, as a duration up to 90 days
+	Window *string `form:"window,omitempty" json:"window,omitempty"`
+
+	// Bucket Size of a time bucket, at least one minute and at most 1000 buckets per window
+	Bucket *string `form:"bucket,omitempty" json:"bucket,omitempty"`
+}
+
+// GetTasksIdParams defines parameters for GetTasksId.
+type GetTasksIdParams struct {
+	WaitFor     *[]GetTasksIdParamsWaitFor `form:"waitFor,omitempty" json:"waitFor,omitempty"`
+	Timeout     *string                    `form:"timeout,omitempty" json:"timeout,omitempty"`
+	IfNoneMatch *string                    `json:"If-None-Match,omitempty"`
+}
+
+// GetTasksIdParamsWaitFor defines parameters for GetTasksId.
+type GetTasksIdParamsWaitFor string
+
+// PostTasksIdAssignParams defines parameters for PostTasksIdAssign.
+type PostTasksIdAssignParams struct {
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
 // PatchTasksIdStatusParams defines parameters for PatchTasksIdStatus.
 type PatchTasksIdStatusParams struct {
-	Status string `form:"status" json:"status"`
+	Status  string  `form:"status" json:"status"`
+	IfMatch *string `json:"If-Match,omitempty"`
 }
 
+// GetWebhooksIdDeliveriesParams defines parameters for GetWebhooksIdDeliveries.
+type GetWebhooksIdDeliveriesParams struct {
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostAdminApiKeysJSONRequestBody defines body for PostAdminApiKeys for application/json ContentType.
+type PostAdminApiKeysJSONRequestBody = CreateApiKeyRequest
+
+// PutAdminQuotasTenantJSONRequestBody defines body for PutAdminQuotasTenant for application/json ContentType.
+type PutAdminQuotasTenantJSONRequestBody = SetQuotaRequest
+
 // PostTasksJSONRequestBody defines body for PostTasks for application/json ContentType.
 type PostTasksJSONRequestBody = CreateTaskRequest
+
+// PostTasksBulkJSONRequestBody defines body for PostTasksBulk for application/json ContentType.
+type PostTasksBulkJSONRequestBody = BulkTaskRequest
+
+// PostTasksIdAssignJSONRequestBody defines body for PostTasksIdAssign for application/json ContentType.
+type PostTasksIdAssignJSONRequestBody = AssignTaskRequest
+
+// PostWebhooksJSONRequestBody defines body for PostWebhooks for application/json ContentType.
+type PostWebhooksJSONRequestBody = CreateWebhookRequest
@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS task_events(
+    id BIGSERIAL PRIMARY KEY,
+    task_id UUID NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    status VARCHAR(10),
+    version INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON task_events (task_id)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_task_event() RETURNS TRIGGER AS $$
+DECLARE
+    row_data RECORD;
+    event_type VARCHAR(16);
+    event task_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := OLD;
+        event_type := 'deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        row_data := NEW;
+        event_type := 'created';
+    ELSE
+        row_data := NEW;
+        event_type := 'updated';
+    END IF;
+    INSERT INTO task_events (task_id, type, status, version)
+    VALUES (row_data.id, event_type, row_data.status, row_data.version)
+    RETURNING * INTO event;
+    PERFORM pg_notify('task_events', json_build_object(
+        'id', event.id,
+        'taskId', event.task_id,
+        'type', event.type,
+        'status', event.status,
+        'version', event.version,
+        'createdAt', event.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER tasks_notify_event
+AFTER INSERT OR UPDATE OR DELETE ON tasks
+FOR EACH ROW EXECUTE FUNCTION notify_task_event()
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS tasks_notify_event ON tasks
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS notify_task_event()
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_events
+-- +goose StatementEnd
@@ -0,0 +1,121 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS callback_url VARCHAR(2048)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhooks(
+    id UUID PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    statuses VARCHAR(10)[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT true,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id UUID REFERENCES webhooks (id) ON DELETE CASCADE,
+    task_id UUID NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_status_code INTEGER,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending'
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_attempts(
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS webhook_attempts_delivery_id_idx ON webhook_attempts (delivery_id)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries() RETURNS TRIGGER AS $$
+DECLARE
+    task tasks%ROWTYPE;
+    payload JSONB;
+BEGIN
+    SELECT * INTO task FROM tasks WHERE id = NEW.task_id;
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+    payload := jsonb_build_object(
+        'event', 'task.status_changed',
+        'eventId', NEW.id,
+        'taskId', task.id,
+        'title', task.title,
+        'status', NEW.status,
+        'version', NEW.version,
+        'occurredAt', NEW.created_at
+    );
+    INSERT INTO webhook_deliveries (webhook_id, task_id, url, payload)
+    SELECT w.id, task.id, w.url, payload FROM webhooks w
+    WHERE w.active AND (cardinality(w.statuses) = 0 OR NEW.status = ANY (w.statuses));
+    IF task.callback_url IS NOT NULL THEN
+        INSERT INTO webhook_deliveries (task_id, url, payload)
+        VALUES (task.id, task.callback_url, payload);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER task_events_enqueue_webhooks
+AFTER INSERT ON task_events
+FOR EACH ROW WHEN (NEW.type = 'updated')
+EXECUTE FUNCTION enqueue_webhook_deliveries()
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS task_events_enqueue_webhooks ON task_events
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS enqueue_webhook_deliveries()
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_attempts
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_deliveries
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhooks
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks DROP COLUMN IF EXISTS callback_url
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS imports(
+    id UUID PRIMARY KEY,
+    format VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'done', 'failed')),
+    enqueue BOOLEAN NOT NULL DEFAULT false,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    imported_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS imports_unfinished_idx ON imports (updated_at) WHERE status IN ('pending', 'processing')
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS import_errors(
+    id BIGSERIAL PRIMARY KEY,
+    import_id UUID NOT NULL REFERENCES imports (id) ON DELETE CASCADE,
+    row INTEGER NOT NULL,
+    field VARCHAR(64) NOT NULL DEFAULT '',
+    message TEXT NOT NULL
+)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS import_errors_import_id_idx ON import_errors (import_id, row)
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS import_errors
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS imports
+-- +goose StatementEnd
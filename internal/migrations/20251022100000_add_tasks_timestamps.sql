@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE tasks t SET
+    created_at = COALESCE(e.created_at, t.created_at),
+    started_at = CASE WHEN t.status IN ('processing', 'done') THEN e.started_at END,
+    finished_at = CASE WHEN t.status = 'done' THEN e.finished_at END
+FROM (
+    SELECT task_id,
+        min(created_at) FILTER (WHERE type = 'created') AS created_at,
+        max(created_at) FILTER (WHERE type = 'updated' AND status = 'processing') AS started_at,
+        max(created_at) FILTER (WHERE type = 'updated' AND status = 'done') AS finished_at
+    FROM task_events
+    GROUP BY task_id
+) e
+WHERE e.task_id = t.id
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION set_task_timestamps() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.status IS NOT DISTINCT FROM NEW.status THEN
+        RETURN NEW;
+    END IF;
+    IF NEW.status = 'created' THEN
+        NEW.started_at := NULL;
+        NEW.finished_at := NULL;
+    ELSIF NEW.status = 'processing' THEN
+        NEW.started_at := now();
+        NEW.finished_at := NULL;
+    ELSIF NEW.status = 'done' THEN
+        NEW.started_at := COALESCE(NEW.started_at, now());
+        NEW.finished_at := now();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER tasks_set_timestamps
+BEFORE INSERT OR UPDATE OF status ON tasks
+FOR EACH ROW EXECUTE FUNCTION set_task_timestamps()
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS tasks_created_at_idx ON tasks (created_at)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS tasks_finished_at_idx ON tasks (finished_at) WHERE finished_at IS NOT NULL
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS tasks_set_timestamps ON tasks
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS set_task_timestamps()
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS started_at,
+    DROP COLUMN IF EXISTS finished_at
+-- +goose StatementEnd
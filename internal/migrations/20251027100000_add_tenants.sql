@@ -0,0 +1,249 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default'
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks ALTER COLUMN tenant_id DROP DEFAULT
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_title_key
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks ADD CONSTRAINT tasks_tenant_id_title_key UNIQUE (tenant_id, title)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE task_events ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default'
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE task_events ALTER COLUMN tenant_id DROP DEFAULT
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default'
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE webhooks ALTER COLUMN tenant_id DROP DEFAULT
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE imports ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default'
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE imports ALTER COLUMN tenant_id DROP DEFAULT
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default'
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ALTER COLUMN tenant_id DROP DEFAULT
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_task_event() RETURNS TRIGGER AS $$
+DECLARE
+    row_data RECORD;
+    event_type VARCHAR(16);
+    event task_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := OLD;
+        event_type := 'deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        row_data := NEW;
+        event_type := 'created';
+    ELSE
+        row_data := NEW;
+        event_type := 'updated';
+    END IF;
+    INSERT INTO task_events (task_id, tenant_id, type, status, version)
+    VALUES (row_data.id, row_data.tenant_id, event_type, row_data.status, row_data.version)
+    RETURNING * INTO event;
+    PERFORM pg_notify('task_events', json_build_object(
+        'id', event.id,
+        'taskId', event.task_id,
+        'tenantId', event.tenant_id,
+        'type', event.type,
+        'status', event.status,
+        'version', event.version,
+        'createdAt', event.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries() RETURNS TRIGGER AS $$
+DECLARE
+    task tasks%ROWTYPE;
+    payload JSONB;
+BEGIN
+    SELECT * INTO task FROM tasks WHERE id = NEW.task_id;
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+    payload := jsonb_build_object(
+        'event', 'task.status_changed',
+        'eventId', NEW.id,
+        'taskId', task.id,
+        'title', task.title,
+        'status', NEW.status,
+        'version', NEW.version,
+        'occurredAt', NEW.created_at
+    );
+    INSERT INTO webhook_deliveries (webhook_id, task_id, url, payload)
+    SELECT w.id, task.id, w.url, payload FROM webhooks w
+    WHERE w.active AND w.tenant_id = task.tenant_id
+        AND (cardinality(w.statuses) = 0 OR NEW.status = ANY (w.statuses));
+    IF task.callback_url IS NOT NULL THEN
+        INSERT INTO webhook_deliveries (task_id, url, payload)
+        VALUES (task.id, task.callback_url, payload);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks ENABLE ROW LEVEL SECURITY
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE POLICY tasks_tenant_isolation ON tasks
+USING (tenant_id = current_setting('app.tenant_id', true))
+WITH CHECK (tenant_id = current_setting('app.tenant_id', true))
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP POLICY IF EXISTS tasks_tenant_isolation ON tasks
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks DISABLE ROW LEVEL SECURITY
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries() RETURNS TRIGGER AS $$
+DECLARE
+    task tasks%ROWTYPE;
+    payload JSONB;
+BEGIN
+    SELECT * INTO task FROM tasks WHERE id = NEW.task_id;
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+    payload := jsonb_build_object(
+        'event', 'task.status_changed',
+        'eventId', NEW.id,
+        'taskId', task.id,
+        'title', task.title,
+        'status', NEW.status,
+        'version', NEW.version,
+        'occurredAt', NEW.created_at
+    );
+    INSERT INTO webhook_deliveries (webhook_id, task_id, url, payload)
+    SELECT w.id, task.id, w.url, payload FROM webhooks w
+    WHERE w.active AND (cardinality(w.statuses) = 0 OR NEW.status = ANY (w.statuses));
+    IF task.callback_url IS NOT NULL THEN
+        INSERT INTO webhook_deliveries (task_id, url, payload)
+        VALUES (task.id, task.callback_url, payload);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_task_event() RETURNS TRIGGER AS $$
+DECLARE
+    row_data RECORD;
+    event_type VARCHAR(16);
+    event task_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := OLD;
+        event_type := 'deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        row_data := NEW;
+        event_type := 'created';
+    ELSE
+        row_data := NEW;
+        event_type := 'updated';
+    END IF;
+    INSERT INTO task_events (task_id, type, status, version)
+    VALUES (row_data.id, event_type, row_data.status, row_data.version)
+    RETURNING * INTO event;
+    PERFORM pg_notify('task_events', json_build_object(
+        'id', event.id,
+        'taskId', event.task_id,
+        'type', event.type,
+        'status', event.status,
+        'version', event.version,
+        'createdAt', event.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS tenant_id
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE imports DROP COLUMN IF EXISTS tenant_id
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE webhooks DROP COLUMN IF EXISTS tenant_id
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE task_events DROP COLUMN IF EXISTS tenant_id
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_tenant_id_title_key
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks DROP COLUMN IF EXISTS tenant_id
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks ADD CONSTRAINT tasks_title_key UNIQUE (title)
+-- +goose StatementEnd
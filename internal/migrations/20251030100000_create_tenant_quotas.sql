@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tenant_quotas(
+    tenant_id VARCHAR(63) PRIMARY KEY,
+    max_active INT NOT NULL DEFAULT 0 CHECK (max_active >= 0),
+    max_processing INT NOT NULL DEFAULT 0 CHECK (max_processing >= 0),
+    max_daily INT NOT NULL DEFAULT 0 CHECK (max_daily >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tenant_usage(
+    tenant_id VARCHAR(63) NOT NULL,
+    day DATE NOT NULL,
+    created INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, day)
+)
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS tasks_tenant_id_status_idx ON tasks (tenant_id, status)
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS tasks_tenant_id_status_idx
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tenant_usage
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tenant_quotas
+-- +goose StatementEnd
@@ -0,0 +1,110 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS created_by VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS assignee VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS processed_by VARCHAR(255)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE imports ADD COLUMN IF NOT EXISTS created_by VARCHAR(255)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS tasks_tenant_id_assignee_idx ON tasks (tenant_id, assignee)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS tasks_tenant_id_created_by_idx ON tasks (tenant_id, created_by)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_task_event() RETURNS TRIGGER AS $$
+DECLARE
+    row_data RECORD;
+    event_type VARCHAR(16);
+    event task_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := OLD;
+        event_type := 'deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        row_data := NEW;
+        event_type := 'created';
+    ELSIF OLD.status IS NOT DISTINCT FROM NEW.status AND OLD.assignee IS DISTINCT FROM NEW.assignee THEN
+        row_data := NEW;
+        event_type := 'assigned';
+    ELSE
+        row_data := NEW;
+        event_type := 'updated';
+    END IF;
+    INSERT INTO task_events (task_id, tenant_id, type, status, version)
+    VALUES (row_data.id, row_data.tenant_id, event_type, row_data.status, row_data.version)
+    RETURNING * INTO event;
+    PERFORM pg_notify('task_events', json_build_object(
+        'id', event.id,
+        'taskId', event.task_id,
+        'tenantId', event.tenant_id,
+        'type', event.type,
+        'status', event.status,
+        'version', event.version,
+        'createdAt', event.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_task_event() RETURNS TRIGGER AS $$
+DECLARE
+    row_data RECORD;
+    event_type VARCHAR(16);
+    event task_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := OLD;
+        event_type := 'deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        row_data := NEW;
+        event_type := 'created';
+    ELSE
+        row_data := NEW;
+        event_type := 'updated';
+    END IF;
+    INSERT INTO task_events (task_id, tenant_id, type, status, version)
+    VALUES (row_data.id, row_data.tenant_id, event_type, row_data.status, row_data.version)
+    RETURNING * INTO event;
+    PERFORM pg_notify('task_events', json_build_object(
+        'id', event.id,
+        'taskId', event.task_id,
+        'tenantId', event.tenant_id,
+        'type', event.type,
+        'status', event.status,
+        'version', event.version,
+        'createdAt', event.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX IF EXISTS tasks_tenant_id_created_by_idx
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX IF EXISTS tasks_tenant_id_assignee_idx
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE imports DROP COLUMN IF EXISTS created_by
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS assignee,
+    DROP COLUMN IF EXISTS processed_by
+-- +goose StatementEnd
@@ -0,0 +1,78 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_task_event() RETURNS TRIGGER AS $$
+DECLARE
+    row_data RECORD;
+    event_type VARCHAR(16);
+    event task_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := OLD;
+        event_type := 'deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        row_data := NEW;
+        event_type := 'created';
+    ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+        row_data := NEW;
+        event_type := 'updated';
+    ELSIF OLD.assignee IS DISTINCT FROM NEW.assignee THEN
+        row_data := NEW;
+        event_type := 'assigned';
+    ELSE
+        row_data := NEW;
+        event_type := 'edited';
+    END IF;
+    INSERT INTO task_events (task_id, tenant_id, type, status, version)
+    VALUES (row_data.id, row_data.tenant_id, event_type, row_data.status, row_data.version)
+    RETURNING * INTO event;
+    PERFORM pg_notify('task_events', json_build_object(
+        'id', event.id,
+        'taskId', event.task_id,
+        'tenantId', event.tenant_id,
+        'type', event.type,
+        'status', event.status,
+        'version', event.version,
+        'createdAt', event.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_task_event() RETURNS TRIGGER AS $$
+DECLARE
+    row_data RECORD;
+    event_type VARCHAR(16);
+    event task_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_data := OLD;
+        event_type := 'deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        row_data := NEW;
+        event_type := 'created';
+    ELSIF OLD.status IS NOT DISTINCT FROM NEW.status AND OLD.assignee IS DISTINCT FROM NEW.assignee THEN
+        row_data := NEW;
+        event_type := 'assigned';
+    ELSE
+        row_data := NEW;
+        event_type := 'updated';
+    END IF;
+    INSERT INTO task_events (task_id, tenant_id, type, status, version)
+    VALUES (row_data.id, row_data.tenant_id, event_type, row_data.status, row_data.version)
+    RETURNING * INTO event;
+    PERFORM pg_notify('task_events', json_build_object(
+        'id', event.id,
+        'taskId', event.task_id,
+        'tenantId', event.tenant_id,
+        'type', event.type,
+        'status', event.status,
+        'version', event.version,
+        'createdAt', event.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+DROP POLICY IF EXISTS tasks_tenant_isolation ON tasks
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE POLICY tasks_tenant_isolation ON tasks
+USING (COALESCE(current_setting('app.tenant_id', true), '') = '' OR tenant_id = current_setting('app.tenant_id', true))
+WITH CHECK (COALESCE(current_setting('app.tenant_id', true), '') = '' OR tenant_id = current_setting('app.tenant_id', true))
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP POLICY IF EXISTS tasks_tenant_isolation ON tasks
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE POLICY tasks_tenant_isolation ON tasks
+USING (tenant_id = current_setting('app.tenant_id', true))
+WITH CHECK (tenant_id = current_setting('app.tenant_id', true))
+-- +goose StatementEnd
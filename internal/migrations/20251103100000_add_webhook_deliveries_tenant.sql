@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default'
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE webhook_deliveries d SET tenant_id = t.tenant_id FROM tasks t WHERE t.id = d.task_id
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries ALTER COLUMN tenant_id DROP DEFAULT
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries() RETURNS TRIGGER AS $$
+DECLARE
+    task tasks%ROWTYPE;
+    payload JSONB;
+BEGIN
+    SELECT * INTO task FROM tasks WHERE id = NEW.task_id;
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+    payload := jsonb_build_object(
+        'event', 'task.status_changed',
+        'eventId', NEW.id,
+        'taskId', task.id,
+        'title', task.title,
+        'status', NEW.status,
+        'version', NEW.version,
+        'occurredAt', NEW.created_at
+    );
+    INSERT INTO webhook_deliveries (webhook_id, task_id, tenant_id, url, payload)
+    SELECT w.id, task.id, task.tenant_id, w.url, payload FROM webhooks w
+    WHERE w.active AND w.tenant_id = task.tenant_id
+        AND (cardinality(w.statuses) = 0 OR NEW.status = ANY (w.statuses));
+    IF task.callback_url IS NOT NULL THEN
+        INSERT INTO webhook_deliveries (task_id, tenant_id, url, payload)
+        VALUES (task.id, task.tenant_id, task.callback_url, payload);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries() RETURNS TRIGGER AS $$
+DECLARE
+    task tasks%ROWTYPE;
+    payload JSONB;
+BEGIN
+    SELECT * INTO task FROM tasks WHERE id = NEW.task_id;
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+    payload := jsonb_build_object(
+        'event', 'task.status_changed',
+        'eventId', NEW.id,
+        'taskId', task.id,
+        'title', task.title,
+        'status', NEW.status,
+        'version', NEW.version,
+        'occurredAt', NEW.created_at
+    );
+    INSERT INTO webhook_deliveries (webhook_id, task_id, url, payload)
+    SELECT w.id, task.id, w.url, payload FROM webhooks w
+    WHERE w.active AND w.tenant_id = task.tenant_id
+        AND (cardinality(w.statuses) = 0 OR NEW.status = ANY (w.statuses));
+    IF task.callback_url IS NOT NULL THEN
+        INSERT INTO webhook_deliveries (task_id, url, payload)
+        VALUES (task.id, task.callback_url, payload);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS tenant_id
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS caller_id VARCHAR(255) NOT NULL DEFAULT ''
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ALTER COLUMN caller_id DROP DEFAULT
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ NOT NULL DEFAULT now()
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ALTER COLUMN locked_until DROP DEFAULT
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, caller_id, key)
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM idempotency_keys k USING idempotency_keys o
+WHERE k.tenant_id = o.tenant_id AND k.key = o.key AND (k.created_at, k.caller_id) < (o.created_at, o.caller_id)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key)
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS locked_until
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS caller_id
+-- +goose StatementEnd
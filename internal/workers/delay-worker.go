@@ -0,0 +1,82 @@
+package workers
+
+import (
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/queue"
+	"betera-tz/pkg/tracing"
+	"context"
+	"maps"
+	"strconv"
+	"time"
+)
+
+// notBeforeHeader holds the time in Unix milliseconds a deferred message is
+// due at.
+const notBeforeHeader = "not-before"
+
+// MessageSender sends messages to a queue.
+type MessageSender interface {
+	SendMessage(ctx context.Context, message queue.Message) error
+}
+
+// DelayWorker moves deferred task messages from the delay topic back to the
+// task queue once they are due. Messages are deferred for the same delay, so
+// they are due in the order they are read and waiting for the first one holds
+// up no other; the task queue is never held up.
+type DelayWorker struct {
+	Consumer *queue.Consumer
+	Producer MessageSender
+	Logger   *logger.Logger
+	runState
+}
+
+func NewDelayWorker(c *queue.Consumer, p MessageSender, l *logger.Logger) *DelayWorker {
+	return &DelayWorker{
+		Consumer: c,
+		Producer: p,
+		Logger:   l,
+	}
+}
+
+// Start moves messages until the consumer fails, which Health reports.
+func (dw *DelayWorker) Start() {
+	op := "DelayWorker.Start"
+	log := dw.Logger.AddOp(op)
+	log.Info("starting delay worker")
+
+	dw.started()
+	err := dw.Consumer.HandleMessages(func(message queue.Message) error {
+		return dw.forward(context.Background(), message)
+	})
+	log.Error("delay worker stopped", logger.Err(err))
+	dw.stopped(err)
+}
+
+// Health returns nil while the worker is moving messages.
+func (dw *DelayWorker) Health(ctx context.Context) error {
+	return dw.health("delay worker")
+}
+
+// forward waits until message is due and sends it to the task queue.
+func (dw *DelayWorker) forward(ctx context.Context, message queue.Message) error {
+	if wait := time.Until(notBefore(message)); wait > 0 {
+		time.Sleep(wait)
+	}
+	headers := maps.Clone(message.Headers)
+	delete(headers, notBeforeHeader)
+	ctx = tracing.Extract(ctx, message.Headers)
+	if err := dw.Producer.SendMessage(ctx, queue.Message{Key: message.Key, Value: message.Value, Headers: headers}); err != nil {
+		dw.Logger.Error("failed to requeue deferred task", logger.Err(err))
+		return err
+	}
+	return nil
+}
+
+// notBefore is the time message is due at, the zero time when it has none.
+func notBefore(message queue.Message) time.Time {
+	ms, err := strconv.ParseInt(message.Headers[notBeforeHeader], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
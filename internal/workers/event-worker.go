@@ -0,0 +1,86 @@
+package workers
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/storage"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const eventsChannel = "task_events"
+
+// EventWorker forwards task change notifications from Postgres to the
+// in-process subscribers, so every API instance sees changes made by others.
+type EventWorker struct {
+	Storage      *storage.Storage
+	EventService services.EventService
+	Logger       *logger.Logger
+	Config       config.EventsConfig
+	runState
+}
+
+func NewEventWorker(s *storage.Storage, es services.EventService, l *logger.Logger, cfg config.EventsConfig) *EventWorker {
+	return &EventWorker{
+		Storage:      s,
+		EventService: es,
+		Logger:       l,
+		Config:       cfg,
+	}
+}
+
+func (ew *EventWorker) Start(ctx context.Context) {
+	op := "EventWorker.Start"
+	log := ew.Logger.AddOp(op)
+	log.Info("starting event worker")
+
+	go ew.prune(ctx)
+
+	ew.started()
+	defer ew.stopped(nil)
+
+	handler := func(payload string) {
+		event := models.TaskEvent{}
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			log.Error("failed to decode event", logger.Err(err))
+			return
+		}
+		ew.EventService.Publish(event)
+	}
+
+	for {
+		err := ew.Storage.Listen(ctx, eventsChannel, handler)
+		if ctx.Err() != nil {
+			log.Info("event worker stopped")
+			return
+		}
+		log.Error("event listener failed, reconnecting", logger.Err(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(ew.Config.ReconnectDelay):
+		}
+	}
+}
+
+// Health returns nil while the worker is listening for events or
+// reconnecting the listener, as Postgres being down is a readiness failure.
+func (ew *EventWorker) Health(ctx context.Context) error {
+	return ew.health("event worker")
+}
+
+func (ew *EventWorker) prune(ctx context.Context) {
+	ticker := time.NewTicker(ew.Config.PruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ew.EventService.Prune(ctx)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package workers
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/logger"
+	"context"
+	"sync"
+	"time"
+)
+
+// ImportWorker processes queued imports with a fixed number of goroutines and
+// fails imports left unfinished by a stopped instance.
+type ImportWorker struct {
+	ImportService services.ImportService
+	Logger        *logger.Logger
+	Config        config.ImportsConfig
+	runState
+}
+
+func NewImportWorker(is services.ImportService, l *logger.Logger, cfg config.ImportsConfig) *ImportWorker {
+	return &ImportWorker{
+		ImportService: is,
+		Logger:        l,
+		Config:        cfg,
+	}
+}
+
+func (iw *ImportWorker) Start(ctx context.Context) {
+	op := "ImportWorker.Start"
+	log := iw.Logger.AddOp(op)
+	log.Info("starting import worker")
+
+	iw.started()
+	defer iw.stopped(nil)
+	wg := sync.WaitGroup{}
+	for range max(iw.Config.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-iw.ImportService.Queue():
+					iw.ImportService.Process(models.WithTenant(ctx, job.Tenant), job.ID)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(iw.Config.StaleAfter / 2)
+	defer ticker.Stop()
+	for {
+		iw.ImportService.FailStale(ctx)
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			log.Info("import worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Health returns nil while the worker is processing imports.
+func (iw *ImportWorker) Health(ctx context.Context) error {
+	return iw.health("import worker")
+}
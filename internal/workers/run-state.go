@@ -0,0 +1,40 @@
+package workers
+
+import (
+	"fmt"
+	"sync"
+)
+
+// runState tracks the loop of a worker for its health check.
+type runState struct {
+	mu      sync.Mutex
+	running bool
+	err     error
+}
+
+func (rs *runState) started() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.running = true
+}
+
+// stopped records that the loop returned, err being why.
+func (rs *runState) stopped(err error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.running = false
+	rs.err = err
+}
+
+// health returns nil while the loop of the worker named name is running.
+func (rs *runState) health(name string) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.err != nil {
+		return fmt.Errorf("%s stopped: %w", name, rs.err)
+	}
+	if !rs.running {
+		return fmt.Errorf("%s is not running", name)
+	}
+	return nil
+}
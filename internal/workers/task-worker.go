@@ -1,59 +1,204 @@
 package workers
 
 import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
 	"betera-tz/internal/domain/repositories"
+	"betera-tz/internal/domain/services"
 	"betera-tz/pkg/errs"
 	"betera-tz/pkg/logger"
+	"betera-tz/pkg/monitoring"
 	"betera-tz/pkg/queue"
+	"betera-tz/pkg/tracing"
 	"context"
-	"fmt"
+	"maps"
+	"os"
+	"strconv"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Outcomes of processing a task, the outcome label of the processing
+// duration metric.
+const (
+	outcomeDone     = "done"
+	outcomeDeferred = "deferred"
+	outcomeFailed   = "failed"
 )
 
+// taskType labels the processing duration metric. Tasks have no type yet,
+// so they all share one.
+const taskType = "task"
+
+// TaskWorker processes queued tasks. A task of a tenant at its processing
+// quota is sent to the delay topic, which DelayWorker moves back to the end
+// of the queue, so it waits without holding up the tasks of other tenants.
+// Tasks are recorded as processed by ID. The processing of a task continues
+// the trace of the request that queued it.
 type TaskWorker struct {
+	ID             string
 	Consumer       *queue.Consumer
-	Producer       *queue.Producer
+	DelayProducer  MessageSender
 	Logger         *logger.Logger
 	TaskRepository repositories.TaskRepository
+	QuotaService   services.QuotaService
+	Metrics        *monitoring.PrometheusSetup
+	Config         config.QuotasConfig
+	runState
 }
 
-func NewTaskWorker(c *queue.Consumer, p *queue.Producer, l *logger.Logger, tr repositories.TaskRepository) *TaskWorker {
+func NewTaskWorker(c *queue.Consumer, dp MessageSender, l *logger.Logger, tr repositories.TaskRepository, qs services.QuotaService, ps *monitoring.PrometheusSetup, cfg config.QuotasConfig) *TaskWorker {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
 	return &TaskWorker{
+		ID:             (&models.Caller{Type: models.CallerWorker, Subject: host}).ID(),
 		Consumer:       c,
-		Producer:       p,
+		DelayProducer:  dp,
 		Logger:         l,
 		TaskRepository: tr,
+		QuotaService:   qs,
+		Metrics:        ps,
+		Config:         cfg,
 	}
 }
 
-func (tw *TaskWorker) MustStart() {
+// Start handles queued tasks until the consumer fails. The failure is
+// reported by Health rather than crashing the app, so the liveness probe
+// fails and the app is restarted.
+func (tw *TaskWorker) Start() {
 	op := "TaskWorker.Start"
 	log := tw.Logger.AddOp(op)
 	log.Info("starting task worker")
 
 	handler := func(message queue.Message) error {
 		taskId := string(message.Value)
-		return tw.processTask(context.Background(), taskId)
+		// messages sent before tenants were introduced have no tenant header
+		tenant := message.Headers[services.TenantHeader]
+		if tenant == "" {
+			tenant = models.DefaultTenant
+		}
+		ctx := tracing.Extract(models.WithTenant(context.Background(), tenant), message.Headers)
+		return tw.observe(message, func() (string, error) {
+			return tw.processTask(ctx, taskId, message)
+		})
 	}
 
-	if err := tw.Consumer.HandleMessages(handler); err != nil {
-		panic(fmt.Errorf("failed to start task worker: %w", err))
+	tw.started()
+	err := tw.Consumer.HandleMessages(handler)
+	log.Error("task worker stopped", logger.Err(err))
+	tw.stopped(err)
+}
+
+// Health returns nil while the worker is handling tasks.
+func (tw *TaskWorker) Health(ctx context.Context) error {
+	return tw.health("task worker")
+}
+
+// ReportConsumerStats feeds the consumer statistics of kafka-go to the queue
+// metrics every interval until ctx is done.
+func (tw *TaskWorker) ReportConsumerStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := tw.Consumer.Stats()
+			tw.Metrics.QueueConsumedTotal.WithLabelValues(stats.Topic).Add(float64(stats.Messages))
+			tw.Metrics.QueueConsumerErrorsTotal.WithLabelValues(stats.Topic).Add(float64(stats.Errors))
+		}
 	}
 }
 
-func (tw *TaskWorker) processTask(ctx context.Context, id string) error {
+// observe records the metrics of handling message by process.
+func (tw *TaskWorker) observe(message queue.Message, process func() (string, error)) error {
+	topic := tw.Consumer.Config.Topic
+	tw.Metrics.QueueConsumerLag.WithLabelValues(topic, strconv.Itoa(message.Partition)).Set(float64(message.Lag))
+	inFlight := tw.Metrics.WorkerInFlight.WithLabelValues(tw.ID)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	start := time.Now()
+	outcome, err := process()
+	tw.Metrics.TaskProcessingDuration.WithLabelValues(taskType, outcome).Observe(time.Since(start).Seconds())
+	switch outcome {
+	case outcomeFailed:
+		tw.Metrics.WorkerFailedTotal.WithLabelValues(tw.ID).Inc()
+	case outcomeDeferred:
+		tw.Metrics.WorkerRetriedTotal.WithLabelValues(tw.ID).Inc()
+	default:
+		tw.Metrics.WorkerProcessedTotal.WithLabelValues(tw.ID).Inc()
+	}
+	return err
+}
+
+func (tw *TaskWorker) processTask(ctx context.Context, id string, message queue.Message) (outcome string, err error) {
 	op := "worker.TaskProcessing"
 	log := tw.Logger.AddOp(op)
-	log.Info("task processing")
-	if err := tw.TaskRepository.UpdateStatus(ctx, id, "processing"); err != nil {
+	ctx, span := tracing.Tracer().Start(ctx, "process "+tw.Consumer.Config.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("messaging.destination.name", tw.Consumer.Config.Topic),
+			attribute.String("messaging.destination.partition.id", strconv.Itoa(message.Partition)),
+			attribute.String("task.id", id),
+			attribute.String("tenant.id", models.TenantFromContext(ctx)),
+		),
+	)
+	defer func() {
+		span.SetAttributes(attribute.String("task.outcome", outcome))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	log.Info("task processing", "id", id, "tenant", models.TenantFromContext(ctx))
+	quota, err := tw.QuotaService.Current(ctx)
+	if err != nil {
+		log.Error("failed to receive quota", logger.Err(err))
+		return outcomeFailed, errs.NewAppError(op, err)
+	}
+	started, err := tw.TaskRepository.StartProcessing(ctx, id, tw.ID, quota.MaxProcessing)
+	if err != nil {
 		log.Error("failed to update task's status", logger.Err(err))
-		return errs.NewAppError(op, err)
+		return outcomeFailed, errs.NewAppError(op, err)
+	}
+	if !started {
+		if err := tw.deferTask(ctx, log, id, message); err != nil {
+			return outcomeFailed, err
+		}
+		return outcomeDeferred, nil
 	}
 	time.Sleep(time.Second * 10)
 	if err := tw.TaskRepository.UpdateStatus(ctx, id, "done"); err != nil {
 		log.Error("failed to update task's status", logger.Err(err))
-		return errs.NewAppError(op, err)
+		return outcomeFailed, errs.NewAppError(op, err)
 	}
 	log.Info("task processed: ", "id", id)
+	return outcomeDone, nil
+}
+
+// deferTask sends the message of a task over the processing quota to the
+// delay topic, due after DeferDelay. The delay keeps a queue holding only
+// such tasks from spinning, without the worker waiting for it.
+func (tw *TaskWorker) deferTask(ctx context.Context, log *logger.Logger, id string, message queue.Message) error {
+	log.Info("task deferred by processing quota", "id", id)
+	headers := maps.Clone(message.Headers)
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	headers[notBeforeHeader] = strconv.FormatInt(time.Now().Add(tw.Config.DeferDelay).UnixMilli(), 10)
+	if err := tw.DelayProducer.SendMessage(ctx, queue.Message{Key: message.Key, Value: message.Value, Headers: headers}); err != nil {
+		log.Error("failed to requeue task", logger.Err(err))
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,173 @@
+package workers
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/models"
+	"betera-tz/internal/domain/repositories"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/logger"
+	"betera-tz/pkg/queue"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskRepository struct {
+	repositories.TaskRepository
+	mock.Mock
+}
+
+func (m *MockTaskRepository) StartProcessing(ctx context.Context, id, actor string, maxProcessing int) (bool, error) {
+	args := m.Called(ctx, id, actor, maxProcessing)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockQuotaService struct {
+	services.QuotaService
+	mock.Mock
+}
+
+func (m *MockQuotaService) Current(ctx context.Context) (*models.Quota, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Quota), args.Error(1)
+}
+
+// recordingSender records the messages sent, failing with err.
+type recordingSender struct {
+	mu       sync.Mutex
+	messages []queue.Message
+	err      error
+}
+
+func (rs *recordingSender) SendMessage(ctx context.Context, message queue.Message) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.messages = append(rs.messages, message)
+	return rs.err
+}
+
+func newTestLogger() *logger.Logger {
+	return logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+}
+
+func TestTaskWorker_DeferTask(t *testing.T) {
+	tests := []struct {
+		name            string
+		sendErr         error
+		expectedOutcome string
+	}{
+		{
+			name:            "deferred",
+			expectedOutcome: outcomeDeferred,
+		},
+		{
+			name:            "delay topic unavailable",
+			sendErr:         errors.New("broker down"),
+			expectedOutcome: outcomeFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			mockQuotas := new(MockQuotaService)
+			sender := &recordingSender{err: tt.sendErr}
+			worker := &TaskWorker{
+				ID:             "worker:test",
+				Consumer:       &queue.Consumer{Config: config.QueueConfig{Topic: "tasks"}},
+				DelayProducer:  sender,
+				Logger:         newTestLogger(),
+				TaskRepository: mockRepo,
+				QuotaService:   mockQuotas,
+				Config:         config.QuotasConfig{DeferDelay: time.Hour},
+			}
+			mockQuotas.On("Current", mock.Anything).Return(&models.Quota{MaxProcessing: 2}, nil)
+			mockRepo.On("StartProcessing", mock.Anything, "task-1", "worker:test", 2).Return(false, nil)
+			message := queue.Message{
+				Key:     "task-1",
+				Value:   []byte("task-1"),
+				Headers: map[string]string{services.TenantHeader: "acme"},
+			}
+
+			start := time.Now()
+			outcome, err := worker.processTask(context.Background(), "task-1", message)
+
+			assert.Equal(t, tt.expectedOutcome, outcome)
+			assert.Equal(t, tt.sendErr, err)
+			assert.Less(t, time.Since(start), time.Second)
+			assert.Len(t, sender.messages, 1)
+			sent := sender.messages[0]
+			assert.Equal(t, message.Key, sent.Key)
+			assert.Equal(t, message.Value, sent.Value)
+			assert.Equal(t, "acme", sent.Headers[services.TenantHeader])
+			assert.WithinDuration(t, start.Add(time.Hour), notBefore(sent), time.Second)
+			assert.NotContains(t, message.Headers, notBeforeHeader)
+			mockRepo.AssertExpectations(t)
+			mockQuotas.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDelayWorker_Forward(t *testing.T) {
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		minWait   time.Duration
+	}{
+		{
+			name:      "due",
+			notBefore: time.Now().Add(-time.Minute),
+		},
+		{
+			name:      "not due yet",
+			notBefore: time.Now().Add(200 * time.Millisecond),
+			minWait:   150 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &recordingSender{}
+			worker := NewDelayWorker(nil, sender, newTestLogger())
+			message := queue.Message{
+				Key:   "task-1",
+				Value: []byte("task-1"),
+				Headers: map[string]string{
+					services.TenantHeader: "acme",
+					notBeforeHeader:       strconv.FormatInt(tt.notBefore.UnixMilli(), 10),
+				},
+			}
+
+			start := time.Now()
+			err := worker.forward(context.Background(), message)
+
+			assert.NoError(t, err)
+			assert.GreaterOrEqual(t, time.Since(start), tt.minWait)
+			assert.Equal(t, []queue.Message{{
+				Key:     message.Key,
+				Value:   message.Value,
+				Headers: map[string]string{services.TenantHeader: "acme"},
+			}}, sender.messages)
+		})
+	}
+}
+
+func TestRunState_Health(t *testing.T) {
+	var rs runState
+	assert.EqualError(t, rs.health("worker"), "worker is not running")
+
+	rs.started()
+	assert.NoError(t, rs.health("worker"))
+
+	rs.stopped(errors.New("consumer closed"))
+	assert.EqualError(t, rs.health("worker"), "worker stopped: consumer closed")
+}
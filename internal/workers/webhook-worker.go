@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/internal/domain/services"
+	"betera-tz/pkg/logger"
+	"context"
+	"time"
+)
+
+// WebhookWorker sends pending webhook deliveries. Deliveries are claimed with
+// row locks, so several API instances can run it side by side.
+type WebhookWorker struct {
+	WebhookService services.WebhookService
+	Logger         *logger.Logger
+	Config         config.WebhooksConfig
+	runState
+}
+
+func NewWebhookWorker(ws services.WebhookService, l *logger.Logger, cfg config.WebhooksConfig) *WebhookWorker {
+	return &WebhookWorker{
+		WebhookService: ws,
+		Logger:         l,
+		Config:         cfg,
+	}
+}
+
+func (ww *WebhookWorker) Start(ctx context.Context) {
+	op := "WebhookWorker.Start"
+	log := ww.Logger.AddOp(op)
+	log.Info("starting webhook worker")
+
+	ww.started()
+	defer ww.stopped(nil)
+	ticker := time.NewTicker(ww.Config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("webhook worker stopped")
+			return
+		case <-ticker.C:
+		}
+		for ctx.Err() == nil {
+			claimed, err := ww.WebhookService.Deliver(ctx)
+			if err != nil || claimed < ww.Config.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+// Health returns nil while the worker is polling for deliveries.
+func (ww *WebhookWorker) Health(ctx context.Context) error {
+	return ww.health("webhook worker")
+}
@@ -3,12 +3,20 @@ package errs
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
 	ErrNotFoundBase      = errors.New("not found")
 	ErrAlreadyExistsBase = errors.New("already exists")
 	ErrInvalidValuesBase = errors.New("invalid values")
+	ErrKeyMismatchBase   = errors.New("idempotency key reused with different request")
+	ErrInProgressBase    = errors.New("request is already in progress")
+	ErrPreconditionBase  = errors.New("precondition failed")
+	ErrTooLargeBase      = errors.New("payload too large")
+	ErrUnauthorizedBase  = errors.New("unauthorized")
+	ErrForbiddenBase     = errors.New("forbidden")
+	ErrQuotaBase         = errors.New("quota exceeded")
 )
 
 type AppError struct {
@@ -24,6 +32,50 @@ func (ae AppError) Unwrap() error {
 	return ae.Err
 }
 
+// Violation describes a single invalid field of a request. Field is a path
+// into the request body such as "statuses[1]" and is empty for the body itself.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError carries every violation found in a request so they can be
+// reported at once. It matches ErrInvalidValuesBase.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (ve ValidationError) Error() string {
+	parts := make([]string, 0, len(ve.Violations))
+	for _, v := range ve.Violations {
+		if v.Field == "" {
+			parts = append(parts, v.Message)
+			continue
+		}
+		parts = append(parts, v.Field+" "+v.Message)
+	}
+	return fmt.Sprintf("%v : %s", ErrInvalidValuesBase, strings.Join(parts, "; "))
+}
+
+func (ve ValidationError) Is(target error) bool {
+	return target == ErrInvalidValuesBase
+}
+
+// QuotaError names the quota a tenant has reached and its limit. It matches
+// ErrQuotaBase.
+type QuotaError struct {
+	Quota string
+	Limit int
+}
+
+func (qe QuotaError) Error() string {
+	return fmt.Sprintf("%v : %s of %d", ErrQuotaBase, qe.Quota, qe.Limit)
+}
+
+func (qe QuotaError) Is(target error) bool {
+	return target == ErrQuotaBase
+}
+
 func NewAppError(op string, err error) AppError {
 	return AppError{
 		Operation: op,
@@ -39,6 +91,38 @@ func ErrInvalidValues(op string, err error) AppError {
 	return NewAppError(op, fmt.Errorf("%w : %w", ErrInvalidValuesBase, err))
 }
 
+func ErrValidation(op string, violations []Violation) AppError {
+	return NewAppError(op, ValidationError{Violations: violations})
+}
+
 func ErrNotFound(op string) AppError {
 	return NewAppError(op, fmt.Errorf("%w", ErrNotFoundBase))
 }
+
+func ErrKeyMismatch(op string) AppError {
+	return NewAppError(op, fmt.Errorf("%w", ErrKeyMismatchBase))
+}
+
+func ErrInProgress(op string) AppError {
+	return NewAppError(op, fmt.Errorf("%w", ErrInProgressBase))
+}
+
+func ErrPreconditionFailed(op string) AppError {
+	return NewAppError(op, fmt.Errorf("%w", ErrPreconditionBase))
+}
+
+func ErrTooLarge(op string) AppError {
+	return NewAppError(op, fmt.Errorf("%w", ErrTooLargeBase))
+}
+
+func ErrUnauthorized(op string) AppError {
+	return NewAppError(op, fmt.Errorf("%w", ErrUnauthorizedBase))
+}
+
+func ErrForbidden(op string) AppError {
+	return NewAppError(op, fmt.Errorf("%w", ErrForbiddenBase))
+}
+
+func ErrQuota(op, quota string, limit int) AppError {
+	return NewAppError(op, QuotaError{Quota: quota, Limit: limit})
+}
@@ -0,0 +1,169 @@
+package jwks
+
+import (
+	"betera-tz/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// maxSize bounds a fetched key set; real ones are a few kilobytes.
+const maxSize = 1 << 20
+
+// KeySet holds the verification keys of an identity provider. Keys from a
+// URL are fetched again once CacheTTL passes and when a token names an
+// unknown key, at most once per MinRefresh, so rotated keys are picked up
+// without a restart. Concurrent lookups share one fetch, and lookups of
+// known keys never wait for it.
+type KeySet struct {
+	Source     string
+	Client     *http.Client
+	CacheTTL   time.Duration
+	MinRefresh time.Duration
+
+	fetches   singleflight.Group
+	mu        sync.Mutex
+	keys      jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+func NewKeySet(cfg config.JWTConfig) *KeySet {
+	return &KeySet{
+		Source:     cfg.JWKS,
+		Client:     &http.Client{Timeout: cfg.Timeout},
+		CacheTTL:   cfg.CacheTTL,
+		MinRefresh: cfg.MinRefresh,
+	}
+}
+
+// NewStaticKeySet returns a key set that never refreshes.
+func NewStaticKeySet(keys jose.JSONWebKeySet) *KeySet {
+	return &KeySet{keys: keys, fetchedAt: time.Now()}
+}
+
+// Remote reports whether the keys are fetched from a URL.
+func (ks *KeySet) Remote() bool {
+	return strings.HasPrefix(ks.Source, "http://") || strings.HasPrefix(ks.Source, "https://")
+}
+
+// Load reads the key set for the first time. A key set from a file must
+// load at start; one from a URL is fetched again on first use if it fails.
+func (ks *KeySet) Load(ctx context.Context) error {
+	return ks.refresh(ctx)
+}
+
+// Key returns the public key with the id. An empty id matches the only key
+// of a single-key set. A key missing after a failed refresh is reported as
+// unknown too, wrapping the failure, as the token cannot be verified either
+// way.
+func (ks *KeySet) Key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	if ks.Remote() && ks.age() >= ks.CacheTTL {
+		// a failed refresh keeps the previous keys until the provider is back
+		ks.refresh(ctx)
+	}
+	if key := ks.find(kid); key != nil {
+		return key, nil
+	}
+	if ks.Remote() && ks.age() >= ks.MinRefresh {
+		if err := ks.refresh(ctx); err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrUnknownKey, kid, err)
+		}
+		if key := ks.find(kid); key != nil {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+// age is the time since the keys were last fetched.
+func (ks *KeySet) age() time.Duration {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return time.Since(ks.fetchedAt)
+}
+
+func (ks *KeySet) find(kid string) *jose.JSONWebKey {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if kid == "" {
+		if len(ks.keys.Keys) == 1 {
+			return &ks.keys.Keys[0]
+		}
+		return nil
+	}
+	if keys := ks.keys.Key(kid); len(keys) > 0 {
+		return &keys[0]
+	}
+	return nil
+}
+
+// refresh fetches the keys once for all concurrent callers. The fetch is not
+// cancelled with the request of the caller that started it, as the others
+// wait for it too; the client timeout bounds it.
+func (ks *KeySet) refresh(ctx context.Context) error {
+	if ks.Source == "" {
+		return nil
+	}
+	_, err, _ := ks.fetches.Do("", func() (any, error) {
+		ks.mu.Lock()
+		ks.fetchedAt = time.Now()
+		ks.mu.Unlock()
+		keys, err := ks.fetch(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		ks.mu.Lock()
+		ks.keys = keys
+		ks.mu.Unlock()
+		return nil, nil
+	})
+	return err
+}
+
+func (ks *KeySet) fetch(ctx context.Context) (jose.JSONWebKeySet, error) {
+	keys := jose.JSONWebKeySet{}
+	data, err := ks.read(ctx)
+	if err != nil {
+		return keys, fmt.Errorf("failed to read key set: %w", err)
+	}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return keys, fmt.Errorf("failed to parse key set: %w", err)
+	}
+	for i := range keys.Keys {
+		if !keys.Keys[i].IsPublic() {
+			keys.Keys[i] = keys.Keys[i].Public()
+		}
+	}
+	return keys, nil
+}
+
+func (ks *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !ks.Remote() {
+		return os.ReadFile(ks.Source)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ks.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxSize))
+}
@@ -0,0 +1,155 @@
+package jwks
+
+import (
+	"betera-tz/internal/config"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKey(t *testing.T, kid string) jose.JSONWebKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jose.JSONWebKey{Key: &key.PublicKey, KeyID: kid, Algorithm: "ES256", Use: "sig"}
+}
+
+// provider serves keys, answering with status when it is not 200. Requests
+// wait for release when it is set.
+type provider struct {
+	keys     jose.JSONWebKeySet
+	status   atomic.Int32
+	requests atomic.Int32
+	release  chan struct{}
+}
+
+func (p *provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.requests.Add(1)
+	if p.release != nil {
+		<-p.release
+	}
+	if status := int(p.status.Load()); status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	json.NewEncoder(w).Encode(p.keys)
+}
+
+func newTestKeySet(t *testing.T, p *provider, minRefresh time.Duration) *KeySet {
+	t.Helper()
+	p.status.Store(http.StatusOK)
+	srv := httptest.NewServer(p)
+	t.Cleanup(srv.Close)
+	ks := NewKeySet(config.JWTConfig{JWKS: srv.URL, Timeout: time.Second, CacheTTL: time.Hour, MinRefresh: minRefresh})
+	if err := ks.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return ks
+}
+
+func TestKeySet_Key(t *testing.T) {
+	tests := []struct {
+		name          string
+		kid           string
+		rotated       bool
+		status        int
+		minRefresh    time.Duration
+		expectedKid   string
+		expectedErrIs error
+	}{
+		{
+			name:        "known key",
+			kid:         "k1",
+			expectedKid: "k1",
+		},
+		{
+			name:        "rotated key fetched",
+			kid:         "k2",
+			rotated:     true,
+			expectedKid: "k2",
+		},
+		{
+			name:          "unknown key",
+			kid:           "k3",
+			rotated:       true,
+			expectedErrIs: ErrUnknownKey,
+		},
+		{
+			name:          "unknown key while the provider fails",
+			kid:           "k2",
+			rotated:       true,
+			status:        http.StatusBadGateway,
+			expectedErrIs: ErrUnknownKey,
+		},
+		{
+			name:          "refreshed too recently",
+			kid:           "k2",
+			rotated:       true,
+			minRefresh:    time.Hour,
+			expectedErrIs: ErrUnknownKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &provider{keys: jose.JSONWebKeySet{Keys: []jose.JSONWebKey{newTestKey(t, "k1")}}}
+			ks := newTestKeySet(t, p, tt.minRefresh)
+			if tt.rotated {
+				p.keys = jose.JSONWebKeySet{Keys: []jose.JSONWebKey{newTestKey(t, "k1"), newTestKey(t, "k2")}}
+			}
+			if tt.status != 0 {
+				p.status.Store(int32(tt.status))
+			}
+
+			key, err := ks.Key(context.Background(), tt.kid)
+
+			if tt.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tt.expectedErrIs)
+				assert.Nil(t, key)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedKid, key.KeyID)
+			}
+		})
+	}
+}
+
+func TestKeySet_KeyDuringFetch(t *testing.T) {
+	p := &provider{keys: jose.JSONWebKeySet{Keys: []jose.JSONWebKey{newTestKey(t, "k1")}}}
+	ks := newTestKeySet(t, p, 0)
+	p.release = make(chan struct{})
+
+	unknown := make(chan error, 1)
+	go func() {
+		_, err := ks.Key(context.Background(), "k2")
+		unknown <- err
+	}()
+	assert.Eventually(t, func() bool { return p.requests.Load() == 2 }, time.Second, 10*time.Millisecond)
+
+	known := make(chan error, 1)
+	go func() {
+		_, err := ks.Key(context.Background(), "k1")
+		known <- err
+	}()
+	select {
+	case err := <-known:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("lookup of a known key waited for the fetch")
+	}
+
+	close(p.release)
+	assert.ErrorIs(t, <-unknown, ErrUnknownKey)
+}
@@ -10,9 +10,29 @@ type PrometheusSetup struct {
 	HTTPRequestsTotal   *prometheus.CounterVec
 	HTTPErrorTotal      *prometheus.CounterVec
 	HTTPRequestDuration *prometheus.HistogramVec
+	HTTPResponseSize    *prometheus.HistogramVec
+	HTTPInFlight        prometheus.Gauge
+	HTTPThrottledTotal  *prometheus.CounterVec
+
+	QueueConsumedTotal       *prometheus.CounterVec
+	QueueConsumerErrorsTotal *prometheus.CounterVec
+	QueueConsumerLag         *prometheus.GaugeVec
+	WorkerProcessedTotal     *prometheus.CounterVec
+	WorkerFailedTotal        *prometheus.CounterVec
+	WorkerRetriedTotal       *prometheus.CounterVec
+	WorkerInFlight           *prometheus.GaugeVec
+	TaskProcessingDuration   *prometheus.HistogramVec
 }
 
+// defaultDurationBuckets cover requests from a few milliseconds to the
+// longest request timeouts.
+var defaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
 func NewPrometheusSetup(cfg config.MonitoringConfig) *PrometheusSetup {
+	durationBuckets := cfg.HTTPDurationBuckets
+	if len(durationBuckets) == 0 {
+		durationBuckets = defaultDurationBuckets
+	}
 	httpRequestsTotal := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: cfg.Namespace,
@@ -27,11 +47,29 @@ func NewPrometheusSetup(cfg config.MonitoringConfig) *PrometheusSetup {
 			Namespace: cfg.Namespace,
 			Name:      "http_request_duration_sec",
 			Help:      "Duration of requests in seconds",
-			Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+			Buckets:   durationBuckets,
 		},
 		[]string{"path", "method", "status"},
 	)
 	prometheus.MustRegister(httpRequestsDuration)
+	httpResponseSize := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: cfg.Namespace,
+			Name:      "http_response_size_bytes",
+			Help:      "Size of response bodies in bytes",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+		},
+		[]string{"path", "method", "status"},
+	)
+	prometheus.MustRegister(httpResponseSize)
+	httpInFlight := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: cfg.Namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served",
+		},
+	)
+	prometheus.MustRegister(httpInFlight)
 	httpErrorTotal := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: cfg.Namespace,
@@ -41,9 +79,103 @@ func NewPrometheusSetup(cfg config.MonitoringConfig) *PrometheusSetup {
 		[]string{"path", "method", "status"},
 	)
 	prometheus.MustRegister(httpErrorTotal)
+	httpThrottledTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "http_throttled_total",
+			Help:      "Total number of HTTP requests rejected by rate limits",
+		},
+		[]string{"path", "method", "key"},
+	)
+	prometheus.MustRegister(httpThrottledTotal)
+	queueConsumedTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "queue_messages_consumed_total",
+			Help:      "Total number of messages read from the queue",
+		},
+		[]string{"topic"},
+	)
+	prometheus.MustRegister(queueConsumedTotal)
+	queueConsumerErrorsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "queue_consumer_errors_total",
+			Help:      "Total number of errors of the queue consumer",
+		},
+		[]string{"topic"},
+	)
+	prometheus.MustRegister(queueConsumerErrorsTotal)
+	queueConsumerLag := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: cfg.Namespace,
+			Name:      "queue_consumer_lag",
+			Help:      "Messages of a partition not yet read by the consumer group",
+		},
+		[]string{"topic", "partition"},
+	)
+	prometheus.MustRegister(queueConsumerLag)
+	workerProcessedTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "worker_messages_processed_total",
+			Help:      "Total number of queue messages processed by workers",
+		},
+		[]string{"worker"},
+	)
+	prometheus.MustRegister(workerProcessedTotal)
+	workerFailedTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "worker_messages_failed_total",
+			Help:      "Total number of queue messages workers failed to process",
+		},
+		[]string{"worker"},
+	)
+	prometheus.MustRegister(workerFailedTotal)
+	workerRetriedTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "worker_messages_retried_total",
+			Help:      "Total number of queue messages put back in the queue to be processed later",
+		},
+		[]string{"worker"},
+	)
+	prometheus.MustRegister(workerRetriedTotal)
+	workerInFlight := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: cfg.Namespace,
+			Name:      "worker_messages_in_flight",
+			Help:      "Number of queue messages being processed",
+		},
+		[]string{"worker"},
+	)
+	prometheus.MustRegister(workerInFlight)
+	taskProcessingDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: cfg.Namespace,
+			Name:      "task_processing_duration_sec",
+			Help:      "Duration of task processing in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+		},
+		[]string{"type", "outcome"},
+	)
+	prometheus.MustRegister(taskProcessingDuration)
 	return &PrometheusSetup{
 		HTTPRequestsTotal:   httpRequestsTotal,
 		HTTPRequestDuration: httpRequestsDuration,
+		HTTPResponseSize:    httpResponseSize,
+		HTTPInFlight:        httpInFlight,
 		HTTPErrorTotal:      httpErrorTotal,
+		HTTPThrottledTotal:  httpThrottledTotal,
+
+		QueueConsumedTotal:       queueConsumedTotal,
+		QueueConsumerErrorsTotal: queueConsumerErrorsTotal,
+		QueueConsumerLag:         queueConsumerLag,
+		WorkerProcessedTotal:     workerProcessedTotal,
+		WorkerFailedTotal:        workerFailedTotal,
+		WorkerRetriedTotal:       workerRetriedTotal,
+		WorkerInFlight:           workerInFlight,
+		TaskProcessingDuration:   taskProcessingDuration,
 	}
 }
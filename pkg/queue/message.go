@@ -4,10 +4,15 @@ import (
 	"time"
 )
 
+// Message is a queue message. Partition and Lag are set on consumed messages
+// only; Lag counts the messages of the partition left after this one.
 type Message struct {
-	Key   string    `json:"key"`
-	Value []byte    `json:"value"`
-	Time  time.Time `json:"time"`
+	Key       string            `json:"key"`
+	Value     []byte            `json:"value"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Time      time.Time         `json:"time"`
+	Partition int               `json:"-"`
+	Lag       int64             `json:"-"`
 }
 
 type MessageHandler func(message Message) error
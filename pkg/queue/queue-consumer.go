@@ -36,9 +36,15 @@ func (c *Consumer) HandleMessages(handler MessageHandler) error {
 		}
 
 		message := Message{
-			Key:   string(msg.Key),
-			Value: msg.Value,
-			Time:  msg.Time,
+			Key:       string(msg.Key),
+			Value:     msg.Value,
+			Headers:   make(map[string]string, len(msg.Headers)),
+			Time:      msg.Time,
+			Partition: msg.Partition,
+			Lag:       max(msg.HighWaterMark-msg.Offset-1, 0),
+		}
+		for _, header := range msg.Headers {
+			message.Headers[header.Key] = string(header.Value)
 		}
 
 		if err := handler(message); err != nil {
@@ -53,6 +59,29 @@ func (c *Consumer) HandleMessages(handler MessageHandler) error {
 	}
 }
 
+// Stats returns the reader statistics gathered since the previous call. Under
+// a consumer group the reader reports a single lag for all its partitions,
+// so the lag of each partition is carried by the consumed messages instead.
+func (c *Consumer) Stats() kafka.ReaderStats {
+	return c.Client.Stats()
+}
+
+// Ping connects to the broker and reads the partitions of the topic.
+func (c *Consumer) Ping(ctx context.Context) error {
+	conn, err := kafka.DialContext(ctx, "tcp", c.Config.Broker)
+	if err != nil {
+		return fmt.Errorf("failed to dial kafka broker: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.ReadPartitions(c.Config.Topic); err != nil {
+		return fmt.Errorf("failed to read partitions of %s: %w", c.Config.Topic, err)
+	}
+	return nil
+}
+
 func (c *Consumer) MustClose() {
 	if err := c.Client.Close(); err != nil {
 		panic(fmt.Errorf("failed to close kafka consumer: %w", err))
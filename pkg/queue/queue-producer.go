@@ -2,10 +2,15 @@ package queue
 
 import (
 	"betera-tz/internal/config"
+	"betera-tz/pkg/tracing"
 	"context"
 	"fmt"
+	"maps"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Producer struct {
@@ -26,20 +31,65 @@ func NewProducer(cfg config.QueueConfig) *Producer {
 	}
 }
 
-func (p *Producer) SendMessage(message Message) error {
-	ctx, cancel := context.WithTimeout(context.Background(), p.Config.Timeout)
+// SendMessage writes a message with the trace context of ctx in its
+// headers, so its consumer continues the trace.
+func (p *Producer) SendMessage(ctx context.Context, message Message) error {
+	return p.SendMessages(ctx, message)
+}
+
+// SendMessages writes the messages in a single batch, which is much faster
+// than sending them one by one.
+func (p *Producer) SendMessages(ctx context.Context, messages ...Message) (err error) {
+	if len(messages) == 0 {
+		return nil
+	}
+	ctx, span := tracing.Tracer().Start(ctx, "send "+p.Config.Topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.operation.type", "send"),
+			attribute.String("messaging.destination.name", p.Config.Topic),
+			attribute.Int("messaging.batch.message_count", len(messages)),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	// the context of the caller is only traced, a message is sent even if
+	// the request that queued it is cancelled
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.Config.Timeout)
 	defer cancel()
 
-	kafkaMsg := kafka.Message{
-		Key:   []byte(message.Key),
-		Value: message.Value,
-		Time:  message.Time,
+	kafkaMsgs := make([]kafka.Message, 0, len(messages))
+	for _, message := range messages {
+		headers := maps.Clone(message.Headers)
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		tracing.Inject(ctx, headers)
+		kafkaMsgs = append(kafkaMsgs, kafka.Message{
+			Key:     []byte(message.Key),
+			Value:   message.Value,
+			Headers: kafkaHeaders(headers),
+			Time:    message.Time,
+		})
 	}
+	return p.Client.WriteMessages(writeCtx, kafkaMsgs...)
+}
 
-	if err := p.Client.WriteMessages(ctx, kafkaMsg); err != nil {
-		return err
+func kafkaHeaders(headers map[string]string) []kafka.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	kafkaHeaders := make([]kafka.Header, 0, len(headers))
+	for key, value := range headers {
+		kafkaHeaders = append(kafkaHeaders, kafka.Header{Key: key, Value: []byte(value)})
 	}
-	return nil
+	return kafkaHeaders
 }
 
 func (p *Producer) MustClose() {
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit is a token bucket holding Burst requests that refills at Requests
+// per Period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// interval is the time one request takes to refill.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(max(l.Requests, 1))
+}
+
+// tolerance is how far the theoretical arrival time may run ahead of now,
+// which is what lets Burst requests through at once.
+func (l Limit) tolerance() time.Duration {
+	return l.interval() * time.Duration(max(l.Burst, 1))
+}
+
+// Result is the decision on a request. Reset is the time until the bucket
+// is full again and RetryAfter, set when the request is denied, the time
+// until the next request is allowed.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter takes a request from the bucket of key. Buckets are implemented
+// with the generic cell rate algorithm, so a bucket is a single theoretical
+// arrival time and a denied request takes nothing.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// result builds the decision from ahead, the time the theoretical arrival
+// time runs ahead of now: after the request when allowed, before it when not.
+func result(limit Limit, ahead time.Duration, allowed bool) Result {
+	res := Result{Allowed: allowed, Limit: max(limit.Burst, 1), Reset: max(ahead, 0)}
+	if allowed {
+		res.Remaining = int((limit.tolerance() - ahead) / limit.interval())
+		return res
+	}
+	res.RetryAfter = max(ahead+limit.interval()-limit.tolerance(), 0)
+	return res
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit(t *testing.T) {
+	tests := []struct {
+		name              string
+		limit             Limit
+		expectedInterval  time.Duration
+		expectedTolerance time.Duration
+	}{
+		{
+			name:              "requests per second",
+			limit:             Limit{Requests: 50, Period: time.Second, Burst: 100},
+			expectedInterval:  20 * time.Millisecond,
+			expectedTolerance: 2 * time.Second,
+		},
+		{
+			name:              "burst of one",
+			limit:             Limit{Requests: 60, Period: time.Minute, Burst: 1},
+			expectedInterval:  time.Second,
+			expectedTolerance: time.Second,
+		},
+		{
+			name:              "zero requests and burst",
+			limit:             Limit{Period: time.Minute},
+			expectedInterval:  time.Minute,
+			expectedTolerance: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedInterval, tt.limit.interval())
+			assert.Equal(t, tt.expectedTolerance, tt.limit.tolerance())
+		})
+	}
+}
+
+func TestResult(t *testing.T) {
+	limit := Limit{Requests: 10, Period: 10 * time.Second, Burst: 5}
+	tests := []struct {
+		name     string
+		ahead    time.Duration
+		allowed  bool
+		expected Result
+	}{
+		{
+			name:     "first request",
+			ahead:    time.Second,
+			allowed:  true,
+			expected: Result{Allowed: true, Limit: 5, Remaining: 4, Reset: time.Second},
+		},
+		{
+			name:     "last request of the burst",
+			ahead:    5 * time.Second,
+			allowed:  true,
+			expected: Result{Allowed: true, Limit: 5, Remaining: 0, Reset: 5 * time.Second},
+		},
+		{
+			name:     "denied request",
+			ahead:    4500 * time.Millisecond,
+			expected: Result{Limit: 5, Reset: 4500 * time.Millisecond, RetryAfter: 500 * time.Millisecond},
+		},
+		{
+			name:     "past arrival time",
+			ahead:    -time.Second,
+			expected: Result{Limit: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, result(limit, tt.ahead, tt.allowed))
+		})
+	}
+}
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	ml := NewMemoryLimiter()
+	limit := Limit{Requests: 1, Period: time.Hour, Burst: 3}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		res, err := ml.Allow(context.Background(), "ip:1", limit)
+		assert.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, remaining, res.Remaining)
+	}
+
+	res, err := ml.Allow(context.Background(), "ip:1", limit)
+	assert.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.InDelta(t, time.Hour, res.RetryAfter, float64(time.Second))
+	assert.InDelta(t, 3*time.Hour, res.Reset, float64(time.Second))
+
+	other, err := ml.Allow(context.Background(), "ip:2", limit)
+	assert.NoError(t, err)
+	assert.True(t, other.Allowed)
+	assert.Equal(t, 2, other.Remaining)
+}
+
+func TestMemoryLimiter_Sweep(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		sweptAt      time.Time
+		expectedKeys []string
+	}{
+		{
+			name:         "full buckets dropped",
+			sweptAt:      now.Add(-sweepInterval),
+			expectedKeys: []string{"draining"},
+		},
+		{
+			name:         "swept recently",
+			sweptAt:      now.Add(-sweepInterval / 2),
+			expectedKeys: []string{"draining", "full"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ml := NewMemoryLimiter()
+			ml.sweptAt = tt.sweptAt
+			ml.tats["full"] = now.Add(-time.Second)
+			ml.tats["draining"] = now.Add(time.Second)
+
+			ml.sweep(now)
+
+			keys := []string{}
+			for key := range ml.tats {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, tt.expectedKeys, keys)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets refilled to full are dropped.
+const sweepInterval = time.Minute
+
+// MemoryLimiter keeps buckets in memory, so every replica limits on its own.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	tats    map[string]time.Time
+	sweptAt time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{tats: make(map[string]time.Time), sweptAt: time.Now()}
+}
+
+func (ml *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	ml.sweep(now)
+
+	tat := ml.tats[key]
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(limit.interval())
+	if next.Sub(now) > limit.tolerance() {
+		return result(limit, tat.Sub(now), false), nil
+	}
+	ml.tats[key] = next
+	return result(limit, next.Sub(now), true), nil
+}
+
+// sweep drops buckets whose theoretical arrival time has passed, as they are
+// full and the same as missing ones.
+func (ml *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(ml.sweptAt) < sweepInterval {
+		return
+	}
+	for key, tat := range ml.tats {
+		if tat.Before(now) {
+			delete(ml.tats, key)
+		}
+	}
+	ml.sweptAt = now
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"betera-tz/pkg/storage"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresLimiter keeps buckets in the rate_limits table, so limits hold
+// across replicas. Times come from the database clock for the same reason.
+type PostgresLimiter struct {
+	Storage *storage.Storage
+
+	mu      sync.Mutex
+	sweptAt time.Time
+}
+
+func NewPostgresLimiter(s *storage.Storage) *PostgresLimiter {
+	return &PostgresLimiter{Storage: s, sweptAt: time.Now()}
+}
+
+func (pl *PostgresLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	pl.sweep(ctx)
+
+	interval := limit.interval().Microseconds()
+	tolerance := limit.tolerance().Microseconds()
+	var ahead float64
+	err := pl.Storage.Pool.QueryRow(ctx, `
+		INSERT INTO rate_limits AS r (key, tat)
+		VALUES ($1, now() + $2 * interval '1 microsecond')
+		ON CONFLICT (key) DO UPDATE
+		SET tat = GREATEST(r.tat, now()) + $2 * interval '1 microsecond'
+		WHERE GREATEST(r.tat, now()) + $2 * interval '1 microsecond' - now() <= $3 * interval '1 microsecond'
+		RETURNING extract(epoch FROM tat - now())::float8`,
+		key, interval, tolerance,
+	).Scan(&ahead)
+	if err == nil {
+		return result(limit, seconds(ahead), true), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Result{}, fmt.Errorf("failed to take from bucket: %w", err)
+	}
+
+	err = pl.Storage.Pool.QueryRow(ctx, `
+		SELECT extract(epoch FROM tat - now())::float8 FROM rate_limits WHERE key = $1`,
+		key,
+	).Scan(&ahead)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read bucket: %w", err)
+	}
+	return result(limit, seconds(ahead), false), nil
+}
+
+// sweep deletes full buckets at most once per sweepInterval. A failed sweep
+// is retried with the next interval, as stale rows only take space.
+func (pl *PostgresLimiter) sweep(ctx context.Context) {
+	pl.mu.Lock()
+	if time.Since(pl.sweptAt) < sweepInterval {
+		pl.mu.Unlock()
+		return
+	}
+	pl.sweptAt = time.Now()
+	pl.mu.Unlock()
+	_, _ = pl.Storage.Pool.Exec(ctx, `DELETE FROM rate_limits WHERE tat < now()`)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
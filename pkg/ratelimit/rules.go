@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"betera-tz/internal/config"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Keys of rules: the caller, its tenant or the client IP address.
+const (
+	KeyCaller = "caller"
+	KeyTenant = "tenant"
+	KeyIP     = "ip"
+)
+
+// Rules are the rate limits of a set of keys, taken from the rules of the
+// configuration. A rule keeps its position in the configuration in its
+// buckets, so Rules built from the same configuration share them, e.g. the
+// REST and gRPC APIs.
+type Rules struct {
+	limiter Limiter
+	rules   []rule
+}
+
+type rule struct {
+	index int
+	config.RateLimit
+}
+
+// NewRules returns the rules with one of keys. A rule with an unknown key or
+// without positive requests and period panics, so the app does not start.
+func NewRules(limiter Limiter, rules []config.RateLimit, keys ...string) *Rules {
+	selected := []rule{}
+	for i, r := range rules {
+		switch r.Key {
+		case KeyCaller, KeyTenant, KeyIP:
+		default:
+			panic(fmt.Errorf("unknown rate limit key %q", r.Key))
+		}
+		if r.Requests <= 0 || r.Period <= 0 {
+			panic(fmt.Errorf("rate limit of %s %s needs positive requests and period", r.Method, r.Pattern))
+		}
+		if slices.Contains(keys, r.Key) {
+			selected = append(selected, rule{index: i, RateLimit: r})
+		}
+	}
+	return &Rules{limiter: limiter, rules: selected}
+}
+
+// Empty reports whether there are no rules to apply.
+func (rs *Rules) Empty() bool {
+	return len(rs.rules) == 0
+}
+
+// Decision is the outcome of Allow. Result is the result of the rule that
+// denied the request, or of the bucket closest to empty when it was allowed,
+// and nil when no rule applied. Key is the key of the denying rule. Err joins
+// the errors of failing limiters, whose rules let the request through rather
+// than take the API down.
+type Decision struct {
+	Allowed bool
+	Result  *Result
+	Key     string
+	Err     error
+}
+
+// Allow takes a request to the route of method and pattern from the bucket
+// of every rule matching it, stopping at the first one that is empty. keyOf
+// returns the value a rule key counts, empty when the request has none and
+// the rule does not apply. Empty Method and Pattern of a rule match every
+// route.
+func (rs *Rules) Allow(ctx context.Context, method, pattern string, keyOf func(key string) string) Decision {
+	decision := Decision{Allowed: true}
+	var errs []error
+	for _, r := range rs.rules {
+		if (r.Method != "" && !strings.EqualFold(r.Method, method)) || (r.Pattern != "" && r.Pattern != pattern) {
+			continue
+		}
+		key := keyOf(r.Key)
+		if key == "" {
+			continue
+		}
+		res, err := rs.limiter.Allow(ctx, fmt.Sprintf("%d:%s:%s", r.index, r.Key, key), Limit{
+			Requests: r.Requests,
+			Period:   r.Period,
+			Burst:    cmp.Or(r.Burst, r.Requests),
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !res.Allowed {
+			return Decision{Result: &res, Key: r.Key, Err: errors.Join(errs...)}
+		}
+		if decision.Result == nil || res.Remaining < decision.Result.Remaining {
+			decision.Result = &res
+		}
+	}
+	decision.Err = errors.Join(errs...)
+	return decision
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"betera-tz/internal/config"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingLimiter fails every request.
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestNewRules(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRules(NewMemoryLimiter(), []config.RateLimit{{Key: "user", Requests: 1, Period: time.Second}})
+	})
+	assert.Panics(t, func() {
+		NewRules(NewMemoryLimiter(), []config.RateLimit{{Key: KeyIP, Period: time.Second}})
+	})
+	rules := NewRules(NewMemoryLimiter(), []config.RateLimit{{Key: KeyIP, Requests: 1, Period: time.Second}}, KeyCaller)
+	assert.True(t, rules.Empty())
+}
+
+func TestRules_Allow(t *testing.T) {
+	cfg := []config.RateLimit{
+		{Key: KeyIP, Requests: 100, Period: time.Hour},
+		{Method: http.MethodPost, Pattern: "/api/v1/tasks", Key: KeyCaller, Requests: 1, Period: time.Hour},
+	}
+	keyOf := func(key string) string { return key + "-value" }
+	limiter := NewMemoryLimiter()
+	rules := NewRules(limiter, cfg, KeyIP, KeyCaller)
+
+	decision := rules.Allow(context.Background(), http.MethodPost, "/api/v1/tasks", keyOf)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 0, decision.Result.Remaining)
+
+	// rules built apart from the same configuration share the buckets
+	decision = NewRules(limiter, cfg, KeyCaller).Allow(context.Background(), http.MethodPost, "/api/v1/tasks", keyOf)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, KeyCaller, decision.Key)
+	assert.Positive(t, decision.Result.RetryAfter)
+
+	decision = rules.Allow(context.Background(), http.MethodGet, "/api/v1/tasks", keyOf)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 98, decision.Result.Remaining)
+
+	decision = rules.Allow(context.Background(), http.MethodGet, "/api/v1/tasks", func(key string) string { return "" })
+	assert.True(t, decision.Allowed)
+	assert.Nil(t, decision.Result)
+
+	decision = NewRules(failingLimiter{}, cfg, KeyIP).Allow(context.Background(), http.MethodGet, "/api/v1/tasks", keyOf)
+	assert.True(t, decision.Allowed)
+	assert.EqualError(t, decision.Err, "store unavailable")
+}
@@ -2,14 +2,28 @@ package storage
 
 import (
 	"betera-tz/internal/config"
+	"betera-tz/pkg/tracing"
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Storage struct {
 	Pool *pgxpool.Pool
+	// RowLevelSecurity makes InTenant set app.tenant_id for the row-level
+	// security policies of the tenant tables.
+	RowLevelSecurity bool
+}
+
+// Querier runs statements on the pool or in a transaction.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 func MustConnect(cfg config.StorageConfig) *Storage {
@@ -29,6 +43,7 @@ func MustConnect(cfg config.StorageConfig) *Storage {
 		panic(fmt.Errorf("failed to parse pool config: %w", err))
 	}
 	pcfg.MaxConns = cfg.AmountOfConns
+	pcfg.ConnConfig.Tracer = tracing.QueryTracer{}
 	pool, err := pgxpool.NewWithConfig(ctx, pcfg)
 	if err != nil {
 		panic(fmt.Errorf("failed to create postgres pool: %w", err))
@@ -39,11 +54,78 @@ func MustConnect(cfg config.StorageConfig) *Storage {
 		panic(fmt.Errorf("failed to ping postgres pool: %w", err))
 	}
 	storage := &Storage{
-		Pool: pool,
+		Pool:             pool,
+		RowLevelSecurity: cfg.RowLevelSecurity,
+	}
+	if storage.RowLevelSecurity {
+		ctxCheck, cancel := context.WithTimeout(context.Background(), cfg.PingTimeout)
+		defer cancel()
+		if err := storage.checkRowLevelSecurity(ctxCheck); err != nil {
+			panic(fmt.Errorf("row-level security: %w", err))
+		}
 	}
 	return storage
 }
 
+// ErrRowLevelSecurityBypassed reports a role that the policies of tasks do
+// not bind: its owner, a member of the owner role, a superuser or a role
+// with BYPASSRLS.
+var ErrRowLevelSecurityBypassed = errors.New("the database role bypasses the policies of tasks, connect with a role that does not own the table")
+
+// checkRowLevelSecurity fails unless the policies of tasks bind the role of
+// the connections, so enabling row-level security cannot silently do nothing.
+func (s *Storage) checkRowLevelSecurity(ctx context.Context) error {
+	var bypassed bool
+	err := s.Pool.QueryRow(ctx, `SELECT pg_has_role(c.relowner, 'USAGE') OR r.rolsuper OR r.rolbypassrls
+		FROM pg_class c, pg_roles r
+		WHERE c.oid = to_regclass('tasks') AND r.rolname = current_user`).Scan(&bypassed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("table tasks not found, run the migrations first")
+	}
+	if err != nil {
+		return err
+	}
+	if bypassed {
+		return ErrRowLevelSecurityBypassed
+	}
+	return nil
+}
+
+// InTenant runs fn with the statements of tenant. With row-level security it
+// runs fn in a transaction that sets app.tenant_id, so the policies admit
+// only the rows of tenant; an error of fn rolls the transaction back and is
+// returned as is. Without it app.tenant_id stays unset, which the policies
+// read as unscoped, and the queries filter by tenant themselves.
+func (s *Storage) InTenant(ctx context.Context, tenant string, fn func(q Querier) error) error {
+	if !s.RowLevelSecurity {
+		return fn(s.Pool)
+	}
+	return s.InTenantTx(ctx, tenant, fn)
+}
+
+// InTenantTx is InTenant that runs fn in a transaction with or without
+// row-level security, for statements that must see and change rows together.
+func (s *Storage) InTenantTx(ctx context.Context, tenant string, fn func(q Querier) error) error {
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if s.RowLevelSecurity {
+		if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenant); err != nil {
+			return err
+		}
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.Pool.Ping(ctx)
+}
+
 func (s *Storage) Close() {
 	s.Pool.Close()
 }
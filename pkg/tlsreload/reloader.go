@@ -0,0 +1,161 @@
+package tlsreload
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/pkg/logger"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrNoClientCAs = errors.New("no certificates in client ca file")
+
+// Reloader serves the certificate, and with mutual TLS the client CAs, of a
+// TLS configuration. Changed files are loaded again on the next handshake
+// after ReloadInterval, so renewed certificates are used without a restart;
+// files that fail to load leave the previous ones in use.
+type Reloader struct {
+	Config config.TLSConfig
+	Logger *logger.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamps    []fileStamp
+	checkedAt time.Time
+}
+
+// fileStamp tells whether a file changed since it was loaded.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the files of cfg, which must name a certificate and key.
+func NewReloader(cfg config.TLSConfig, l *logger.Logger) (*Reloader, error) {
+	if _, err := minVersion(cfg.MinVersion); err != nil {
+		return nil, err
+	}
+	r := &Reloader{Config: cfg, Logger: l}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checkedAt = time.Now()
+	return r, nil
+}
+
+// TLSConfig returns the server configuration. Clients must present a
+// certificate signed by a client CA when a client CA file is configured.
+func (r *Reloader) TLSConfig() *tls.Config {
+	version, _ := minVersion(r.Config.MinVersion)
+	cfg := &tls.Config{
+		MinVersion: version,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		},
+	}
+	if r.Config.ClientCAFile == "" {
+		return cfg
+	}
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		_, clientCAs := r.current()
+		handshake := cfg.Clone()
+		handshake.GetConfigForClient = nil
+		handshake.ClientAuth = tls.RequireAndVerifyClientCert
+		handshake.ClientCAs = clientCAs
+		return handshake, nil
+	}
+	return cfg
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Config.ReloadInterval > 0 && time.Since(r.checkedAt) >= r.Config.ReloadInterval {
+		r.checkedAt = time.Now()
+		if r.changed() {
+			if err := r.load(); err != nil {
+				r.Logger.Error("failed to reload tls certificate, keeping the previous one", logger.Err(err))
+			} else {
+				r.Logger.Info("tls certificate reloaded", "cert", r.Config.CertFile)
+			}
+		}
+	}
+	return r.cert, r.clientCAs
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.Config.CertFile, r.Config.KeyFile}
+	if r.Config.ClientCAFile != "" {
+		files = append(files, r.Config.ClientCAFile)
+	}
+	return files
+}
+
+func (r *Reloader) changed() bool {
+	stamps, err := stat(r.files())
+	if err != nil {
+		// a file being replaced may be missing for a moment
+		return false
+	}
+	for i := range stamps {
+		if stamps[i] != r.stamps[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads every file and replaces the served ones only when all loaded.
+func (r *Reloader) load() error {
+	stamps, err := stat(r.files())
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.Config.CertFile, r.Config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.Config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.Config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client ca file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return ErrNoClientCAs
+		}
+	}
+	r.cert, r.clientCAs, r.stamps = &cert, clientCAs, stamps
+	return nil
+}
+
+func stat(files []string) ([]fileStamp, error) {
+	stamps := make([]fileStamp, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, fileStamp{modTime: info.ModTime(), size: info.Size()})
+	}
+	return stamps, nil
+}
+
+func minVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls min version %q, want 1.2 or 1.3", version)
+	}
+}
@@ -0,0 +1,308 @@
+package tlsreload
+
+import (
+	"betera-tz/internal/config"
+	"betera-tz/pkg/logger"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// authority issues the certificates of a test.
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T) *authority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of a server certificate for
+// 127.0.0.1, or of a client certificate.
+func (a *authority) issue(t *testing.T, serial int64, client bool) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	if client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// writeFile writes data to path, moving its modification time forward so a
+// rewrite within the resolution of the file system is still seen as changed.
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serve starts a server handshaking with the configuration of r.
+func serve(t *testing.T, r *Reloader) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.Listener = tls.NewListener(srv.Listener, r.TLSConfig())
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// get makes a request over a new connection and returns the serial number
+// of the certificate the server presented.
+func get(srv *httptest.Server, ca *authority, clientCerts ...tls.Certificate) (int64, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: clientCerts},
+		DisableKeepAlives: true,
+	}}
+	resp, err := client.Get("https://" + srv.Listener.Addr().String())
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+func newTestLogger() *logger.Logger {
+	return logger.NewLogger(config.AppConfig{Name: "test", Version: "1.0.0", Env: "test", LogPath: "test.log"})
+}
+
+func TestMinVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         string
+		expectedVersion uint16
+		expectedError   bool
+	}{
+		{name: "default", version: "", expectedVersion: tls.VersionTLS12},
+		{name: "tls 1.2", version: "1.2", expectedVersion: tls.VersionTLS12},
+		{name: "tls 1.3", version: "1.3", expectedVersion: tls.VersionTLS13},
+		{name: "unsupported", version: "1.1", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := minVersion(tt.version)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedVersion, version)
+			}
+		})
+	}
+}
+
+func TestNewReloader(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	cert, key := ca.issue(t, 2, false)
+	writeFile(t, certFile, cert, time.Now())
+	writeFile(t, keyFile, key, time.Now())
+	writeFile(t, caFile, []byte("not a certificate"), time.Now())
+	tests := []struct {
+		name          string
+		cfg           config.TLSConfig
+		expectedError bool
+		expectedErrIs error
+	}{
+		{
+			name: "certificate and key",
+			cfg:  config.TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"},
+		},
+		{
+			name:          "unsupported min version",
+			cfg:           config.TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"},
+			expectedError: true,
+		},
+		{
+			name:          "missing key",
+			cfg:           config.TLSConfig{CertFile: certFile, KeyFile: filepath.Join(dir, "missing.key")},
+			expectedError: true,
+			expectedErrIs: os.ErrNotExist,
+		},
+		{
+			name:          "client ca file without certificates",
+			cfg:           config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile},
+			expectedError: true,
+			expectedErrIs: ErrNoClientCAs,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReloader(tt.cfg, newTestLogger())
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.expectedErrIs != nil {
+					assert.ErrorIs(t, err, tt.expectedErrIs)
+				}
+				assert.Nil(t, r)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, r)
+			}
+		})
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	ca := newAuthority(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	cert, key := ca.issue(t, 2, false)
+	writeFile(t, certFile, cert, time.Now())
+	writeFile(t, keyFile, key, time.Now())
+	r, err := NewReloader(config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Nanosecond}, newTestLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := serve(t, r)
+
+	serial, err := get(srv, ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), serial)
+
+	// the renewed certificate is served from the next handshake on
+	cert, key = ca.issue(t, 3, false)
+	writeFile(t, certFile, cert, time.Now().Add(time.Minute))
+	writeFile(t, keyFile, key, time.Now().Add(time.Minute))
+	serial, err = get(srv, ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(3), serial)
+
+	// a certificate that fails to load leaves the previous one in use
+	writeFile(t, certFile, []byte("truncated"), time.Now().Add(2*time.Minute))
+	serial, err = get(srv, ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(3), serial)
+}
+
+func TestReloader_MutualTLS(t *testing.T) {
+	ca := newAuthority(t)
+	other := newAuthority(t)
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	cert, key := ca.issue(t, 2, false)
+	writeFile(t, certFile, cert, time.Now())
+	writeFile(t, keyFile, key, time.Now())
+	writeFile(t, caFile, ca.pem, time.Now())
+	r, err := NewReloader(config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile}, newTestLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := serve(t, r)
+
+	clientCert := func(a *authority) tls.Certificate {
+		cert, key := a.issue(t, 10, true)
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pair
+	}
+	tests := []struct {
+		name          string
+		clientCerts   []tls.Certificate
+		expectedError bool
+	}{
+		{
+			name:        "certificate of a client ca",
+			clientCerts: []tls.Certificate{clientCert(ca)},
+		},
+		{
+			name:          "no certificate",
+			expectedError: true,
+		},
+		{
+			name:          "certificate of another ca",
+			clientCerts:   []tls.Certificate{clientCert(other)},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := get(srv, ca, tt.clientCerts...)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrForbiddenDestination is returned for webhook urls pointing at the host
+// itself or at internal networks, which clients must not reach through the
+// deliveries.
+var ErrForbiddenDestination = errors.New("webhook destination is not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, internal like the
+// private ranges.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// AllowedAddr reports whether deliveries may connect to addr: loopback,
+// link-local, private, unspecified and multicast addresses are rejected.
+func AllowedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL rejects a url whose host is a forbidden address or a localhost
+// name. Other names are checked once resolved, when the delivery dials them.
+func CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenDestination
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !AllowedAddr(addr) {
+		return ErrForbiddenDestination
+	}
+	return nil
+}
+
+// controlDial rejects connections to forbidden addresses. It runs after the
+// name is resolved, so a name resolving to an internal address is refused
+// however it passed validation.
+func controlDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !AllowedAddr(addr) {
+		return ErrForbiddenDestination
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"betera-tz/internal/config"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		expectedErr error
+	}{
+		{name: "public name", url: "https://example.com/hooks"},
+		{name: "public address", url: "http://93.184.216.34/hooks"},
+		{name: "public ipv6 address", url: "http://[2606:2800:220:1:248:1893:25c8:1946]/hooks"},
+		{name: "loopback", url: "http://127.0.0.1:8080/hooks", expectedErr: ErrForbiddenDestination},
+		{name: "ipv6 loopback", url: "http://[::1]/hooks", expectedErr: ErrForbiddenDestination},
+		{name: "localhost", url: "http://LocalHost./hooks", expectedErr: ErrForbiddenDestination},
+		{name: "localhost subdomain", url: "http://api.localhost/hooks", expectedErr: ErrForbiddenDestination},
+		{name: "private", url: "http://192.168.1.10/hooks", expectedErr: ErrForbiddenDestination},
+		{name: "ipv6 unique local", url: "http://[fd00::1]/hooks", expectedErr: ErrForbiddenDestination},
+		{name: "link-local", url: "http://169.254.169.254/latest", expectedErr: ErrForbiddenDestination},
+		{name: "mapped link-local", url: "http://[::ffff:169.254.169.254]/latest", expectedErr: ErrForbiddenDestination},
+		{name: "shared address space", url: "http://100.64.0.1/hooks", expectedErr: ErrForbiddenDestination},
+		{name: "unspecified", url: "http://0.0.0.0/hooks", expectedErr: ErrForbiddenDestination},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckURL(tt.url)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSender_SendForbiddenDestination(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	// Send does not validate the url, the dialer refuses the address
+	_, err := NewSender(config.WebhooksConfig{Timeout: time.Second}).Send(context.Background(), srv.URL, "secret", []byte(`{}`), http.Header{})
+
+	assert.ErrorIs(t, err, ErrForbiddenDestination)
+	assert.False(t, called)
+}